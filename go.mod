@@ -7,6 +7,7 @@ require (
 	github.com/fatih/color v1.15.0
 	github.com/gorilla/websocket v1.5.0
 	github.com/sirupsen/logrus v1.9.3
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -1,27 +1,529 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/commentary"
 	"github.com/zerobugdebug/cogfight/pkg/fighter"
 	"github.com/zerobugdebug/cogfight/pkg/game"
+	"github.com/zerobugdebug/cogfight/pkg/llm"
 	"github.com/zerobugdebug/cogfight/pkg/logging"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+	"github.com/zerobugdebug/cogfight/pkg/replay"
+	"github.com/zerobugdebug/cogfight/pkg/roster"
+	"github.com/zerobugdebug/cogfight/pkg/simulate"
+)
 
+const (
+	defaultReplayDir     = "replays"
+	defaultRosterDir     = "fighters"
+	newFighterOption     = "<-New fighter"
+	catalogWatchInterval = time.Second
 )
 
 func main() {
-	//fmt.Println("log = %v", log)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "replay":
+			replayCommand(os.Args[2:])
+			return
+		case "simulate":
+			simulateCommand(os.Args[2:])
+			return
+		case "fighter":
+			fighterCommand(os.Args[2:])
+			return
+		}
+	}
+	fightCommand(os.Args[1:])
+}
+
+// fightCommand picks the player's fighter from the roster or creates a new one and runs the fight against the computer
+func fightCommand(args []string) {
+	flags := flag.NewFlagSet("cogfight", flag.ExitOnError)
+	seed := flags.Int64("seed", 0, "seed for the fight random rolls, the same seed replays the same dice (default: random)")
+	record := flags.String("record", "", "file to record the fight to (default: "+defaultReplayDir+"/<time>-<seed>.json)")
+	noRecord := flags.Bool("no-record", false, "don't record the fight")
+	difficulty := flags.String("difficulty", "", "computer opponent strategy: "+strings.Join(game.Difficulties, ", ")+" (default: ask)")
+	commentaryProvider := flags.String("commentary", "llm", "fight commentary: "+strings.Join(commentary.Providers, ", ")+", llm falls back to template when unavailable")
+	llmConfig := flags.String("llm-config", "", "LLM backend config file (default: llm.json if present)")
+	attacksFile := flags.String("attacks", "", "attack catalog CSV, JSON or YAML file (default: built-in catalog)")
+	conditionsFile := flags.String("conditions", "", "conditions YAML or JSON file (default: built-in conditions)")
+	dev := flags.Bool("dev", false, "development mode: reload the -attacks catalog file whenever it changes")
+	rounds := flags.Int("rounds", game.DefaultRounds, "number of rounds, the judges decide the fight without the knockout (0: fight until the knockout)")
+	roundTurns := flags.Int("round-turns", game.DefaultTurnsPerRound, "number of turns in every round, the turns of both fighters are counted")
+	rosterDir := flags.String("roster", defaultRosterDir, "directory of the saved fighters")
+	fighterName := flags.String("fighter", "", "name of the saved fighter to fight with (default: ask)")
+	budget := flags.Int("budget", 0, "point budget of the player's fighter build, the fighters over it can't fight (0: no limit)")
+	flags.Parse(args)
+	rules := matchRules(*rounds, *roundTurns)
+
+	// Pick a random seed unless one was requested, so every fight can be reproduced
+	if !isSet(flags, "seed") {
+		*seed = time.Now().UnixNano()
+	}
+	if *record == "" {
+		*record = filepath.Join(defaultReplayDir, fmt.Sprintf("%s-%d.json", time.Now().Format("20060102-150405"), *seed))
+	}
+
+	loadConditions(*conditionsFile)
+	catalog, err := attack.NewRegistry(*attacksFile)
+	if err != nil {
+		logging.Fatalf("Can't load the attacks: %v", err)
+	}
+	if *dev {
+		ctx, stop := context.WithCancel(context.Background())
+		defer stop()
+		go catalog.Watch(ctx, catalogWatchInterval)
+	}
+
+	// The LLM is optional, without it the commentary is offline and there are no custom attacks
+	client, err := newLLMClient(*llmConfig)
+	if err != nil {
+		logging.Warnf("LLM is not available, custom attacks are disabled: %v", err)
+		if strings.ToLower(*commentaryProvider) == "llm" {
+			logging.Warn("Using the offline commentary")
+			*commentaryProvider = "template"
+		}
+	}
+	// Commentary phrases don't use the fight seed, so they don't change the dice
+	commentator, err := commentary.CommentatorByName(*commentaryProvider, client, rand.New(rand.NewSource(time.Now().UnixNano())))
+	if err != nil {
+		logging.Fatalf("Can't create the commentary: %v", err)
+	}
+
 	// Welcome message
 	logging.Info("Welcome to the CogFight!")
 
 	// Fighter Generation
-	logging.Info("Let's create your fighter:")
-	playerFighter := fighter.CreateFighter()
+	fighters := roster.New(*rosterDir)
+	playerFighter := pickFighter(fighters, *fighterName, *budget)
+	if playerFighter == nil {
+		return
+	}
 
 	// Fight Match
+	if *difficulty == "" {
+		var err error
+		*difficulty, err = game.SelectDifficulty()
+		if err != nil {
+			fmt.Println("Error during the difficulty selection:", err)
+			return
+		}
+	}
+	computerStrategy, err := game.StrategyByName(*difficulty, catalog)
+	if err != nil {
+		logging.Fatalf("Can't create the computer opponent: %v", err)
+	}
+
 	logging.Info("Let's start the fight!")
-	computerFighter := fighter.GenerateComputerFighter(playerFighter)
-	winner := game.Fight(playerFighter, computerFighter)
-	if winner != nil {
-		// Display the winner
-		logging.Info("The winner is ", winner.Name)
+	computerFighter := fighter.GenerateFighter(rand.New(rand.NewSource(*seed)))
+	fmt.Printf("\n%s has been generated!\n", computerFighter.Name)
+	fmt.Println(computerFighter.String())
+	recorder, err := replay.NewRecorder(playerFighter, computerFighter, rules, *seed)
+	if err != nil {
+		logging.Fatalf("Can't record the fight: %v", err)
+	}
+	customAttacks := len(playerFighter.CustomAttacks)
+	result := game.Fight(playerFighter, computerFighter, &game.PlayerStrategy{Catalog: catalog, Client: client}, computerStrategy, commentator, rules, *seed, recorder)
+	// Keep the custom attacks created during the fight for the next fights
+	if len(playerFighter.CustomAttacks) > customAttacks {
+		if err := fighters.Save(playerFighter); err != nil {
+			logging.Errorf("Can't save the new custom attacks of %s: %v", playerFighter.Name, err)
+		} else {
+			logging.Infof("New custom attacks of %s saved to %s", playerFighter.Name, fighters.Path(playerFighter.Name))
+		}
+	}
+	if !*noRecord {
+		if err := recorder.Save(*record); err != nil {
+			logging.Errorf("Can't save the fight recording: %v", err)
+		} else {
+			logging.Infof("Fight recorded to %s, watch it again with: cogfight replay %s", *record, *record)
+		}
+	}
+	logging.Info(result)
+}
+
+// pickFighter loads the saved fighter with the name, or asks the player to pick one of the saved fighters or to create a new one.
+// Only the fighters within the budget can be picked or created. New fighters are saved to the roster. It returns nil if the player cancels.
+func pickFighter(fighters *roster.Roster, name string, budget int) *fighter.Fighter {
+	if name != "" {
+		f, err := fighters.Load(name)
+		if err != nil {
+			logging.Fatalf("Can't load the fighter: %v", err)
+		}
+		if err := f.Build().CheckBudget(budget); err != nil {
+			logging.Fatalf("%s can't fight, the %v", f.Name, err)
+		}
+		return f
+	}
+
+	all, err := fighters.List()
+	if err != nil {
+		logging.Fatalf("Can't load the roster: %v", err)
+	}
+	saved := []*fighter.Fighter{}
+	for _, f := range all {
+		if f.Build().CheckBudget(budget) == nil {
+			saved = append(saved, f)
+		}
+	}
+	if len(saved) < len(all) {
+		logging.Infof("%d saved fighters cost more than the budget of %d points and can't fight", len(all)-len(saved), budget)
+	}
+	if len(saved) > 0 {
+		options := []string{}
+		for _, f := range saved {
+			options = append(options, f.Name)
+		}
+		options = append(options, newFighterOption)
+		selected := 0
+		err := survey.AskOne(&survey.Select{
+			Message: "Select your fighter:",
+			Options: options,
+			Description: func(value string, index int) string {
+				if index < len(saved) {
+					return saved[index].Archetype()
+				}
+				return ""
+			},
+		}, &selected)
+		if err != nil {
+			fmt.Println("Error during the fighter selection:", err)
+			return nil
+		}
+		if selected < len(saved) {
+			return saved[selected]
+		}
+	}
+
+	logging.Info("Let's create your fighter:")
+	f := fighter.CreateFighter(budget)
+	if f == nil {
+		return nil
+	}
+	if fighters.Has(f.Name) {
+		logging.Fatalf("Fighter %q is already in the roster, change it with: cogfight fighter edit %s", f.Name, f.Name)
+	}
+	if err := fighters.Save(f); err != nil {
+		logging.Errorf("Can't save the fighter: %v", err)
+	} else {
+		logging.Infof("%s saved to %s", f.Name, fighters.Path(f.Name))
+	}
+	return f
+}
+
+// fighterCommand manages the saved fighters in the roster
+func fighterCommand(args []string) {
+	command := ""
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+	flags := flag.NewFlagSet("cogfight fighter "+command, flag.ExitOnError)
+	rosterDir := flags.String("roster", defaultRosterDir, "directory of the saved fighters")
+	var budget *int
+	var newFighter func() *fighter.Fighter
+	if command == "new" || command == "edit" {
+		budget = flags.Int("budget", 0, "point budget of the fighter build, every balance step away from the balanced costs one point more than the previous one and the height, weight and age closer to their limits than to the average cost one point each (0: no limit)")
+	}
+	if command == "new" {
+		newFighter = buildFlags(flags, budget)
+	}
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cogfight fighter new|list|show|edit|delete [flags] [name]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	fighters := roster.New(*rosterDir)
+	// The names with spaces can be passed without the quotes
+	name := strings.Join(flags.Args(), " ")
+	if (command == "show" || command == "edit" || command == "delete") && name == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	switch command {
+	case "new":
+		f := newFighter()
+		if f == nil {
+			return
+		}
+		if fighters.Has(f.Name) {
+			logging.Fatalf("Fighter %q is already in the roster, change it with: cogfight fighter edit %s", f.Name, f.Name)
+		}
+		saveFighter(fighters, f)
+	case "list":
+		saved, err := fighters.List()
+		if err != nil {
+			logging.Fatalf("Can't load the roster: %v", err)
+		}
+		if len(saved) == 0 {
+			logging.Infof("No fighters in %s yet, create one with: cogfight fighter new", fighters.Dir())
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Name\tArchetype\tHeight\tWeight\tAge\tHealth\tStamina\tPoints")
+		for _, f := range saved {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\n", f.Name, f.Archetype(), f.Height, f.Weight, f.Age, f.MaxHealth, f.MaxStamina, f.Build().Cost())
+		}
+		w.Flush()
+	case "show":
+		f, err := fighters.Load(name)
+		if err != nil {
+			logging.Fatalf("Can't load the fighter: %v", err)
+		}
+		fmt.Println(f.String())
+		fmt.Printf("Archetype: %s, health: %d, stamina: %d, points: %d\n", f.Archetype(), f.MaxHealth, f.MaxStamina, f.Build().Cost())
+	case "edit":
+		f, err := fighters.Load(name)
+		if err != nil {
+			logging.Fatalf("Can't load the fighter: %v", err)
+		}
+		edited := fighter.EditFighter(f, *budget)
+		if edited == nil {
+			return
+		}
+		renamed := fighters.Path(edited.Name) != fighters.Path(f.Name)
+		if renamed && fighters.Has(edited.Name) {
+			logging.Fatalf("Fighter %q is already in the roster", edited.Name)
+		}
+		// The new name is saved to the same file, the old fighter is replaced by the renamed one
+		if !renamed && edited.Name != f.Name {
+			if err := fighters.Delete(f.Name); err != nil {
+				logging.Fatalf("Can't rename the fighter: %v", err)
+			}
+		}
+		saveFighter(fighters, edited)
+		if renamed {
+			if err := fighters.Delete(f.Name); err != nil {
+				logging.Fatalf("Can't delete the fighter: %v", err)
+			}
+		}
+	case "delete":
+		f, err := fighters.Load(name)
+		if err != nil {
+			logging.Fatalf("Can't load the fighter: %v", err)
+		}
+		confirmed := false
+		err = survey.AskOne(&survey.Confirm{Message: fmt.Sprintf("Delete %s from the roster?", f.Name)}, &confirmed)
+		if err != nil || !confirmed {
+			return
+		}
+		if err := fighters.Delete(f.Name); err != nil {
+			logging.Fatalf("Can't delete the fighter: %v", err)
+		}
+		logging.Infof("%s deleted", f.Name)
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+}
+
+// buildFlags registers the flags of the fighter creation without the prompts and returns the function that creates the fighter
+// from them after the flags are parsed. The build is read from the -from file first and the other flags override it,
+// the fighter is created with the prompts if none of the flags are set. The build can't cost more than the positive budget.
+func buildFlags(flags *flag.FlagSet, budget *int) func() *fighter.Fighter {
+	defaults := fighter.DefaultBuild()
+	from := flags.String("from", "", "YAML or JSON build file with the name and the build flags as the keys with underscores, e.g. agility_strength")
+	name := flags.String("name", "", "fighter name")
+	height := flags.Int("height", defaults.Height, "fighter height in cm")
+	weight := flags.Int("weight", defaults.Weight, "fighter weight in kg")
+	age := flags.Int("age", defaults.Age, "fighter age in years")
+	agilityStrength := flags.Float64("agility-strength", 0, "agility/strength balance from -2 (agility) to 2 (strength)")
+	burstEndurance := flags.Float64("burst-endurance", 0, "burst/endurance balance from -2 (burst) to 2 (endurance)")
+	defenseOffense := flags.Float64("defense-offense", 0, "defense/offense balance from -2 (defense) to 2 (offense)")
+	speedControl := flags.Float64("speed-control", 0, "speed/control balance from -2 (speed) to 2 (control)")
+	intelligenceInstinct := flags.Float64("intelligence-instinct", 0, "intelligence/instinct balance from -2 (intelligence) to 2 (instinct)")
+
+	return func() *fighter.Fighter {
+		fighterName, build := "", defaults
+		if *from != "" {
+			var err error
+			fighterName, build, err = fighter.LoadBuildFile(*from)
+			if err != nil {
+				logging.Fatalf("Can't load the build: %v", err)
+			}
+		}
+		buildSet := *from != ""
+		flags.Visit(func(f *flag.Flag) {
+			buildSet = buildSet || (f.Name != "roster" && f.Name != "budget")
+			switch f.Name {
+			case "name":
+				fighterName = *name
+			case "height":
+				build.Height = *height
+			case "weight":
+				build.Weight = *weight
+			case "age":
+				build.Age = *age
+			case "agility-strength":
+				build.AgilityStrength = *agilityStrength
+			case "burst-endurance":
+				build.BurstEndurance = *burstEndurance
+			case "defense-offense":
+				build.DefenseOffense = *defenseOffense
+			case "speed-control":
+				build.SpeedControl = *speedControl
+			case "intelligence-instinct":
+				build.IntelligenceInstinct = *intelligenceInstinct
+			}
+		})
+		if !buildSet {
+			return fighter.CreateFighter(*budget)
+		}
+
+		if strings.TrimSpace(fighterName) == "" {
+			logging.Fatal("The fighter name is required, set it with -name or in the -from file")
+		}
+		if err := build.Validate(); err != nil {
+			logging.Fatalf("Invalid build: %v", err)
+		}
+		if err := build.CheckBudget(*budget); err != nil {
+			logging.Fatalf("Invalid build: %v", err)
+		}
+		return fighter.NewFighter(fighterName, build)
+	}
+}
+
+// saveFighter saves the fighter to the roster, exits if it can't be saved
+func saveFighter(fighters *roster.Roster, f *fighter.Fighter) {
+	if err := fighters.Save(f); err != nil {
+		logging.Fatalf("Can't save the fighter: %v", err)
+	}
+	logging.Infof("%s saved to %s", f.Name, fighters.Path(f.Name))
+}
+
+// isSet returns true if the flag was set on the command line, so the zero value can be requested too
+func isSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
+
+// matchRules returns the professional rules with the number of rounds and turns, exits if they can't be played
+func matchRules(rounds, roundTurns int) game.Rules {
+	rules := game.DefaultRules()
+	rules.Rounds = rounds
+	rules.TurnsPerRound = roundTurns
+	if err := rules.Validate(); err != nil {
+		logging.Fatalf("Invalid match rules: %v", err)
+	}
+	return rules
+}
+
+// newLLMClient creates the LLM client from the config file and the environment variables
+func newLLMClient(configFile string) (llm.Client, error) {
+	config, err := llm.LoadConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+	return llm.NewClient(config)
+}
+
+// loadConditions replaces the built-in conditions with the ones from the file, if it's set.
+// The conditions are loaded before the attacks, because the attack specials refer to them.
+func loadConditions(filename string) {
+	if filename == "" {
+		return
+	}
+	err := modifiers.LoadConditions(filename)
+	if err != nil {
+		logging.Fatalf("Can't load the conditions: %v", err)
+	}
+}
+
+// replayCommand plays back the recorded fight
+func replayCommand(args []string) {
+	flags := flag.NewFlagSet("cogfight replay", flag.ExitOnError)
+	attacksFile := flags.String("attacks", "", "attack catalog CSV, JSON or YAML file the fight was recorded with (default: built-in catalog)")
+	conditionsFile := flags.String("conditions", "", "conditions YAML or JSON file the fight was recorded with (default: built-in conditions)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: cogfight replay [flags] <file>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	recording, err := replay.Load(flags.Arg(0))
+	if err != nil {
+		logging.Fatalf("Can't load the recording: %v", err)
+	}
+	loadConditions(*conditionsFile)
+	catalog, err := attack.NewRegistry(*attacksFile)
+	if err != nil {
+		logging.Fatalf("Can't load the attacks: %v", err)
+	}
+	err = replay.Play(recording, catalog)
+	if err != nil {
+		logging.Fatalf("Can't play the recording: %v", err)
+	}
+}
+
+// simulateCommand runs many headless computer vs computer fights and prints the balance report
+func simulateCommand(args []string) {
+	flags := flag.NewFlagSet("cogfight simulate", flag.ExitOnError)
+	fights := flags.Int("fights", 1000, "number of fights to simulate")
+	workers := flags.Int("workers", 0, "number of fights to simulate in parallel (default: number of CPUs)")
+	seed := flags.Int64("seed", 0, "seed for the first fight, the next fights use the following seeds (default: random)")
+	strategy := flags.String("strategy", "random", "first fighter strategy: "+strings.Join(game.Difficulties, ", "))
+	opponentStrategy := flags.String("opponent-strategy", "", "second fighter strategy (default: same as -strategy)")
+	fighterFiles := flags.String("fighters", "", "comma-separated fighter files to pick the fighters from (default: generate random fighters)")
+	attacksFile := flags.String("attacks", "", "attack catalog CSV, JSON or YAML file (default: built-in catalog)")
+	conditionsFile := flags.String("conditions", "", "conditions YAML or JSON file (default: built-in conditions)")
+	rounds := flags.Int("rounds", game.DefaultRounds, "number of rounds, the judges decide the fight without the knockout (0: fight until the knockout)")
+	roundTurns := flags.Int("round-turns", game.DefaultTurnsPerRound, "number of turns in every round, the turns of both fighters are counted")
+	flags.Parse(args)
+
+	if !isSet(flags, "seed") {
+		*seed = time.Now().UnixNano()
+	}
+	if *opponentStrategy == "" {
+		*opponentStrategy = *strategy
+	}
+
+	loadConditions(*conditionsFile)
+	catalog, err := attack.NewRegistry(*attacksFile)
+	if err != nil {
+		logging.Fatalf("Can't load the attacks: %v", err)
+	}
+	config := simulate.Config{
+		Fights:     *fights,
+		Workers:    *workers,
+		Seed:       *seed,
+		Strategies: [2]string{*strategy, *opponentStrategy},
+		Catalog:    catalog,
+		Rules:      matchRules(*rounds, *roundTurns),
+	}
+	if *fighterFiles != "" {
+		for _, filename := range strings.Split(*fighterFiles, ",") {
+			f, err := fighter.LoadFighterFromFile(strings.TrimSpace(filename))
+			if err != nil {
+				logging.Fatalf("Can't load the fighter: %v", err)
+			}
+			config.Fighters = append(config.Fighters, f)
+		}
+	}
+
+	logging.Infof("Simulating %d fights with seed %d", *fights, *seed)
+	report, err := simulate.Run(config)
+	if err != nil {
+		logging.Fatalf("Can't run the simulation: %v", err)
 	}
+	report.Print(os.Stdout)
 }
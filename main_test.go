@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zerobugdebug/cogfight/pkg/fighter"
+)
+
+func TestIsSet(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{"not set", []string{}, false},
+		{"zero seed", []string{"-seed", "0"}, true},
+		{"seed", []string{"-seed", "42"}, true},
+		{"other flag", []string{"-fights", "10"}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			flags.Int64("seed", 0, "")
+			flags.Int("fights", 0, "")
+			if err := flags.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			if got := isSet(flags, "seed"); got != test.want {
+				t.Errorf("seed set is %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBuildFlags(t *testing.T) {
+	from := filepath.Join(t.TempDir(), "tom.yaml")
+	document := "name: Tom\nheight: 190\nweight: 100\nagility_strength: 1\nspeed_control: -1\n"
+	if err := os.WriteFile(from, []byte(document), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file := fighter.DefaultBuild()
+	file.Height, file.Weight, file.AgilityStrength, file.SpeedControl = 190, 100, 1, -1
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantName  string
+		wantBuild func(b *fighter.Build)
+	}{
+		{"flags", []string{"-name", "Jerry", "-height", "170", "-burst-endurance", "2"}, "Jerry", func(b *fighter.Build) {
+			*b = fighter.DefaultBuild()
+			b.Height, b.BurstEndurance = 170, 2
+		}},
+		{"file", []string{"-from", from}, "Tom", func(b *fighter.Build) {}},
+		{"flags over file", []string{"-from", from, "-name", "Jerry", "-weight", "80", "-agility-strength", "0"}, "Jerry", func(b *fighter.Build) {
+			b.Weight, b.AgilityStrength = 80, 0
+		}},
+		{"flags before file", []string{"-speed-control", "2", "-from", from}, "Tom", func(b *fighter.Build) {
+			b.SpeedControl = 2
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			budget := flags.Int("budget", 0, "")
+			newFighter := buildFlags(flags, budget)
+			if err := flags.Parse(test.args); err != nil {
+				t.Fatal(err)
+			}
+			want := file
+			test.wantBuild(&want)
+
+			f := newFighter()
+			if f.Name != test.wantName || f.Build() != want {
+				t.Errorf("fighter is %s with %+v, want %s with %+v", f.Name, f.Build(), test.wantName, want)
+			}
+		})
+	}
+}
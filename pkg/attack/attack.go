@@ -1,12 +1,12 @@
 package attack
 
 import (
-	"encoding/csv"
+	"fmt"
+	"math"
 	"math/rand"
-	"os"
-	"strconv"
+	"sort"
+	"strings"
 
-	"github.com/zerobugdebug/cogfight/pkg/logging"
 	"github.com/zerobugdebug/cogfight/pkg/modifiers"
 )
 
@@ -25,10 +25,6 @@ const (
 	MaxDamage                    = 300
 )
 
-const (
-	defaultAttacksFile = "default_attacks.csv"
-)
-
 // AttackType represents the type of a fighting move
 type AttackType int
 
@@ -70,6 +66,16 @@ func (at AttackType) String() string {
 	return ""
 }
 
+// ParseAttackType returns the attack type by its name, ignoring the case
+func ParseAttackType(name string) (AttackType, error) {
+	for attackType, attackTypeName := range attackTypeNames {
+		if strings.EqualFold(strings.TrimSpace(name), attackTypeName) {
+			return attackType, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown attack type %q", name)
+}
+
 var attackTypeHints = map[AttackType]string{
 	Punch:       "Closed fist attacks, high damage, low complexity, high hit chance, high block chance",
 	Slap:        "Open fist or back hand attacks, very low damage, low complexity, high hit chance, high block chance",
@@ -120,78 +126,57 @@ type Attack struct {
 	BlockChance    float64
 	CriticalChance float64
 	SpecialChance  float64
+	// Specials override the attack type special, each of them is rolled separately
+	Specials    []Special `json:",omitempty"`
+	Tags        []string  `json:",omitempty"`
+	Description string    `json:",omitempty"`
 }
 
-// Attacks represents a structure to hold the attacks
-type Attacks struct {
-	ByName map[string]*Attack
-	ByType [MaxAttackTypes][]*Attack
+// Special is the condition the attack applies to the opponent with the chance
+type Special struct {
+	Condition modifiers.Condition
+	Chance    float64
 }
 
-func NewAttacks() *Attacks {
-	return &Attacks{
-		ByName: make(map[string]*Attack),
-	}
-}
+// Attack tags
+const (
+	// TagGroundOnly attacks can only be used against the Prone opponent
+	TagGroundOnly string = "ground-only"
+)
 
-func NewDefaultAttacks() *Attacks {
-	logging.Infof("Reading configuration file %s", defaultAttacksFile)
-	file, err := os.Open(defaultAttacksFile)
-	if err != nil {
-		logging.Fatalf("Failed to open default attack configuration file: %v", err)
-		return nil
-	}
-	defer file.Close()
-
-	// Read the CSV file
-	reader := csv.NewReader(file)
-	records, err := reader.ReadAll()
-	if err != nil {
-		logging.Fatalf("Failed to read default attack configuration file: %v", err)
-		return nil
+// SpecialChances returns the specials of the attack, which is the attack type special with the SpecialChance unless Specials are set
+func (a *Attack) SpecialChances() []Special {
+	if len(a.Specials) > 0 {
+		return a.Specials
 	}
+	return []Special{{Condition: a.Type.Special(), Chance: a.SpecialChance}}
+}
 
-	// Create a new Attacks struct
-	defaultAttacks := NewAttacks()
-
-	// Generate reverse map to attackTypeNames to match strings from the attack CSV file
-	var stringToAttackTypeMap = make(map[string]AttackType)
-
-	for k, v := range attackTypeNames {
-		stringToAttackTypeMap[v] = k
+// HasTag reports whether the attack has the tag
+func (a *Attack) HasTag(tag string) bool {
+	for _, attackTag := range a.Tags {
+		if attackTag == tag {
+			return true
+		}
 	}
+	return false
+}
 
-	// Skip the header row
-	for _, record := range records[1:] {
-		attackType, ok := stringToAttackTypeMap[record[1]]
-		if !ok {
-			logging.Infof("Unknown attack type: %v. Defaulting to %s", record[1], attackTypeNames[0])
-			continue
-		}
+// StaminaCost returns the stamina the fighter spends on the attack, harder and heavier attacks are more tiring
+func (a *Attack) StaminaCost() int {
+	return int(math.Round(a.Damage/10 + a.Complexity/5))
+}
 
-		damage, _ := strconv.ParseFloat(record[2], 64)
-		complexity, _ := strconv.ParseFloat(record[3], 64)
-		hitChance, _ := strconv.ParseFloat(record[4], 64)
-		blockChance, _ := strconv.ParseFloat(record[5], 64)
-		criticalChance, _ := strconv.ParseFloat(record[6], 64)
-		specialChance, _ := strconv.ParseFloat(record[7], 64)
-
-		attack := &Attack{
-			Name:           record[0],
-			Type:           attackType,
-			Damage:         damage,
-			Complexity:     complexity,
-			HitChance:      hitChance,
-			BlockChance:    blockChance,
-			CriticalChance: criticalChance,
-			SpecialChance:  specialChance,
-		}
+// Attacks represents a structure to hold the attacks
+type Attacks struct {
+	ByName map[string]*Attack
+	ByType [MaxAttackTypes][]*Attack
+}
 
-		defaultAttacks.AddAttack(attack)
+func NewAttacks() *Attacks {
+	return &Attacks{
+		ByName: make(map[string]*Attack),
 	}
-
-	return defaultAttacks
-
 }
 
 /* func NewDefaultAttacks() *Attacks {
@@ -265,14 +250,31 @@ func (attacks *Attacks) GetAttackByName(name string) *Attack {
 	return attacks.ByName[name]
 }
 
+// List returns all attacks sorted by name
+func (attacks *Attacks) List() []*Attack {
+	list := make([]*Attack, 0, len(attacks.ByName))
+	for _, attack := range attacks.ByName {
+		list = append(list, attack)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
 func (attacks *Attacks) GetAttacksByType(attackType AttackType) []*Attack {
 	return attacks.ByType[attackType]
 }
 
-func (attacks *Attacks) GetRandomAttack() *Attack {
-	attackType := AttackType(rand.Intn(MaxAttackTypes - 1))
+// GetRandomAttack returns a random non-custom attack using the provided random source, all attack types present in the catalog are equally likely
+func (attacks *Attacks) GetRandomAttack(rng *rand.Rand) *Attack {
+	attackTypes := []AttackType{}
+	for attackType := AttackType(0); attackType < Custom; attackType++ {
+		if len(attacks.GetAttacksByType(attackType)) > 0 {
+			attackTypes = append(attackTypes, attackType)
+		}
+	}
+	attackType := attackTypes[rng.Intn(len(attackTypes))]
 	attacksNum := len(attacks.GetAttacksByType(attackType))
-	return attacks.GetAttacksByType(attackType)[rand.Intn(attacksNum)]
+	return attacks.GetAttacksByType(attackType)[rng.Intn(attacksNum)]
 }
 
 func Clamp(val, min, max float64) float64 {
@@ -0,0 +1,422 @@
+package attack
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/zerobugdebug/cogfight/pkg/logging"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+const (
+	defaultAttacksFile string = "default_attacks.csv"
+	csvTagsSeparator          = ";"
+)
+
+//go:embed default_attacks.csv
+var defaultAttacksCSV []byte
+
+// knownTags lists the tags allowed in the catalog
+var knownTags = []string{TagGroundOnly}
+
+// NewDefaultAttacks returns the attack catalog embedded in the binary
+func NewDefaultAttacks() *Attacks {
+	defaultAttacks, err := LoadAttacksCSV(bytes.NewReader(defaultAttacksCSV), defaultAttacksFile)
+	if err != nil {
+		logging.Fatalf("Embedded attack catalog is invalid: %v", err)
+	}
+	return defaultAttacks
+}
+
+// LoadAttacks returns the attack catalog from the CSV, JSON or YAML file, or the embedded catalog if filename is empty
+func LoadAttacks(filename string) (*Attacks, error) {
+	if filename == "" {
+		return LoadAttacksCSV(bytes.NewReader(defaultAttacksCSV), defaultAttacksFile)
+	}
+	logging.Infof("Reading attack catalog %s", filename)
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error opening attack catalog: %s", err)
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return LoadAttacksCSV(file, filename)
+	case ".json", ".yaml", ".yml":
+		// JSON is valid YAML, so both are read the same way
+		return LoadAttacksYAML(file, filename)
+	default:
+		return nil, fmt.Errorf("unknown attack catalog format %s, should be .csv, .json, .yaml or .yml", filepath.Ext(filename))
+	}
+}
+
+// CatalogError describes the invalid value in the attack catalog
+type CatalogError struct {
+	Source string
+	Line   int
+	Column int
+	Field  string
+	Err    error
+}
+
+func (e *CatalogError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("%s:%d: %v", e.Source, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s: %v", e.Source, e.Line, e.Column, e.Field, e.Err)
+}
+
+func (e *CatalogError) Unwrap() error {
+	return e.Err
+}
+
+// catalogEntry is the attack as written in the catalog, before the validation
+type catalogEntry struct {
+	Name           string           `yaml:"name"`
+	Type           string           `yaml:"type"`
+	Damage         float64          `yaml:"damage"`
+	Complexity     float64          `yaml:"complexity"`
+	HitChance      float64          `yaml:"hit_chance"`
+	BlockChance    float64          `yaml:"block_chance"`
+	CriticalChance float64          `yaml:"critical_chance"`
+	SpecialChance  float64          `yaml:"special_chance"`
+	Specials       []catalogSpecial `yaml:"specials"`
+	Tags           []string         `yaml:"tags"`
+	Description    string           `yaml:"description"`
+}
+
+type catalogSpecial struct {
+	Condition string  `yaml:"condition"`
+	Chance    float64 `yaml:"chance"`
+}
+
+// catalogFields maps the catalog field keys to the CSV column names, the keys are used in JSON and YAML catalogs
+var catalogFields = []struct {
+	key, column string
+	required    bool
+}{
+	{"name", "Name", true},
+	{"type", "Type", true},
+	{"damage", "Damage", true},
+	{"complexity", "Complexity", true},
+	{"hit_chance", "HitChance", true},
+	{"block_chance", "BlockChance", true},
+	{"critical_chance", "CriticalChance", true},
+	{"special_chance", "SpecialChance", true},
+	{"specials", "", false},
+	{"tags", "Tags", false},
+	{"description", "Description", false},
+}
+
+// catalogRanges are the allowed ranges of the numeric fields
+var catalogRanges = map[string][2]float64{
+	"damage":          {MinDamage, MaxDamage},
+	"complexity":      {MinComplexity, MaxComplexity},
+	"hit_chance":      {MinHitChance, MaxHitChance},
+	"block_chance":    {MinBlockChance, MaxBlockChance},
+	"critical_chance": {MinCriticalHitChance, MaxCriticalHitChance},
+	"special_chance":  {MinSpecialChance, MaxSpecialChance},
+	"chance":          {MinSpecialChance, MaxSpecialChance},
+}
+
+// fieldPosition returns the line, column and name of the entry field in the source, to point the errors at it.
+// Nested fields are separated with dots, e.g. specials.0.chance
+type fieldPosition func(key string) (line, column int, name string)
+
+// catalogBuilder validates the entries and collects the attacks and all errors
+type catalogBuilder struct {
+	source  string
+	attacks *Attacks
+	names   map[string]int
+	errs    []error
+}
+
+func newCatalogBuilder(source string) *catalogBuilder {
+	return &catalogBuilder{source: source, attacks: NewAttacks(), names: make(map[string]int)}
+}
+
+func (b *catalogBuilder) fieldError(position fieldPosition, key string, err error) {
+	line, column, name := position(key)
+	b.errs = append(b.errs, &CatalogError{Source: b.source, Line: line, Column: column, Field: name, Err: err})
+}
+
+// add validates the entry and adds the attack to the catalog, the invalid fields were already reported while reading the entry
+func (b *catalogBuilder) add(entry catalogEntry, position fieldPosition, invalid map[string]bool) {
+	checkRange := func(key string, value float64) {
+		if limits := catalogRanges[key[strings.LastIndex(key, ".")+1:]]; !invalid[key] && (value < limits[0] || value > limits[1]) {
+			b.fieldError(position, key, fmt.Errorf("%v is out of range [%v, %v]", value, limits[0], limits[1]))
+		}
+	}
+
+	name := strings.TrimSpace(entry.Name)
+	line, _, _ := position("name")
+	if name == "" {
+		b.fieldError(position, "name", fmt.Errorf("empty name"))
+	} else if firstLine, ok := b.names[name]; ok {
+		b.fieldError(position, "name", fmt.Errorf("duplicate name %q, first defined on line %d", name, firstLine))
+	} else {
+		b.names[name] = line
+	}
+	attackType, err := ParseAttackType(entry.Type)
+	if err == nil && attackType == Custom {
+		err = fmt.Errorf("custom attacks can't be in the catalog")
+	}
+	if err != nil {
+		b.fieldError(position, "type", err)
+	}
+	checkRange("damage", entry.Damage)
+	checkRange("complexity", entry.Complexity)
+	checkRange("hit_chance", entry.HitChance)
+	checkRange("block_chance", entry.BlockChance)
+	checkRange("critical_chance", entry.CriticalChance)
+	if len(entry.Specials) == 0 {
+		checkRange("special_chance", entry.SpecialChance)
+	}
+
+	attack := &Attack{
+		Name:           name,
+		Type:           attackType,
+		Damage:         entry.Damage,
+		Complexity:     entry.Complexity,
+		HitChance:      entry.HitChance,
+		BlockChance:    entry.BlockChance,
+		CriticalChance: entry.CriticalChance,
+		SpecialChance:  entry.SpecialChance,
+		Description:    strings.TrimSpace(entry.Description),
+	}
+	for i, special := range entry.Specials {
+		key := fmt.Sprintf("specials.%d", i)
+		condition, err := modifiers.ParseCondition(special.Condition)
+		if err == nil && condition == modifiers.Healthy {
+			err = fmt.Errorf("%s is not a special", condition.String())
+		}
+		if err != nil {
+			b.fieldError(position, key+".condition", err)
+		}
+		checkRange(key+".chance", special.Chance)
+		attack.Specials = append(attack.Specials, Special{Condition: condition, Chance: special.Chance})
+	}
+	for i, tag := range entry.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		known := false
+		for _, knownTag := range knownTags {
+			known = known || tag == knownTag
+		}
+		if !known {
+			b.fieldError(position, fmt.Sprintf("tags.%d", i), fmt.Errorf("unknown tag %q, should be one of: %s", tag, strings.Join(knownTags, ", ")))
+		}
+		attack.Tags = append(attack.Tags, tag)
+	}
+	b.attacks.AddAttack(attack)
+}
+
+// result returns the catalog, or all collected errors
+func (b *catalogBuilder) result() (*Attacks, error) {
+	if len(b.errs) > 0 {
+		// Report the errors in the order of the source
+		sort.SliceStable(b.errs, func(i, j int) bool {
+			var first, second *CatalogError
+			if !errors.As(b.errs[i], &first) || !errors.As(b.errs[j], &second) {
+				return false
+			}
+			return first.Line < second.Line || (first.Line == second.Line && first.Column < second.Column)
+		})
+		return nil, errors.Join(b.errs...)
+	}
+	if len(b.attacks.ByName) == 0 {
+		return nil, fmt.Errorf("attack catalog %s has no attacks", b.source)
+	}
+	return b.attacks, nil
+}
+
+// LoadAttacksCSV reads the attack catalog in CSV with the header row from the reader.
+// Description and Tags columns are optional, the tags are separated with semicolons, specials can't be set in CSV.
+// All invalid values are reported together as CatalogErrors with their line and column, source is the name used in the errors.
+func LoadAttacksCSV(r io.Reader, source string) (*Attacks, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading attack catalog %s header: %v", source, err)
+	}
+
+	// Columns can go in any order
+	headerColumns := make(map[string]int, len(header))
+	for i, name := range header {
+		headerColumns[strings.TrimSpace(name)] = i
+	}
+	columns := make(map[string]int)
+	for _, field := range catalogFields {
+		if i, ok := headerColumns[field.column]; ok && field.column != "" {
+			columns[field.key] = i
+		} else if field.required {
+			return nil, &CatalogError{Source: source, Line: 1, Err: fmt.Errorf("missing column %s", field.column)}
+		}
+	}
+
+	builder := newCatalogBuilder(source)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, &CatalogError{Source: source, Line: parseErr.Line, Column: parseErr.Column, Err: parseErr.Err}
+			}
+			return nil, fmt.Errorf("error reading attack catalog %s: %v", source, err)
+		}
+
+		position := func(key string) (int, int, string) {
+			// Nested keys like tags.1 point at the whole column
+			key = strings.SplitN(key, ".", 2)[0]
+			line, column := reader.FieldPos(columns[key])
+			for _, field := range catalogFields {
+				if field.key == key {
+					return line, column, field.column
+				}
+			}
+			return line, column, key
+		}
+		field := func(key string) string {
+			if i, ok := columns[key]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		invalid := make(map[string]bool)
+		number := func(key string) float64 {
+			value, err := strconv.ParseFloat(field(key), 64)
+			if err != nil {
+				builder.fieldError(position, key, fmt.Errorf("%q is not a number", field(key)))
+				invalid[key] = true
+			}
+			return value
+		}
+
+		entry := catalogEntry{
+			Name:           field("name"),
+			Type:           field("type"),
+			Damage:         number("damage"),
+			Complexity:     number("complexity"),
+			HitChance:      number("hit_chance"),
+			BlockChance:    number("block_chance"),
+			CriticalChance: number("critical_chance"),
+			SpecialChance:  number("special_chance"),
+			Description:    field("description"),
+		}
+		if tags := field("tags"); tags != "" {
+			entry.Tags = strings.Split(tags, csvTagsSeparator)
+		}
+		builder.add(entry, position, invalid)
+	}
+	return builder.result()
+}
+
+// LoadAttacksYAML reads the attack catalog in YAML or JSON from the reader.
+// The catalog is an object with the attacks list, every attack uses the field keys of catalogFields:
+//
+//	attacks:
+//	  - name: Flying Knee
+//	    type: Knee Strike
+//	    damage: 75
+//	    ...
+//	    specials:
+//	      - {condition: Bleeding, chance: 30}
+//	    tags: [ground-only]
+//	    description: Jumping knee to the head
+//
+// All invalid values are reported together as CatalogErrors with their line and column, source is the name used in the errors.
+func LoadAttacksYAML(r io.Reader, source string) (*Attacks, error) {
+	document := struct {
+		Attacks []yaml.Node `yaml:"attacks"`
+	}{}
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	err := decoder.Decode(&document)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding attack catalog %s: %v", source, err)
+	}
+
+	builder := newCatalogBuilder(source)
+	for _, node := range document.Attacks {
+		node := node
+		if node.Kind != yaml.MappingNode {
+			builder.errs = append(builder.errs, &CatalogError{Source: source, Line: node.Line, Column: node.Column, Err: fmt.Errorf("attack should be an object")})
+			continue
+		}
+
+		// Remember where every value is to point the errors at it
+		nodes := make(map[string]*yaml.Node)
+		indexNodes(nodes, "", &node)
+		position := func(key string) (int, int, string) {
+			if valueNode, ok := nodes[key]; ok {
+				return valueNode.Line, valueNode.Column, key
+			}
+			return node.Line, node.Column, key
+		}
+
+		invalid := make(map[string]bool)
+		for i := 0; i < len(node.Content); i += 2 {
+			known := false
+			for _, field := range catalogFields {
+				known = known || node.Content[i].Value == field.key
+			}
+			if !known {
+				builder.fieldError(position, node.Content[i].Value, fmt.Errorf("unknown field"))
+			}
+		}
+		for _, field := range catalogFields {
+			if _, ok := nodes[field.key]; !ok && field.required && !(field.key == "special_chance" && nodes["specials"] != nil) {
+				builder.fieldError(position, field.key, fmt.Errorf("missing field"))
+				invalid[field.key] = true
+			}
+		}
+		for key, valueNode := range nodes {
+			if _, numeric := catalogRanges[key[strings.LastIndex(key, ".")+1:]]; numeric && valueNode.Tag != "!!int" && valueNode.Tag != "!!float" {
+				builder.fieldError(position, key, fmt.Errorf("%q is not a number", valueNode.Value))
+				invalid[key] = true
+			}
+		}
+
+		entry := catalogEntry{}
+		err := node.Decode(&entry)
+		if err != nil && len(invalid) == 0 {
+			builder.errs = append(builder.errs, &CatalogError{Source: source, Line: node.Line, Err: err})
+			continue
+		}
+		builder.add(entry, position, invalid)
+	}
+	return builder.result()
+}
+
+// indexNodes adds the nodes of the mapping and sequence values to the index by their dotted path
+func indexNodes(index map[string]*yaml.Node, prefix string, node *yaml.Node) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := prefix + node.Content[i].Value
+			index[key] = node.Content[i+1]
+			indexNodes(index, key+".", node.Content[i+1])
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			key := prefix + strconv.Itoa(i)
+			index[key] = item
+			indexNodes(index, key+".", item)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package attack
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+const testCSVHeader string = "Name,Type,Damage,Complexity,HitChance,BlockChance,CriticalChance,SpecialChance,Description\n"
+
+// catalogErrors returns all CatalogErrors joined in the error
+func catalogErrors(err error) []*CatalogError {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	catalogErrs := []*CatalogError{}
+	for _, err := range errs {
+		var catalogErr *CatalogError
+		if errors.As(err, &catalogErr) {
+			catalogErrs = append(catalogErrs, catalogErr)
+		}
+	}
+	return catalogErrs
+}
+
+// wantError is the expected position of the CatalogError and the text its cause should contain
+type wantError struct {
+	line, column int
+	field, text  string
+}
+
+// checkCatalogErrors fails the test unless the error holds exactly the expected CatalogErrors in order
+func checkCatalogErrors(t *testing.T, err error, source string, want []wantError) {
+	t.Helper()
+	if err == nil {
+		t.Fatal("invalid catalog loaded without errors")
+	}
+	got := catalogErrors(err)
+	if len(got) != len(want) {
+		t.Fatalf("got %d catalog errors, want %d: %v", len(got), len(want), err)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Source != source || g.Line != w.line || g.Column != w.column || g.Field != w.field || !strings.Contains(g.Err.Error(), w.text) {
+			t.Errorf("error %d is %q at %s:%d:%d field %q, want %q at line %d, column %d, field %q", i, g.Err, g.Source, g.Line, g.Column, g.Field, w.text, w.line, w.column, w.field)
+		}
+	}
+}
+
+func TestLoadAttacksCSVErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want []wantError
+	}{
+		{"non-numeric damage", testCSVHeader + "Jab,Punch,lots,5,85,30,5,10,Quick jab\n",
+			[]wantError{{2, 11, "Damage", `"lots" is not a number`}}},
+		{"unknown type", testCSVHeader + "Jab,Poke,30,5,85,30,5,10,Quick jab\n",
+			[]wantError{{2, 5, "Type", "Poke"}}},
+		{"custom type", testCSVHeader + "Jab,Custom,30,5,85,30,5,10,Quick jab\n",
+			[]wantError{{2, 5, "Type", "custom attacks can't be in the catalog"}}},
+		{"duplicate name", testCSVHeader + "Jab,Punch,30,5,85,30,5,10,Quick jab\nJab,Punch,35,5,80,30,5,10,Another jab\n",
+			[]wantError{{3, 1, "Name", `duplicate name "Jab", first defined on line 2`}}},
+		{"empty name", testCSVHeader + " ,Punch,30,5,85,30,5,10,Quick jab\n",
+			[]wantError{{2, 2, "Name", "empty name"}}},
+		{"hit chance out of range", testCSVHeader + "Jab,Punch,30,5,150,30,5,10,Quick jab\n",
+			[]wantError{{2, 16, "HitChance", "150 is out of range [1, 99]"}}},
+		{"all errors of all rows", testCSVHeader + "Jab,Punch,30,5,85,30,5,10,Quick jab\nJab,Poke,x,5,85,96,5,10,Bad jab\n",
+			[]wantError{{3, 1, "Name", "duplicate name"}, {3, 5, "Type", "Poke"}, {3, 10, "Damage", "not a number"}, {3, 17, "BlockChance", "96 is out of range"}}},
+		{"missing column", "Name,Type,Complexity,HitChance,BlockChance,CriticalChance,SpecialChance\nJab,Punch,5,85,30,5,10\n",
+			[]wantError{{1, 0, "", "missing column Damage"}}},
+		{"wrong number of fields", testCSVHeader + "Jab,Punch,30,5,85,30\n",
+			[]wantError{{2, 1, "", "wrong number of fields"}}},
+		{"unknown tag", "Name,Type,Damage,Complexity,HitChance,BlockChance,CriticalChance,SpecialChance,Tags\nJab,Punch,30,5,85,30,5,10,ground-only;flying\n",
+			[]wantError{{2, 27, "Tags", `unknown tag "flying"`}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attacks, err := LoadAttacksCSV(strings.NewReader(test.csv), "test.csv")
+			if attacks != nil {
+				t.Error("invalid catalog returned the attacks")
+			}
+			checkCatalogErrors(t, err, "test.csv", test.want)
+		})
+	}
+}
+
+func TestLoadAttacksCSVColumnsInAnyOrder(t *testing.T) {
+	attacks, err := LoadAttacksCSV(strings.NewReader("Type,Name,SpecialChance,CriticalChance,BlockChance,HitChance,Complexity,Damage\n Punch, Jab,10,5,30,85,5,30\n"), "test.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Attack{Name: "Jab", Type: Punch, Damage: 30, Complexity: 5, HitChance: 85, BlockChance: 30, CriticalChance: 5, SpecialChance: 10}
+	if got := attacks.GetAttackByName("Jab"); got == nil || got.Name != want.Name || got.Type != want.Type || got.Damage != want.Damage || got.HitChance != want.HitChance || got.SpecialChance != want.SpecialChance {
+		t.Errorf("attack %+v, want %+v", got, want)
+	}
+}
+
+func TestEmbeddedCatalogLoads(t *testing.T) {
+	attacks, err := LoadAttacks("")
+	if err != nil {
+		t.Fatalf("embedded catalog is invalid: %v", err)
+	}
+	if len(attacks.ByName) == 0 || attacks.GetAttackByName("Jab") == nil {
+		t.Errorf("embedded catalog has %d attacks, want the default attacks", len(attacks.ByName))
+	}
+	for attackType := AttackType(0); attackType.String() != ""; attackType++ {
+		if attackType != Custom && len(attacks.GetAttacksByType(attackType)) == 0 {
+			t.Errorf("embedded catalog has no %s attacks", attackType)
+		}
+	}
+}
+
+func TestEmbeddedCatalogHasGroundOnlyAttacks(t *testing.T) {
+	groundOnly := 0
+	for _, a := range NewDefaultAttacks().ByName {
+		if a.HasTag(TagGroundOnly) {
+			groundOnly++
+		}
+	}
+	if groundOnly == 0 {
+		t.Error("embedded catalog has no ground-only attacks")
+	}
+}
+
+func TestLoadAttacksYAMLAndJSONParity(t *testing.T) {
+	yamlAttacks, err := LoadAttacks("testdata/attacks.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonAttacks, err := LoadAttacks("testdata/attacks.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(yamlAttacks.ByName) != 4 {
+		t.Errorf("YAML catalog has %d attacks, want 4", len(yamlAttacks.ByName))
+	}
+	if !reflect.DeepEqual(yamlAttacks, jsonAttacks) {
+		t.Error("the same catalog in YAML and JSON loaded different attacks")
+	}
+}
+
+func TestLoadAttacksYAMLSpecialsAndTags(t *testing.T) {
+	attacks, err := LoadAttacks("testdata/attacks.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		specials   []Special
+		groundOnly bool
+	}{
+		// Without the specials the attack type special is rolled with the special chance
+		{"Jab", []Special{{modifiers.CriticalHit, 10}}, false},
+		{"Hip Throw", []Special{{modifiers.Prone, 50}}, false},
+		{"Flying Knee", []Special{{modifiers.Bleeding, 30}, {modifiers.Bruised, 15}}, false},
+		{"Ground and Pound", []Special{{modifiers.CriticalHit, 10}, {modifiers.Disoriented, 20}}, true},
+	}
+	for _, test := range tests {
+		a := attacks.GetAttackByName(test.name)
+		if a == nil {
+			t.Errorf("%s is not in the catalog", test.name)
+			continue
+		}
+		if !reflect.DeepEqual(a.SpecialChances(), test.specials) {
+			t.Errorf("%s specials %+v, want %+v", test.name, a.SpecialChances(), test.specials)
+		}
+		if a.HasTag(TagGroundOnly) != test.groundOnly {
+			t.Errorf("%s ground-only is %v, want %v", test.name, !test.groundOnly, test.groundOnly)
+		}
+	}
+}
+
+// testYAMLAttack is the valid attack, the tests replace its lines to make it invalid
+const testYAMLAttack string = `attacks:
+  - name: Flying Knee
+    type: Knee Strike
+    damage: 75
+    complexity: 45
+    hit_chance: 60
+    block_chance: 15
+    critical_chance: 10
+    specials:
+      - {condition: Bleeding, chance: 30}
+    tags: [ground-only]
+`
+
+func TestLoadAttacksYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []wantError
+	}{
+		{"unknown tag", "[ground-only]", "[ground-only, flying]",
+			[]wantError{{11, 25, "tags.1", `unknown tag "flying"`}}},
+		{"unknown field", "    tags:", "    reach: 2\n    tags:",
+			[]wantError{{11, 12, "reach", "unknown field"}}},
+		{"missing field", "    damage: 75\n", "",
+			[]wantError{{2, 5, "damage", "missing field"}}},
+		{"non-numeric damage", "damage: 75", "damage: lots",
+			[]wantError{{4, 13, "damage", `"lots" is not a number`}}},
+		{"unknown special", "condition: Bleeding", "condition: Sleepy",
+			[]wantError{{10, 21, "specials.0.condition", `unknown condition "Sleepy"`}}},
+		{"special out of range", "chance: 30", "chance: 99",
+			[]wantError{{10, 39, "specials.0.chance", "99 is out of range [5, 95]"}}},
+		{"special chance without specials", "    specials:\n      - {condition: Bleeding, chance: 30}\n", "",
+			[]wantError{{2, 5, "special_chance", "missing field"}}},
+		{"attack not an object", "  - name: Flying Knee", "  - Flying Knee\n  - name: Flying Knee",
+			[]wantError{{2, 5, "", "attack should be an object"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			catalog := strings.Replace(testYAMLAttack, test.old, test.new, 1)
+			if catalog == testYAMLAttack {
+				t.Fatalf("%q is not in the test catalog", test.old)
+			}
+			attacks, err := LoadAttacksYAML(strings.NewReader(catalog), "test.yaml")
+			if attacks != nil {
+				t.Error("invalid catalog returned the attacks")
+			}
+			checkCatalogErrors(t, err, "test.yaml", test.want)
+		})
+	}
+}
+
+func TestLoadAttacksYAMLValid(t *testing.T) {
+	attacks, err := LoadAttacksYAML(strings.NewReader(testYAMLAttack), "test.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := attacks.GetAttackByName("Flying Knee"); a == nil || a.Type != KneeStrike || !a.HasTag(TagGroundOnly) {
+		t.Errorf("attack %+v, want the ground-only Flying Knee", a)
+	}
+}
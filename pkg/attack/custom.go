@@ -0,0 +1,104 @@
+package attack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zerobugdebug/cogfight/pkg/llm"
+)
+
+const (
+	customAttackPrompt  string = "COG_CUSTOM_ATTACK_PROMPT"
+	maxCustomNameLength        = 40
+)
+
+// ErrInvalidAttack means the described move is not a valid or possible single attack
+var ErrInvalidAttack = errors.New("invalid attack")
+
+// customAttackReply is the structured reply of the model for the custom attack description
+type customAttackReply struct {
+	// Verdict is one of valid, invalid or impossible
+	Verdict        string  `json:"verdict"`
+	Reason         string  `json:"reason"`
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+	Damage         float64 `json:"damage"`
+	Complexity     float64 `json:"complexity"`
+	HitChance      float64 `json:"hit_chance"`
+	BlockChance    float64 `json:"block_chance"`
+	CriticalChance float64 `json:"critical_chance"`
+	SpecialChance  float64 `json:"special_chance"`
+}
+
+// DesignCustomAttack asks the model to validate the free text description of the move, classify it and rate it.
+// The attacks are shown to the model as the reference for the stats, which are clamped to the allowed ranges.
+// Moves the model finds invalid or impossible are rejected with ErrInvalidAttack.
+func DesignCustomAttack(ctx context.Context, client llm.Client, attacks *Attacks, description string) (*Attack, error) {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return nil, fmt.Errorf("%w: empty description", ErrInvalidAttack)
+	}
+
+	reference := "Reference attacks (name, type, damage, complexity, hit_chance, block_chance, critical_chance, special_chance):\n"
+	for attackType := AttackType(0); attackType < Custom; attackType++ {
+		if typeAttacks := attacks.GetAttacksByType(attackType); len(typeAttacks) > 0 {
+			a := typeAttacks[0]
+			reference += fmt.Sprintf("%s, %s, %.0f, %.0f, %.0f, %.0f, %.0f, %.0f\n", a.Name, a.Type.String(), a.Damage, a.Complexity, a.HitChance, a.BlockChance, a.CriticalChance, a.SpecialChance)
+		}
+	}
+	request := llm.Request{
+		Prompt: customAttackPrompt,
+		Messages: []llm.Message{
+			{Role: "user", Content: reference + "Move description: " + description},
+		},
+		JSON: true,
+	}
+	replyText, err := client.Complete(ctx, request, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := customAttackReply{}
+	err = json.Unmarshal([]byte(extractJSON(replyText)), &reply)
+	if err != nil {
+		return nil, fmt.Errorf("%w: error decoding custom attack from JSON: %v\nReply: %s", llm.ErrProtocol, err, replyText)
+	}
+	if !strings.EqualFold(reply.Verdict, "valid") {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAttack, strings.TrimSpace(reply.Verdict+" "+reply.Reason))
+	}
+	attackType, err := ParseAttackType(reply.Type)
+	if err != nil || attackType == Custom {
+		return nil, fmt.Errorf("%w: can't classify the move as one of the attack types: %q", llm.ErrProtocol, reply.Type)
+	}
+
+	name := strings.TrimSpace(reply.Name)
+	if name == "" {
+		name = description
+	}
+	if len([]rune(name)) > maxCustomNameLength {
+		name = string([]rune(name)[:maxCustomNameLength])
+	}
+	return &Attack{
+		Name:           name,
+		Type:           attackType,
+		Damage:         Clamp(reply.Damage, MinDamage, MaxDamage),
+		Complexity:     Clamp(reply.Complexity, MinComplexity, MaxComplexity),
+		HitChance:      Clamp(reply.HitChance, MinHitChance, MaxHitChance),
+		BlockChance:    Clamp(reply.BlockChance, MinBlockChance, MaxBlockChance),
+		CriticalChance: Clamp(reply.CriticalChance, MinCriticalHitChance, MaxCriticalHitChance),
+		SpecialChance:  Clamp(reply.SpecialChance, MinSpecialChance, MaxSpecialChance),
+	}, nil
+}
+
+// extractJSON returns the JSON object from the reply, dropping any text or code fences around it
+func extractJSON(reply string) string {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return reply
+	}
+	return reply[start : end+1]
+}
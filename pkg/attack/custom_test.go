@@ -0,0 +1,104 @@
+package attack
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zerobugdebug/cogfight/pkg/llm"
+)
+
+// stubClient replies with the fixed text and keeps the last request
+type stubClient struct {
+	reply   string
+	err     error
+	request llm.Request
+}
+
+func (c *stubClient) Complete(ctx context.Context, request llm.Request, onChunk func(chunk string)) (string, error) {
+	c.request = request
+	return c.reply, c.err
+}
+
+func TestDesignCustomAttack(t *testing.T) {
+	attacks := NewAttacks()
+	attacks.AddAttack(&Attack{Name: "Jab", Type: Punch, Damage: 20, Complexity: 5, HitChance: 80, BlockChance: 40, CriticalChance: 5, SpecialChance: 10})
+
+	tests := []struct {
+		name    string
+		reply   string
+		want    *Attack
+		wantErr error
+	}{
+		{
+			"valid",
+			`{"verdict": "valid", "name": "Siberian Hook", "type": "punch", "damage": 70, "complexity": 40, "hit_chance": 60, "block_chance": 30, "critical_chance": 10, "special_chance": 15}`,
+			&Attack{Name: "Siberian Hook", Type: Punch, Damage: 70, Complexity: 40, HitChance: 60, BlockChance: 30, CriticalChance: 10, SpecialChance: 15},
+			nil,
+		},
+		{
+			"fenced reply without the name",
+			"```json\n{\"verdict\": \"Valid\", \"type\": \"kick\", \"damage\": 50, \"complexity\": 30, \"hit_chance\": 50, \"block_chance\": 20, \"critical_chance\": 5, \"special_chance\": 5}\n```",
+			&Attack{Name: "spinning back kick", Type: Kick, Damage: 50, Complexity: 30, HitChance: 50, BlockChance: 20, CriticalChance: 5, SpecialChance: 5},
+			nil,
+		},
+		{
+			"clamped stats",
+			`{"verdict": "valid", "name": "Death Touch", "type": "slap", "damage": 9000, "complexity": -10, "hit_chance": 100, "block_chance": 99, "critical_chance": 0, "special_chance": 100}`,
+			&Attack{Name: "Death Touch", Type: Slap, Damage: MaxDamage, Complexity: MinComplexity, HitChance: MaxHitChance, BlockChance: MaxBlockChance,
+				CriticalChance: MinCriticalHitChance, SpecialChance: MaxSpecialChance},
+			nil,
+		},
+		{"invalid", `{"verdict": "invalid", "reason": "it's a dance move"}`, nil, ErrInvalidAttack},
+		{"impossible", `{"verdict": "impossible", "reason": "nobody can fly"}`, nil, ErrInvalidAttack},
+		{"bad JSON", `{"verdict": "valid", "damage": "a lot"}`, nil, llm.ErrProtocol},
+		{"no JSON", "I can't rate this move", nil, llm.ErrProtocol},
+		{"unknown type", `{"verdict": "valid", "name": "Headbutt", "type": "headbutt"}`, nil, llm.ErrProtocol},
+		{"custom type", `{"verdict": "valid", "name": "Headbutt", "type": "custom"}`, nil, llm.ErrProtocol},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &stubClient{reply: test.reply}
+			got, err := DesignCustomAttack(context.Background(), client, attacks, " spinning back kick ")
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("got error %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+			if !client.request.JSON || len(client.request.Messages) != 1 || !strings.Contains(client.request.Messages[0].Content, "Jab, Punch, 20") ||
+				!strings.HasSuffix(client.request.Messages[0].Content, "Move description: spinning back kick") {
+				t.Errorf("request doesn't ask for the JSON rating of the description with the reference attacks: %+v", client.request)
+			}
+		})
+	}
+}
+
+func TestDesignCustomAttackErrors(t *testing.T) {
+	failure := errors.New("connection refused")
+	tests := []struct {
+		name        string
+		description string
+		err         error
+		want        error
+	}{
+		{"empty description", "  ", nil, ErrInvalidAttack},
+		{"client error", "jab", failure, failure},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := DesignCustomAttack(context.Background(), &stubClient{err: test.err}, NewAttacks(), test.description)
+			if !errors.Is(err, test.want) {
+				t.Errorf("got error %v, want %v", err, test.want)
+			}
+		})
+	}
+}
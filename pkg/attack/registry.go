@@ -0,0 +1,134 @@
+package attack
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zerobugdebug/cogfight/pkg/logging"
+)
+
+// Registry holds the attack catalog loaded once and shared by the fighters and the matches.
+// The catalog is never changed after loading, so it can be read from many goroutines at once,
+// reloading replaces the whole catalog and the readers holding the previous one keep using it.
+type Registry struct {
+	filename string
+	attacks  atomic.Pointer[Attacks]
+
+	// reloadMu serializes the reloads and guards the file state
+	reloadMu sync.Mutex
+	modTime  time.Time
+	size     int64
+}
+
+var (
+	defaultRegistry     *Registry
+	defaultRegistryOnce sync.Once
+)
+
+// NewRegistry loads the attack catalog from the CSV, JSON or YAML file, or the embedded catalog if filename is empty
+func NewRegistry(filename string) (*Registry, error) {
+	registry := &Registry{filename: filename}
+	err := registry.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// NewStaticRegistry returns the registry holding the attacks, which can't be reloaded
+func NewStaticRegistry(attacks *Attacks) *Registry {
+	registry := &Registry{}
+	registry.attacks.Store(attacks)
+	return registry
+}
+
+// DefaultRegistry returns the registry of the embedded catalog, loaded on the first call
+func DefaultRegistry() *Registry {
+	defaultRegistryOnce.Do(func() {
+		defaultRegistry = NewStaticRegistry(NewDefaultAttacks())
+	})
+	return defaultRegistry
+}
+
+// Attacks returns the current attack catalog, it must not be changed
+func (r *Registry) Attacks() *Attacks {
+	return r.attacks.Load()
+}
+
+// Filename returns the catalog file, or an empty string for the embedded catalog
+func (r *Registry) Filename() string {
+	return r.filename
+}
+
+// Reload reads the catalog file again, the current catalog is kept if the file is invalid
+func (r *Registry) Reload() error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+	if r.filename == "" {
+		if r.Attacks() == nil {
+			r.attacks.Store(NewDefaultAttacks())
+		}
+		return nil
+	}
+
+	// Remember the file state before reading it, so the changes made while reading are picked up by the next check
+	info, err := os.Stat(r.filename)
+	if err != nil {
+		return fmt.Errorf("error reading attack catalog: %s", err)
+	}
+	attacks, err := LoadAttacks(r.filename)
+	if err != nil {
+		return err
+	}
+	r.modTime, r.size = info.ModTime(), info.Size()
+	r.attacks.Store(attacks)
+	return nil
+}
+
+// changed reports whether the catalog file was modified since the last reload
+func (r *Registry) changed() bool {
+	info, err := os.Stat(r.filename)
+	if err != nil {
+		return false
+	}
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+	return !info.ModTime().Equal(r.modTime) || info.Size() != r.size
+}
+
+// Watch checks the catalog file for changes every interval and reloads it until the context is done.
+// It's meant for the development, the invalid catalog is reported and the previous one stays in use until the file is fixed.
+func (r *Registry) Watch(ctx context.Context, interval time.Duration) {
+	if r.filename == "" {
+		logging.Warn("The embedded attack catalog can't be watched, set the catalog file to reload it")
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			err := r.Reload()
+			if err != nil {
+				logging.Errorf("Keeping the previous attack catalog: %v", err)
+				// Don't report the same broken file on every check
+				if info, statErr := os.Stat(r.filename); statErr == nil {
+					r.reloadMu.Lock()
+					r.modTime, r.size = info.ModTime(), info.Size()
+					r.reloadMu.Unlock()
+				}
+				continue
+			}
+			logging.Infof("Reloaded attack catalog %s: %d attacks", r.filename, len(r.Attacks().ByName))
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package attack
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Stance is the defensive action the fighter takes against the opponent's next attack
+type Stance int
+
+const (
+	// Block raises the block chance
+	Block Stance = iota
+	// Dodge adds the evasion roll after the attack hits, the evasion chance depends on the agility
+	Dodge
+	// Counter lowers the block chance, but gives the chance to strike back when the attack doesn't land
+	Counter
+	// Clinch cancels the strikes and makes the grapples harder to block, the fighter's own grapples land easier while it holds the clinch
+	Clinch
+)
+
+const (
+	// BlockStanceBonus is added to the block chance of the attacks against the fighter in the Block stance
+	BlockStanceBonus float64 = 20
+	// CounterStancePenalty is subtracted from the block chance of the attacks against the fighter in the Counter stance
+	CounterStancePenalty float64 = 10
+	// ClinchStancePenalty is subtracted from the block chance of the grapples against the fighter in the Clinch stance
+	ClinchStancePenalty float64 = 25
+	// ClinchGrappleBonus is added to the hit chance of the grapples of the fighter holding the clinch
+	ClinchGrappleBonus float64 = 15
+)
+
+// Stances lists all stances in the order they are offered to the player
+var Stances = []Stance{Block, Dodge, Counter, Clinch}
+
+var stanceNames = map[Stance]string{
+	Block:   "Block",
+	Dodge:   "Dodge",
+	Counter: "Counter",
+	Clinch:  "Clinch",
+}
+
+var stanceHints = map[Stance]string{
+	Block:   "Keep the guard up, higher chance to block the attack",
+	Dodge:   "Try to evade the attack after it hits, agile fighters evade more often",
+	Counter: "Lower chance to block, but strike back when the attack doesn't land",
+	Clinch:  "Tie up the opponent, strikes can't land and your next throw, lock or choke hits easier, but the opponent's grapples are harder to block",
+}
+
+// String returns the name of the stance
+func (s Stance) String() string {
+	return stanceNames[s]
+}
+
+// Hint returns the description of the stance for the player
+func (s Stance) Hint() string {
+	return stanceHints[s]
+}
+
+// BlockBonus returns the change of the block chance of the attacks against the fighter in the stance
+func (s Stance) BlockBonus() float64 {
+	switch s {
+	case Block:
+		return BlockStanceBonus
+	case Counter:
+		return -CounterStancePenalty
+	case Clinch:
+		return -ClinchStancePenalty
+	default:
+		return 0
+	}
+}
+
+// ParseStance returns the stance by its name, ignoring the case
+func ParseStance(name string) (Stance, error) {
+	for stance, stanceName := range stanceNames {
+		if strings.EqualFold(strings.TrimSpace(name), stanceName) {
+			return stance, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown stance %q", name)
+}
+
+// Grapple reports whether the attacks of the type are grapples, which land on the fighter in the Clinch stance.
+// All other attacks are strikes.
+func (at AttackType) Grapple() bool {
+	return at == Throw || at == Lock || at == Choke
+}
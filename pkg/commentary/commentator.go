@@ -0,0 +1,90 @@
+package commentary
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/fighter"
+	"github.com/zerobugdebug/cogfight/pkg/llm"
+	"github.com/zerobugdebug/cogfight/pkg/logging"
+)
+
+// Commentator comments the match in the terminal
+type Commentator interface {
+	// Introduce comments the fighters before the fight starts
+	Introduce(f1, f2 *fighter.Fighter) error
+	// Comment comments the events of the turn
+	Comment(events []event.Event) error
+}
+
+// Providers lists the names of the built-in commentators
+var Providers = []string{"llm", "template", "silent"}
+
+// CommentatorByName returns the built-in commentator for the provider name.
+// The llm commentator falls back to the template one when the model fails, so the fight can always finish.
+func CommentatorByName(name string, client llm.Client, rng *rand.Rand) (Commentator, error) {
+	switch strings.ToLower(name) {
+	case "llm":
+		if client == nil {
+			return nil, fmt.Errorf("llm commentary requires the LLM client")
+		}
+		return &FallbackCommentator{Primary: &LLMCommentator{Client: client}, Fallback: NewTemplateCommentator(rng)}, nil
+	case "template":
+		return NewTemplateCommentator(rng), nil
+	case "silent":
+		return SilentCommentator{}, nil
+	default:
+		return nil, fmt.Errorf("unknown commentary provider %q, should be one of: %s", name, strings.Join(Providers, ", "))
+	}
+}
+
+// SilentCommentator doesn't comment at all
+type SilentCommentator struct{}
+
+// Introduce does nothing
+func (c SilentCommentator) Introduce(f1, f2 *fighter.Fighter) error {
+	return nil
+}
+
+// Comment does nothing
+func (c SilentCommentator) Comment(events []event.Event) error {
+	return nil
+}
+
+// FallbackCommentator uses the primary commentator until it fails and the fallback commentator for the rest of the match
+type FallbackCommentator struct {
+	Primary  Commentator
+	Fallback Commentator
+	failed   bool
+}
+
+// Introduce introduces the fighters with the primary commentator, or with the fallback one if the primary fails
+func (c *FallbackCommentator) Introduce(f1, f2 *fighter.Fighter) error {
+	if !c.failed {
+		err := c.Primary.Introduce(f1, f2)
+		if err == nil {
+			return nil
+		}
+		c.fail(err)
+	}
+	return c.Fallback.Introduce(f1, f2)
+}
+
+// Comment comments the turn with the primary commentator, or with the fallback one if the primary fails
+func (c *FallbackCommentator) Comment(events []event.Event) error {
+	if !c.failed {
+		err := c.Primary.Comment(events)
+		if err == nil {
+			return nil
+		}
+		c.fail(err)
+	}
+	return c.Fallback.Comment(events)
+}
+
+func (c *FallbackCommentator) fail(err error) {
+	c.failed = true
+	logging.Warnf("Commentary is not available, switching to the offline commentary: %v", err)
+}
@@ -0,0 +1,149 @@
+package commentary
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/fighter"
+	"github.com/zerobugdebug/cogfight/pkg/llm"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+// stubClient replies with the fixed comment
+type stubClient struct{}
+
+func (c stubClient) Complete(ctx context.Context, request llm.Request, onChunk func(chunk string)) (string, error) {
+	return "What a fight!", nil
+}
+
+func TestCommentatorByName(t *testing.T) {
+	tests := []struct {
+		name   string
+		client llm.Client
+		want   Commentator
+		err    string
+	}{
+		{"llm", stubClient{}, &FallbackCommentator{}, ""},
+		{"Template", nil, &TemplateCommentator{}, ""},
+		{"silent", nil, SilentCommentator{}, ""},
+		{"llm", nil, nil, "llm commentary requires the LLM client"},
+		{"radio", nil, nil, `unknown commentary provider "radio", should be one of: llm, template, silent`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := CommentatorByName(test.name, test.client, rand.New(rand.NewSource(1)))
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Errorf("got error %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reflect.TypeOf(got) != reflect.TypeOf(test.want) {
+				t.Errorf("got %T, want %T", got, test.want)
+			}
+		})
+	}
+}
+
+// recordingCommentator records the calls and fails the calls from failAt on, if failAt is set
+type recordingCommentator struct {
+	calls  int
+	failAt int
+}
+
+func (c *recordingCommentator) comment() error {
+	c.calls++
+	if c.failAt > 0 && c.calls >= c.failAt {
+		return errors.New("connection lost")
+	}
+	return nil
+}
+
+func (c *recordingCommentator) Introduce(f1, f2 *fighter.Fighter) error {
+	return c.comment()
+}
+
+func (c *recordingCommentator) Comment(events []event.Event) error {
+	return c.comment()
+}
+
+func TestFallbackCommentator(t *testing.T) {
+	tests := []struct {
+		name string
+		// failAt is the call the primary commentator fails at, 0 if it never fails
+		failAt                    int
+		wantPrimary, wantFallback int
+	}{
+		{"primary works", 0, 4, 0},
+		{"introduction fails", 1, 1, 4},
+		{"second turn fails", 3, 3, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			primary, fallback := &recordingCommentator{failAt: test.failAt}, &recordingCommentator{}
+			c := &FallbackCommentator{Primary: primary, Fallback: fallback}
+			f1, f2 := fighter.NewFighter("Tom", fighter.DefaultBuild()), fighter.NewFighter("Jerry", fighter.DefaultBuild())
+			if err := c.Introduce(f1, f2); err != nil {
+				t.Fatal(err)
+			}
+			for turn := 0; turn < 3; turn++ {
+				if err := c.Comment(nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// The failed call is repeated by the fallback, and the primary isn't asked again after the failure
+			if primary.calls != test.wantPrimary || fallback.calls != test.wantFallback {
+				t.Errorf("primary was called %d times and fallback %d, want %d and %d", primary.calls, fallback.calls, test.wantPrimary, test.wantFallback)
+			}
+		})
+	}
+}
+
+func TestTemplateCommentator(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []event.Event
+		want   []string
+	}{
+		{"attack", []event.Event{event.AttackAttempted{Attacker: "Tom", Defender: "Jerry", Attack: "Jab"}}, []string{"Tom", "Jab"}},
+		{"fumble", []event.Event{event.ComplexityRoll{Fighter: "Tom", Success: false}}, []string{"Tom"}},
+		{"executed", []event.Event{event.ComplexityRoll{Fighter: "Tom", Success: true}}, nil},
+		{"sure strike", []event.Event{event.HitRoll{Attacker: "Tom", Defender: "Jerry", Success: true, SureStrike: true}}, []string{"Jerry is defenseless!"}},
+		{"block", []event.Event{event.BlockRoll{Defender: "Jerry", Blocked: true}}, []string{"Jerry"}},
+		{"stacked special", []event.Event{event.SpecialApplied{Attacker: "Tom", Defender: "Jerry", Condition: modifiers.Bleeding, Stacks: 2}},
+			[]string{"Jerry is Bleeding even worse now, that's 2 times!"}},
+		{"hit", []event.Event{event.DamageDealt{Attacker: "Tom", Target: "Jerry", Amount: 40, Health: -5, MaxHealth: 250, Attack: "Jab"}}, []string{"Jerry", "0 of 250"}},
+		{"condition damage", []event.Event{event.DamageDealt{Target: "Jerry", Amount: 20, Condition: modifiers.Bleeding}}, []string{"Jerry loses 20 more health to Bleeding."}},
+		{"condition healing", []event.Event{event.DamageDealt{Target: "Jerry", Amount: -5, Condition: modifiers.Bleeding}}, nil},
+		{"draw", []event.Event{event.Decision{Side: -1}}, []string{"The judges can't separate them, it's a draw!"}},
+		{"condition knockout", []event.Event{event.KnockOut{Fighter: "Jerry", Condition: modifiers.Bleeding}}, []string{"Jerry collapses from Bleeding, it's over!"}},
+		{"forfeit", []event.Event{event.Forfeit{Side: 1, Fighter: "Jerry"}}, []string{"The towel flies in from Jerry's corner, it's over!"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lines := NewTemplateCommentator(rand.New(rand.NewSource(1))).describe(test.events)
+			if test.want == nil {
+				if len(lines) != 0 {
+					t.Errorf("got %q, want no comments", lines)
+				}
+				return
+			}
+			if len(lines) != 1 {
+				t.Fatalf("got %q, want a single line", lines)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(lines[0], want) {
+					t.Errorf("got %q, want it to contain %q", lines[0], want)
+				}
+			}
+		})
+	}
+}
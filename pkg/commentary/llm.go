@@ -0,0 +1,60 @@
+package commentary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/fighter"
+	"github.com/zerobugdebug/cogfight/pkg/llm"
+	"github.com/zerobugdebug/cogfight/pkg/ui"
+)
+
+const (
+	turnCommentPrompt string = "COG_TURN_COMMENT_PROMPT"
+)
+
+// LLMCommentator streams the comments from the language model, keeping the whole match as the chat history
+type LLMCommentator struct {
+	Client       llm.Client
+	chatMessages []llm.Message
+}
+
+// Introduce asks the commentators to introduce themselves and the fighters
+func (c *LLMCommentator) Introduce(f1, f2 *fighter.Fighter) error {
+	fmt.Println("Waiting for the comments...")
+	situation := fmt.Sprintf("Fight not started yet. Commentators introduce themselves and talk about the fighters\nFirst fighter: %s Second fighter: %s", f1.String(), f2.String())
+	return c.ask(situation)
+}
+
+// Comment asks the commentators to comment the turn
+func (c *LLMCommentator) Comment(events []event.Event) error {
+	return c.ask(Situation(events))
+}
+
+// ask sends the situation to the model, prints the streamed comments and adds both to the chat history.
+// Ctrl-C while waiting for the comments skips them without stopping the fight.
+func (c *LLMCommentator) ask(situation string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	chatMessages := append(c.chatMessages, llm.Message{Role: "user", Content: situation})
+	colorStack := []string{"default"}
+	comments, err := c.Client.Complete(ctx, llm.Request{Prompt: turnCommentPrompt, Messages: chatMessages}, func(chunk string) {
+		var coloredText string
+		coloredText, colorStack = ui.ColorizeChunk(chunk, colorStack)
+		fmt.Print(coloredText)
+	})
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("\nComments skipped.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("can't get the comments: %w", err)
+	}
+	c.chatMessages = append(chatMessages, llm.Message{Role: "assistant", Content: comments})
+	return nil
+}
@@ -0,0 +1,113 @@
+package commentary
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+	"github.com/zerobugdebug/cogfight/pkg/ui"
+)
+
+// Situation describes the turn events in plain English for the commentators
+func Situation(events []event.Event) string {
+	header := ""
+	situationDescription := ""
+	for _, e := range events {
+		switch e := e.(type) {
+		case event.TurnStarted:
+			header = fmt.Sprintf("Turn %d: %s attacks %s. ", e.Turn, e.Attacker, e.Defender)
+		case event.ConditionExpired:
+			situationDescription += e.Fighter + " is not " + e.Condition.String() + " anymore. "
+		case event.TurnSkipped:
+			situationDescription += e.Fighter + " is currently " + e.Condition.String() + ". "
+			situationDescription += e.Fighter + " cannot attack. "
+		case event.AttackAttempted:
+			situationDescription += e.Attacker + " executing " + e.Attack + ". "
+		case event.ComplexityRoll:
+			situationDescription += fmt.Sprintf("That is a %s level attack. ", ui.PercentileWithType(e.Complexity, attack.MinComplexity, attack.MaxComplexity, "complexity"))
+			if e.Success {
+				situationDescription += "Attack executed successfully! "
+			} else {
+				situationDescription += e.Fighter + " failed to execute attack! "
+			}
+		case event.HitRoll:
+			if e.SureStrike {
+				situationDescription += e.Defender + " is defenseless. "
+			}
+			situationDescription += fmt.Sprintf("Attack has a %s chance to hit. ", ui.PercentileDefault(e.Chance, attack.MinHitChance, attack.MaxHitChance))
+			if e.Success {
+				situationDescription += "Attack sucessfully hit the " + e.Defender + ". "
+			} else {
+				situationDescription += e.Attacker + " attack missed the " + e.Defender + ". "
+			}
+		case event.StrikeCancelled:
+			situationDescription += e.Defender + " holds " + e.Attacker + " in the clinch, the " + e.Attack + " can't land. "
+		case event.DodgeRoll:
+			if e.Dodged {
+				situationDescription += e.Defender + " dodged the attack. "
+			} else {
+				situationDescription += e.Defender + " tried to dodge, but was too slow. "
+			}
+		case event.CounterRoll:
+			if e.Success {
+				situationDescription += e.Defender + " counters the failed attack. "
+			}
+		case event.StanceChosen:
+			if e.Condition != modifiers.Healthy {
+				situationDescription += fmt.Sprintf("%s can't move and can only %s. ", e.Fighter, strings.ToLower(e.Stance.String()))
+			} else {
+				situationDescription += fmt.Sprintf("%s takes the %s stance. ", e.Fighter, strings.ToLower(e.Stance.String()))
+			}
+		case event.BlockRoll:
+			situationDescription += fmt.Sprintf("%s has a %s chance to block the attack. ", e.Defender, ui.PercentileDefault(e.Chance, attack.MinBlockChance, attack.MaxBlockChance))
+			if e.Blocked {
+				situationDescription += e.Defender + " blocked the attack. "
+			} else {
+				situationDescription += e.Defender + " was not able to block the attack. "
+			}
+		case event.SpecialApplied:
+			situationDescription += e.Defender + " become " + e.Condition.String() + ". "
+			if e.Stacks > 1 {
+				situationDescription += fmt.Sprintf("%s is %s %d times over. ", e.Defender, e.Condition.String(), e.Stacks)
+			}
+		case event.ConditionResisted:
+			if e.Immunity > 0 {
+				situationDescription += e.Defender + " is immune to " + e.Condition.String() + " for now. "
+			} else {
+				situationDescription += e.Defender + " is already " + e.Condition.String() + ". "
+			}
+		case event.DamageDealt:
+			if e.Counter {
+				situationDescription += fmt.Sprintf("%s takes a %s damage from the counter. ", e.Target, ui.PercentileDefault(float64(e.Amount), attack.MinDamage, attack.MaxDamage))
+			} else if e.Attack != "" {
+				for _, multiplier := range e.Multipliers {
+					situationDescription += e.Attacker + " executed " + multiplier.String() + ". "
+				}
+				situationDescription += fmt.Sprintf("%s takes a %s damage. ", e.Target, ui.PercentileDefault(float64(e.Amount), attack.MinDamage, attack.MaxDamage))
+			} else if e.Amount > 0 {
+				situationDescription += fmt.Sprintf("%s takes %d damage due to %s. ", e.Target, e.Amount, e.Condition.String())
+			}
+		case event.RoundStarted:
+			situationDescription += fmt.Sprintf("Round %d of %d starts. ", e.Round, e.Rounds)
+		case event.RoundEnded:
+			situationDescription += fmt.Sprintf("Round %d is over. ", e.Round)
+		case event.Decision:
+			if e.Side < 0 {
+				situationDescription += "The fight went the distance and the judges scored it a draw. "
+			} else {
+				situationDescription += fmt.Sprintf("The fight went the distance, %s wins by %s decision. ", e.Fighter, e.Type)
+			}
+		case event.KnockOut:
+			if e.Condition == modifiers.Healthy {
+				situationDescription += e.Fighter + " is knocked out. "
+			} else {
+				situationDescription += e.Fighter + " lost consciousness. "
+			}
+		case event.Forfeit:
+			situationDescription += e.Fighter + " forfeits the fight. "
+		}
+	}
+	return header + situationDescription
+}
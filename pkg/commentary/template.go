@@ -0,0 +1,176 @@
+package commentary
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/fighter"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+	"github.com/zerobugdebug/cogfight/pkg/ui"
+)
+
+const (
+	templateCommentatorName string = "Ringside"
+)
+
+var (
+	introPhrases = []string{
+		"Welcome to the CogFight! Tonight %s meets %s in the ring!",
+		"Ladies and gentlemen, it's %s against %s, and the crowd is on its feet!",
+		"Here we go! %s and %s step into the ring, only one will walk out a winner!",
+	}
+	attackPhrases = []string{
+		"%s goes for the %s!",
+		"%s loads up a %s!",
+		"Here comes the %[2]s from %[1]s!",
+	}
+	fumblePhrases = []string{
+		"%s fumbles it, the technique just isn't there!",
+		"Sloppy! %s couldn't pull it off!",
+	}
+	missPhrases = []string{
+		"%s slips away, it's a clean miss!",
+		"Nothing but air, %s wasn't there!",
+	}
+	blockPhrases = []string{
+		"%s blocks it!",
+		"Solid defense from %s, that one was stopped!",
+	}
+	dodgePhrases = []string{
+		"%s ducks under it!",
+		"Great footwork, %s just isn't there anymore!",
+	}
+	counterPhrases = []string{
+		"%s makes them pay with a counter, %d damage!",
+		"And %s answers right back for %d!",
+	}
+	hitPhrases = []string{
+		"%s takes a %s hit and is at %d of %d!",
+		"That's a %[2]s shot on %[1]s, %[3]d of %[4]d left!",
+	}
+	skipPhrases = []string{
+		"%s is %s and can only watch!",
+		"%s can't answer back, still %s!",
+	}
+	knockOutPhrases = []string{
+		"It's all over! %s is down and out!",
+		"%s hits the canvas, and that's the end of it!",
+	}
+)
+
+// TemplateCommentator writes the play-by-play from the turn events without any network connection
+type TemplateCommentator struct {
+	rng *rand.Rand
+}
+
+// NewTemplateCommentator creates an offline commentator that picks the phrases with the random source
+func NewTemplateCommentator(rng *rand.Rand) *TemplateCommentator {
+	return &TemplateCommentator{rng: rng}
+}
+
+// Introduce announces the fighters
+func (c *TemplateCommentator) Introduce(f1, f2 *fighter.Fighter) error {
+	c.print([]string{fmt.Sprintf(c.pick(introPhrases), f1.Name, f2.Name)})
+	return nil
+}
+
+// Comment describes the turn events
+func (c *TemplateCommentator) Comment(events []event.Event) error {
+	c.print(c.describe(events))
+	return nil
+}
+
+// describe returns the lines of the play-by-play for the turn events
+func (c *TemplateCommentator) describe(events []event.Event) []string {
+	var lines []string
+	for _, e := range events {
+		switch e := e.(type) {
+		case event.ConditionExpired:
+			lines = append(lines, fmt.Sprintf("%s is not %s anymore.", e.Fighter, e.Condition.String()))
+		case event.TurnSkipped:
+			lines = append(lines, fmt.Sprintf(c.pick(skipPhrases), e.Fighter, e.Condition.String()))
+		case event.AttackAttempted:
+			lines = append(lines, fmt.Sprintf(c.pick(attackPhrases), e.Attacker, e.Attack))
+		case event.ComplexityRoll:
+			if !e.Success {
+				lines = append(lines, fmt.Sprintf(c.pick(fumblePhrases), e.Fighter))
+			}
+		case event.HitRoll:
+			if !e.Success {
+				lines = append(lines, fmt.Sprintf(c.pick(missPhrases), e.Defender))
+			} else if e.SureStrike {
+				lines = append(lines, fmt.Sprintf("%s is defenseless!", e.Defender))
+			}
+		case event.StrikeCancelled:
+			lines = append(lines, fmt.Sprintf("%s ties up %s, the %s goes nowhere in the clinch!", e.Defender, e.Attacker, e.Attack))
+		case event.DodgeRoll:
+			if e.Dodged {
+				lines = append(lines, fmt.Sprintf(c.pick(dodgePhrases), e.Defender))
+			}
+		case event.BlockRoll:
+			if e.Blocked {
+				lines = append(lines, fmt.Sprintf(c.pick(blockPhrases), e.Defender))
+			}
+		case event.SpecialApplied:
+			if e.Stacks > 1 {
+				lines = append(lines, fmt.Sprintf("%s is %s even worse now, that's %d times!", e.Defender, e.Condition.String(), e.Stacks))
+			} else {
+				lines = append(lines, fmt.Sprintf("%s is %s now!", e.Defender, e.Condition.String()))
+			}
+		case event.ConditionResisted:
+			if e.Immunity > 0 {
+				lines = append(lines, fmt.Sprintf("%s shrugs it off, not %s again so soon!", e.Defender, e.Condition.String()))
+			}
+		case event.DamageDealt:
+			if e.Counter {
+				lines = append(lines, fmt.Sprintf(c.pick(counterPhrases), e.Attacker, e.Amount))
+			} else if e.Attack != "" {
+				for _, multiplier := range e.Multipliers {
+					lines = append(lines, fmt.Sprintf("What a %s from %s!", multiplier.String(), e.Attacker))
+				}
+				strength := strings.ToLower(ui.PercentileDefault(float64(e.Amount), attack.MinDamage, attack.MaxDamage))
+				health := e.Health
+				if health < 0 {
+					health = 0
+				}
+				lines = append(lines, fmt.Sprintf(c.pick(hitPhrases), e.Target, strength, health, e.MaxHealth))
+			} else if e.Amount > 0 {
+				lines = append(lines, fmt.Sprintf("%s loses %d more health to %s.", e.Target, e.Amount, e.Condition.String()))
+			}
+		case event.RoundEnded:
+			lines = append(lines, fmt.Sprintf("There's the bell, round %d is in the books!", e.Round))
+		case event.Decision:
+			if e.Side < 0 {
+				lines = append(lines, "The judges can't separate them, it's a draw!")
+			} else {
+				lines = append(lines, fmt.Sprintf("%s takes it on the scorecards by %s decision!", e.Fighter, e.Type))
+			}
+		case event.KnockOut:
+			if e.Condition == modifiers.Healthy {
+				lines = append(lines, fmt.Sprintf(c.pick(knockOutPhrases), e.Fighter))
+			} else {
+				lines = append(lines, fmt.Sprintf("%s collapses from %s, it's over!", e.Fighter, e.Condition.String()))
+			}
+		case event.Forfeit:
+			lines = append(lines, fmt.Sprintf("The towel flies in from %s's corner, it's over!", e.Fighter))
+		}
+	}
+	return lines
+}
+
+// pick returns a random phrase
+func (c *TemplateCommentator) pick(phrases []string) string {
+	return phrases[c.rng.Intn(len(phrases))]
+}
+
+// print writes the lines the same way the proxy commentators are displayed
+func (c *TemplateCommentator) print(lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	coloredText, _ := ui.ColorizeChunk(fmt.Sprintf("[%s]: \"%s\"\n", templateCommentatorName, strings.Join(lines, " ")), []string{"default"})
+	fmt.Print(coloredText)
+}
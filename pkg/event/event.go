@@ -0,0 +1,268 @@
+package event
+
+import (
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/logging"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+// Kind identifies the type of the event
+type Kind string
+
+const (
+	KindTurnStarted       Kind = "TurnStarted"
+	KindConditionExpired  Kind = "ConditionExpired"
+	KindTurnSkipped       Kind = "TurnSkipped"
+	KindAttackAttempted   Kind = "AttackAttempted"
+	KindComplexityRoll    Kind = "ComplexityRoll"
+	KindHitRoll           Kind = "HitRoll"
+	KindStrikeCancelled   Kind = "StrikeCancelled"
+	KindDodgeRoll         Kind = "DodgeRoll"
+	KindBlockRoll         Kind = "BlockRoll"
+	KindCounterRoll       Kind = "CounterRoll"
+	KindSpecialRoll       Kind = "SpecialRoll"
+	KindSpecialApplied    Kind = "SpecialApplied"
+	KindConditionResisted Kind = "ConditionResisted"
+	KindDamageDealt       Kind = "DamageDealt"
+	KindKnockOut          Kind = "KnockOut"
+	KindStanceChosen      Kind = "StanceChosen"
+	KindTurnEnded         Kind = "TurnEnded"
+	KindRoundStarted      Kind = "RoundStarted"
+	KindRoundEnded        Kind = "RoundEnded"
+	KindDecision          Kind = "Decision"
+	KindForfeit           Kind = "Forfeit"
+)
+
+// DecisionType tells how the judges agreed on the winner
+type DecisionType string
+
+const (
+	// Unanimous decision: all judges picked the winner
+	Unanimous DecisionType = "unanimous"
+	// Majority decision: some judges picked the winner and the others scored a draw
+	Majority DecisionType = "majority"
+	// Split decision: more judges picked the winner than the other fighter
+	Split DecisionType = "split"
+	// Draw: the judges couldn't pick the winner
+	Draw DecisionType = "draw"
+)
+
+// Event represents something that happened during the match
+type Event interface {
+	Kind() Kind
+}
+
+// Subscriber receives the match events as they happen
+type Subscriber interface {
+	Notify(e Event)
+}
+
+// SubscriberFunc allows to use an ordinary function as a Subscriber
+type SubscriberFunc func(e Event)
+
+// Notify calls f(e)
+func (f SubscriberFunc) Notify(e Event) {
+	f(e)
+}
+
+// Logger is a Subscriber that writes every event to the debug log
+type Logger struct{}
+
+// Notify logs the event
+func (l Logger) Notify(e Event) {
+	logging.Debugf("%s %+v", e.Kind(), e)
+}
+
+// TurnStarted is emitted before anything else happens in the turn, Side is the index of the attacker in the match
+type TurnStarted struct {
+	Turn     int
+	Side     int
+	Attacker string
+	Defender string
+}
+
+// ConditionExpired is emitted when the condition wears off the fighter
+type ConditionExpired struct {
+	Fighter   string
+	Condition modifiers.Condition
+}
+
+// TurnSkipped is emitted when the fighter can't attack due to the condition
+type TurnSkipped struct {
+	Fighter   string
+	Condition modifiers.Condition
+}
+
+// AttackAttempted is emitted when the attacker starts the attack, Stamina is the attacker's stamina left after paying the StaminaCost
+type AttackAttempted struct {
+	Attacker    string
+	Defender    string
+	Attack      string
+	Type        attack.AttackType
+	StaminaCost int
+	Stamina     int
+}
+
+// ComplexityRoll is emitted after the roll to execute the attack, the attack is executed if the dice is above the complexity
+type ComplexityRoll struct {
+	Fighter    string
+	Complexity float64
+	Dice       float64
+	Success    bool
+}
+
+// HitRoll is emitted after the roll to hit the defender, SureStrike is set when the defender couldn't avoid the attack
+type HitRoll struct {
+	Attacker   string
+	Defender   string
+	Chance     float64
+	Dice       float64
+	Success    bool
+	SureStrike bool
+}
+
+// StrikeCancelled is emitted when the strike can't land, because the defender holds the clinch
+type StrikeCancelled struct {
+	Attacker string
+	Defender string
+	Attack   string
+}
+
+// DodgeRoll is emitted after the defender's roll to evade the attack in the Dodge stance
+type DodgeRoll struct {
+	Defender string
+	Chance   float64
+	Dice     float64
+	Dodged   bool
+}
+
+// BlockRoll is emitted after the defender's roll to block the attack
+type BlockRoll struct {
+	Defender string
+	Chance   float64
+	Dice     float64
+	Blocked  bool
+}
+
+// CounterRoll is emitted after the defender's roll to strike back in the Counter stance
+type CounterRoll struct {
+	Defender string
+	Chance   float64
+	Dice     float64
+	Success  bool
+}
+
+// SpecialRoll is emitted after the roll for the attack special
+type SpecialRoll struct {
+	Special modifiers.Condition
+	Chance  float64
+	Dice    float64
+	Success bool
+}
+
+// SpecialApplied is emitted when the special puts the defender into the condition, Stacks is the intensity of the condition
+type SpecialApplied struct {
+	Attacker  string
+	Defender  string
+	Condition modifiers.Condition
+	Duration  int
+	Stacks    int `json:",omitempty"`
+}
+
+// ConditionResisted is emitted when the special lands, but the defender can't get the condition.
+// Immunity is the number of turns the defender is still immune to it, or 0 if the defender already has the condition and it doesn't stack.
+type ConditionResisted struct {
+	Attacker  string
+	Defender  string
+	Condition modifiers.Condition
+	Immunity  int
+}
+
+// DamageDealt is emitted when the fighter loses health, either from the Attack, the Counter to it or from the Condition.
+// Multipliers lists the conditions that multiplied the attack damage.
+type DamageDealt struct {
+	Attacker    string
+	Target      string
+	Amount      int
+	Health      int
+	MaxHealth   int
+	Attack      string
+	Condition   modifiers.Condition
+	Multipliers []modifiers.Condition
+	Counter     bool `json:",omitempty"`
+}
+
+// KnockOut is emitted when the fighter can't continue the fight, Condition is set if the fighter was knocked out by the condition
+type KnockOut struct {
+	Side      int
+	Fighter   string
+	Condition modifiers.Condition
+}
+
+// StanceChosen is emitted when the fighter takes the stance against the opponent's next attack.
+// Condition is set if the condition made the fighter skip the turn and drop to the block instead of choosing the stance.
+type StanceChosen struct {
+	Fighter   string
+	Stance    attack.Stance
+	Condition modifiers.Condition `json:",omitempty"`
+}
+
+// TurnEnded is emitted after all effects of the turn are applied
+type TurnEnded struct {
+	Turn int
+}
+
+// RoundStarted is emitted before the first turn of the round
+type RoundStarted struct {
+	Round  int
+	Rounds int
+}
+
+// RoundEnded is emitted after the last turn of the round without the knockout.
+// Scores are the judges' scores of the round for both sides, Recovered is the health and StaminaRecovered is the stamina
+// both sides got back before the next round.
+type RoundEnded struct {
+	Round            int
+	Judges           []string
+	Scores           [][2]int
+	Recovered        [2]int
+	StaminaRecovered [2]int
+}
+
+// Decision is emitted when the last round ends without the knockout, Cards are the judges' total scores for both sides.
+// Side is the side of the winner, or -1 for the draw.
+type Decision struct {
+	Type    DecisionType
+	Side    int
+	Fighter string
+	Judges  []string
+	Cards   [][2]int
+}
+
+// Forfeit is emitted when the fighter throws in the towel instead of attacking, the opponent wins the match
+type Forfeit struct {
+	Side    int
+	Fighter string
+}
+
+func (e TurnStarted) Kind() Kind       { return KindTurnStarted }
+func (e ConditionExpired) Kind() Kind  { return KindConditionExpired }
+func (e TurnSkipped) Kind() Kind       { return KindTurnSkipped }
+func (e AttackAttempted) Kind() Kind   { return KindAttackAttempted }
+func (e ComplexityRoll) Kind() Kind    { return KindComplexityRoll }
+func (e HitRoll) Kind() Kind           { return KindHitRoll }
+func (e StrikeCancelled) Kind() Kind   { return KindStrikeCancelled }
+func (e DodgeRoll) Kind() Kind         { return KindDodgeRoll }
+func (e BlockRoll) Kind() Kind         { return KindBlockRoll }
+func (e CounterRoll) Kind() Kind       { return KindCounterRoll }
+func (e SpecialRoll) Kind() Kind       { return KindSpecialRoll }
+func (e SpecialApplied) Kind() Kind    { return KindSpecialApplied }
+func (e ConditionResisted) Kind() Kind { return KindConditionResisted }
+func (e DamageDealt) Kind() Kind       { return KindDamageDealt }
+func (e KnockOut) Kind() Kind          { return KindKnockOut }
+func (e StanceChosen) Kind() Kind      { return KindStanceChosen }
+func (e TurnEnded) Kind() Kind         { return KindTurnEnded }
+func (e RoundStarted) Kind() Kind      { return KindRoundStarted }
+func (e RoundEnded) Kind() Kind        { return KindRoundEnded }
+func (e Decision) Kind() Kind          { return KindDecision }
+func (e Forfeit) Kind() Kind           { return KindForfeit }
@@ -0,0 +1,64 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Record is the serializable form of the event
+type Record struct {
+	Kind Kind            `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+func decode[T Event](data json.RawMessage) (Event, error) {
+	var e T
+	err := json.Unmarshal(data, &e)
+	return e, err
+}
+
+var decoders = map[Kind]func(json.RawMessage) (Event, error){
+	KindTurnStarted:       decode[TurnStarted],
+	KindConditionExpired:  decode[ConditionExpired],
+	KindTurnSkipped:       decode[TurnSkipped],
+	KindAttackAttempted:   decode[AttackAttempted],
+	KindComplexityRoll:    decode[ComplexityRoll],
+	KindHitRoll:           decode[HitRoll],
+	KindStrikeCancelled:   decode[StrikeCancelled],
+	KindDodgeRoll:         decode[DodgeRoll],
+	KindBlockRoll:         decode[BlockRoll],
+	KindCounterRoll:       decode[CounterRoll],
+	KindSpecialRoll:       decode[SpecialRoll],
+	KindSpecialApplied:    decode[SpecialApplied],
+	KindConditionResisted: decode[ConditionResisted],
+	KindDamageDealt:       decode[DamageDealt],
+	KindKnockOut:          decode[KnockOut],
+	KindForfeit:           decode[Forfeit],
+	KindStanceChosen:      decode[StanceChosen],
+	KindTurnEnded:         decode[TurnEnded],
+	KindRoundStarted:      decode[RoundStarted],
+	KindRoundEnded:        decode[RoundEnded],
+	KindDecision:          decode[Decision],
+}
+
+// NewRecord converts the event to its serializable form
+func NewRecord(e Event) (Record, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return Record{}, fmt.Errorf("error encoding %s event: %v", e.Kind(), err)
+	}
+	return Record{Kind: e.Kind(), Data: data}, nil
+}
+
+// Event converts the record back to the event
+func (r Record) Event() (Event, error) {
+	decoder, ok := decoders[r.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown event kind %q", r.Kind)
+	}
+	e, err := decoder(r.Data)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s event: %v", r.Kind, err)
+	}
+	return e, nil
+}
@@ -0,0 +1,84 @@
+package event
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+func TestRecordRoundTrip(t *testing.T) {
+	events := []Event{
+		TurnStarted{Turn: 3, Side: 1, Attacker: "Jerry", Defender: "Tom"},
+		ConditionExpired{Fighter: "Tom", Condition: modifiers.Prone},
+		TurnSkipped{Fighter: "Tom", Condition: modifiers.Paralysed},
+		AttackAttempted{Attacker: "Tom", Defender: "Jerry", Attack: "Hook", Type: attack.Punch, StaminaCost: 10, Stamina: 90},
+		ComplexityRoll{Fighter: "Tom", Complexity: 12.5, Dice: 40.1, Success: true},
+		HitRoll{Attacker: "Tom", Defender: "Jerry", Chance: 70, Dice: 12.3, Success: true, SureStrike: true},
+		StrikeCancelled{Attacker: "Tom", Defender: "Jerry", Attack: "Hook"},
+		DodgeRoll{Defender: "Jerry", Chance: 20, Dice: 10, Dodged: true},
+		BlockRoll{Defender: "Jerry", Chance: 30, Dice: 50},
+		CounterRoll{Defender: "Jerry", Chance: 15, Dice: 5, Success: true},
+		SpecialRoll{Special: modifiers.Bleeding, Chance: 25, Dice: 3, Success: true},
+		SpecialApplied{Attacker: "Tom", Defender: "Jerry", Condition: modifiers.Bleeding, Duration: 3, Stacks: 2},
+		ConditionResisted{Attacker: "Tom", Defender: "Jerry", Condition: modifiers.Prone, Immunity: 2},
+		DamageDealt{Attacker: "Tom", Target: "Jerry", Amount: 12, Health: 88, MaxHealth: 100, Attack: "Hook",
+			Multipliers: []modifiers.Condition{modifiers.Prone, modifiers.CriticalHit}},
+		KnockOut{Side: 1, Fighter: "Jerry", Condition: modifiers.Bleeding},
+		StanceChosen{Fighter: "Jerry", Stance: attack.Counter},
+		TurnEnded{Turn: 3},
+		RoundStarted{Round: 2, Rounds: 3},
+		RoundEnded{Round: 2, Judges: []string{"Judge A"}, Scores: [][2]int{{10, 9}}, Recovered: [2]int{5, 7}, StaminaRecovered: [2]int{20, 30}},
+		Decision{Type: Split, Side: 0, Fighter: "Tom", Judges: []string{"Judge A", "Judge B"}, Cards: [][2]int{{29, 28}, {28, 29}}},
+		Forfeit{Side: 1, Fighter: "Jerry"},
+	}
+	if len(events) != len(decoders) {
+		t.Fatalf("test has %d events, the decoders know %d kinds", len(events), len(decoders))
+	}
+	for _, e := range events {
+		t.Run(string(e.Kind()), func(t *testing.T) {
+			record, err := NewRecord(e)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The records are saved as JSON in the replays
+			data, err := json.Marshal(record)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var loaded Record
+			if err := json.Unmarshal(data, &loaded); err != nil {
+				t.Fatal(err)
+			}
+			got, err := loaded.Event()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, e) {
+				t.Errorf("got %#v, want %#v", got, e)
+			}
+		})
+	}
+}
+
+func TestRecordEventErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  Record
+		wantErr string
+	}{
+		{"unknown kind", Record{Kind: "Taunt", Data: json.RawMessage(`{}`)}, `unknown event kind "Taunt"`},
+		{"invalid data", Record{Kind: KindTurnStarted, Data: json.RawMessage(`{"Turn":"three"}`)}, "error decoding TurnStarted event"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.record.Event()
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("got error %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package fighter
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// baseHealth is the health of the fighter of the average weight, every kg above or below it adds or takes one
+	baseHealth int = 250
+	// maxBalance is the furthest the balance can lean to either of its traits
+	maxBalance float64 = 2
+	// bodyCostBonus is the body bonus from which the height, weight or age costs a point, it's about the bonus of one balance step
+	bodyCostBonus float64 = 0.5
+)
+
+// Build is what the fighter is made of: the body and the balances between the opposite traits, from -2 to 2.
+// Negative balance leans to the first trait, like agility for the agility/strength balance.
+type Build struct {
+	Height               int     `json:"height" yaml:"height"`
+	Weight               int     `json:"weight" yaml:"weight"`
+	Age                  int     `json:"age" yaml:"age"`
+	AgilityStrength      float64 `json:"agility_strength" yaml:"agility_strength"`
+	BurstEndurance       float64 `json:"burst_endurance" yaml:"burst_endurance"`
+	DefenseOffense       float64 `json:"defense_offense" yaml:"defense_offense"`
+	SpeedControl         float64 `json:"speed_control" yaml:"speed_control"`
+	IntelligenceInstinct float64 `json:"intelligence_instinct" yaml:"intelligence_instinct"`
+}
+
+// DefaultBuild returns the build of the average and balanced fighter, the new fighters start from it
+func DefaultBuild() Build {
+	return Build{Height: (minHeight + maxHeight) / 2, Weight: (minWeight + maxWeight) / 2, Age: (minAge + maxAge) / 2}
+}
+
+// checkRange returns the error if the number isn't between min and max
+func checkRange(name string, value, min, max int) error {
+	if value < min || value > max {
+		return fmt.Errorf("%s should be a number between %d and %d", name, min, max)
+	}
+	return nil
+}
+
+// checkBalance returns the error if the balance isn't one of the five steps from -2 to 2 offered to the player
+func checkBalance(name string, value float64) error {
+	if value != math.Trunc(value) || value < -maxBalance || value > maxBalance {
+		return fmt.Errorf("%s balance should be one of -2, -1, 0, 1, 2, got %g", name, value)
+	}
+	return nil
+}
+
+// Stats are the fighter's stats derived from the build
+type Stats struct {
+	MaxHealth          int
+	MaxStamina         int
+	DamageBonus        float64
+	ComplexityBonus    float64
+	HitChanceBonus     float64
+	BlockChanceBonus   float64
+	SpecialChanceBonus float64
+}
+
+// minus returns the difference between the stats and the other stats
+func (s Stats) minus(other Stats) Stats {
+	return Stats{
+		MaxHealth:          s.MaxHealth - other.MaxHealth,
+		MaxStamina:         s.MaxStamina - other.MaxStamina,
+		DamageBonus:        s.DamageBonus - other.DamageBonus,
+		ComplexityBonus:    s.ComplexityBonus - other.ComplexityBonus,
+		HitChanceBonus:     s.HitChanceBonus - other.HitChanceBonus,
+		BlockChanceBonus:   s.BlockChanceBonus - other.BlockChanceBonus,
+		SpecialChanceBonus: s.SpecialChanceBonus - other.SpecialChanceBonus,
+	}
+}
+
+// Validate checks the build the same way as the fighter creation prompts: the body is in the allowed ranges
+// and the balances are the whole steps from -2 to 2
+func (b Build) Validate() error {
+	if err := checkRange("height", b.Height, minHeight, maxHeight); err != nil {
+		return err
+	}
+	if err := checkRange("weight", b.Weight, minWeight, maxWeight); err != nil {
+		return err
+	}
+	if err := checkRange("age", b.Age, minAge, maxAge); err != nil {
+		return err
+	}
+	for _, balance := range b.balances() {
+		if err := checkBalance(balance.name, balance.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// namedBalance is the balance of the build with the names of its traits, like agility/strength
+type namedBalance struct {
+	name  string
+	value float64
+}
+
+// balances returns the balances of the build with their names
+func (b Build) balances() []namedBalance {
+	return []namedBalance{
+		{"agility/strength", b.AgilityStrength},
+		{"burst/endurance", b.BurstEndurance},
+		{"defense/offense", b.DefenseOffense},
+		{"speed/control", b.SpeedControl},
+		{"intelligence/instinct", b.IntelligenceInstinct},
+	}
+}
+
+// Cost returns the points the build costs. Every balance step away from the balanced costs one point more
+// than the previous one, so the first step costs 1 point and the second one 2 more. The height, weight and age
+// cost 1 point each when they are closer to their limit than to the average.
+func (b Build) Cost() int {
+	cost := 0
+	for _, balance := range b.balances() {
+		steps := int(math.Abs(math.Round(balance.value)))
+		cost += steps * (steps + 1) / 2
+	}
+	age, weight, height := b.bodyBonuses()
+	for _, bonus := range []float64{age, weight, height} {
+		if math.Abs(bonus) > bodyCostBonus {
+			cost++
+		}
+	}
+	return cost
+}
+
+// CheckBudget returns the error if the build costs more than the budget, the budget of 0 or less has no limit
+func (b Build) CheckBudget(budget int) error {
+	if budget > 0 && b.Cost() > budget {
+		return fmt.Errorf("build costs %d points, more than the budget of %d points", b.Cost(), budget)
+	}
+	return nil
+}
+
+// buildDocument is the build file, the build keys are at the top level next to the name
+type buildDocument struct {
+	Name  string `yaml:"name"`
+	Build `yaml:",inline"`
+}
+
+// LoadBuildFile reads the fighter's name and build from the YAML or JSON file, the missing values are taken from DefaultBuild
+func LoadBuildFile(filename string) (string, Build, error) {
+	buildFile, err := os.Open(filename)
+	if err != nil {
+		return "", Build{}, fmt.Errorf("error opening build file: %s", err)
+	}
+	defer buildFile.Close()
+
+	// JSON is valid YAML, so both are read the same way
+	document := buildDocument{Build: DefaultBuild()}
+	decoder := yaml.NewDecoder(buildFile)
+	decoder.KnownFields(true)
+	err = decoder.Decode(&document)
+	if err != nil {
+		return "", Build{}, fmt.Errorf("error decoding build file %s: %v", filename, err)
+	}
+	if document.Name == "" {
+		return "", Build{}, fmt.Errorf("build file %s doesn't have the fighter name", filename)
+	}
+	if err := document.Build.Validate(); err != nil {
+		return "", Build{}, fmt.Errorf("invalid build file %s: %v", filename, err)
+	}
+	return document.Name, document.Build, nil
+}
+
+// bodyBonuses returns the bonuses of the age, weight and height, i.e. the values normalized across [-1;+1] scale
+func (b Build) bodyBonuses() (age, weight, height float64) {
+	age = float64(b.Age-minAge)/(maxAge-minAge)*2 - 1
+	weight = float64(b.Weight-minWeight)/(maxWeight-minWeight)*2 - 1
+	height = float64(b.Height-minHeight)/(maxHeight-minHeight)*2 - 1
+	return age, weight, height
+}
+
+// Stats derives the fighter's stats from the build, the bonuses are from -48% to 48%
+func (b Build) Stats() Stats {
+	ageBonus, weightBonus, heightBonus := b.bodyBonuses()
+
+	return Stats{
+		MaxHealth:          baseHealth + (b.Weight - (maxWeight+minWeight)/2),
+		MaxStamina:         maxStamina(b.BurstEndurance, b.Weight),
+		DamageBonus:        (4*b.AgilityStrength + 4*b.DefenseOffense + 4*weightBonus - 4*ageBonus) * 4,
+		ComplexityBonus:    (4*b.SpeedControl + 4*b.IntelligenceInstinct + 4*heightBonus - 4*ageBonus) * 4,
+		HitChanceBonus:     (-4*b.BurstEndurance - 4*b.AgilityStrength - 4*weightBonus + 4*heightBonus) * 4,
+		BlockChanceBonus:   (4*b.IntelligenceInstinct - 4*b.DefenseOffense) * 6,
+		SpecialChanceBonus: (4*b.SpeedControl + 4*b.BurstEndurance) * 6,
+	}
+}
+
+// NewFighter creates the fighter with the build and the stats derived from it, ready for a new fight
+func NewFighter(name string, build Build) *Fighter {
+	stats := build.Stats()
+	f := &Fighter{
+		Name:                        name,
+		Height:                      build.Height,
+		Weight:                      build.Weight,
+		Age:                         build.Age,
+		AgilityStrengthBalance:      build.AgilityStrength,
+		BurstEnduranceBalance:       build.BurstEndurance,
+		DefenseOffenseBalance:       build.DefenseOffense,
+		SpeedControlBalance:         build.SpeedControl,
+		IntelligenceInstinctBalance: build.IntelligenceInstinct,
+		DamageBonus:                 stats.DamageBonus,
+		ComplexityBonus:             stats.ComplexityBonus,
+		HitChanceBonus:              stats.HitChanceBonus,
+		BlockChanceBonus:            stats.BlockChanceBonus,
+		SpecialChanceBonus:          stats.SpecialChanceBonus,
+		MaxHealth:                   stats.MaxHealth,
+		MaxStamina:                  stats.MaxStamina,
+	}
+	f.Reset()
+	return f
+}
+
+// Build returns the fighter's build
+func (f *Fighter) Build() Build {
+	return Build{
+		Height:               f.Height,
+		Weight:               f.Weight,
+		Age:                  f.Age,
+		AgilityStrength:      f.AgilityStrengthBalance,
+		BurstEndurance:       f.BurstEnduranceBalance,
+		DefenseOffense:       f.DefenseOffenseBalance,
+		SpeedControl:         f.SpeedControlBalance,
+		IntelligenceInstinct: f.IntelligenceInstinctBalance,
+	}
+}
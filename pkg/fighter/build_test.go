@@ -0,0 +1,140 @@
+package fighter
+
+import (
+	"math"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildStats(t *testing.T) {
+	tests := []struct {
+		name  string
+		build Build
+		want  Stats
+	}{
+		{"default", DefaultBuild(), Stats{MaxHealth: 250, MaxStamina: 100}},
+		{
+			"smallest body",
+			Build{Height: minHeight, Weight: minWeight, Age: maxAge},
+			Stats{MaxHealth: 220, MaxStamina: 115, DamageBonus: -32, ComplexityBonus: -32},
+		},
+		{
+			// The integer division made the age, weight and height bonuses -1 for this body, all bonuses were 0
+			"body between the limits",
+			Build{Height: 170, Weight: 105, Age: minAge},
+			Stats{MaxHealth: 265, MaxStamina: 93, DamageBonus: 24, ComplexityBonus: 8, HitChanceBonus: -16},
+		},
+		{
+			"uneven balances",
+			Build{Height: 180, Weight: 90, Age: 39, AgilityStrength: 2, BurstEndurance: -2, DefenseOffense: 1, SpeedControl: -1, IntelligenceInstinct: 2},
+			Stats{MaxHealth: 250, MaxStamina: 70, DamageBonus: 48, ComplexityBonus: 16, BlockChanceBonus: 24, SpecialChanceBonus: -72},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.build.Stats()
+			if got.MaxHealth != test.want.MaxHealth || got.MaxStamina != test.want.MaxStamina {
+				t.Errorf("health %d and stamina %d, want %d and %d", got.MaxHealth, got.MaxStamina, test.want.MaxHealth, test.want.MaxStamina)
+			}
+			bonuses := []struct {
+				name      string
+				got, want float64
+			}{
+				{"damage", got.DamageBonus, test.want.DamageBonus},
+				{"complexity", got.ComplexityBonus, test.want.ComplexityBonus},
+				{"hit chance", got.HitChanceBonus, test.want.HitChanceBonus},
+				{"block chance", got.BlockChanceBonus, test.want.BlockChanceBonus},
+				{"special chance", got.SpecialChanceBonus, test.want.SpecialChanceBonus},
+			}
+			for _, bonus := range bonuses {
+				if math.Abs(bonus.got-bonus.want) > 1e-9 {
+					t.Errorf("%s bonus is %.2f, want %.2f", bonus.name, bonus.got, bonus.want)
+				}
+			}
+
+			f := NewFighter("Tom", test.build)
+			if fighterStats(f) != got || f.Build() != test.build {
+				t.Errorf("new fighter has %+v with %+v, want the build's stats %+v", fighterStats(f), f.Build(), got)
+			}
+		})
+	}
+}
+
+func TestGenerateFighterStats(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		f := GenerateFighter(rng)
+		if err := f.Build().Validate(); err != nil {
+			t.Fatalf("generated invalid build %+v: %s", f.Build(), err)
+		}
+		if fighterStats(f) != f.Build().Stats() {
+			t.Fatalf("generated fighter has %+v, want the stats of its build %+v", fighterStats(f), f.Build().Stats())
+		}
+	}
+}
+
+func TestLoadFighterRecomputesStats(t *testing.T) {
+	f, err := LoadFighterFromFile(filepath.Join("testdata", "fighter_v2_tampered.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fighterStats(f) != fixtureBuild.Stats() {
+		t.Errorf("loaded the stats %+v from the file, want the stats of the build %+v", fighterStats(f), fixtureBuild.Stats())
+	}
+}
+
+func TestBuildCost(t *testing.T) {
+	tests := []struct {
+		name  string
+		build func(b *Build)
+		want  int
+	}{
+		{"default", func(b *Build) {}, 0},
+		{"one step", func(b *Build) { b.AgilityStrength = -1 }, 1},
+		{"two steps", func(b *Build) { b.DefenseOffense = 2 }, 3},
+		{"one step on every balance", func(b *Build) {
+			b.AgilityStrength, b.BurstEndurance, b.DefenseOffense, b.SpeedControl, b.IntelligenceInstinct = 1, -1, 1, -1, 1
+		}, 5},
+		{"every balance at the limit", func(b *Build) {
+			b.AgilityStrength, b.BurstEndurance, b.DefenseOffense, b.SpeedControl, b.IntelligenceInstinct = 2, -2, 2, -2, 2
+		}, 15},
+		{"body halfway to the limits", func(b *Build) { b.Height, b.Weight, b.Age = 190, 75, 49 }, 0},
+		{"weight past halfway", func(b *Build) { b.Weight = 106 }, 1},
+		{"body at the limits", func(b *Build) { b.Height, b.Weight, b.Age = minHeight, maxWeight, minAge }, 3},
+		{"body and balances", func(b *Build) { b.Height, b.AgilityStrength = maxHeight, 2 }, 4},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			build := DefaultBuild()
+			test.build(&build)
+			if got := build.Cost(); got != test.want {
+				t.Errorf("%+v costs %d points, want %d", build, got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheckBudget(t *testing.T) {
+	build := DefaultBuild()
+	build.AgilityStrength, build.DefenseOffense = 2, -1
+	tests := []struct {
+		budget int
+		want   string
+	}{
+		{0, ""},
+		{-1, ""},
+		{5, ""},
+		{4, ""},
+		{3, "build costs 4 points, more than the budget of 3 points"},
+	}
+	for _, test := range tests {
+		err := build.CheckBudget(test.budget)
+		switch {
+		case test.want == "" && err != nil:
+			t.Errorf("budget %d: unexpected error %s", test.budget, err)
+		case test.want != "" && (err == nil || err.Error() != test.want):
+			t.Errorf("budget %d: got error %v, want %q", test.budget, err, test.want)
+		}
+	}
+}
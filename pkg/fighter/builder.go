@@ -0,0 +1,226 @@
+package fighter
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/fatih/color"
+
+	"github.com/zerobugdebug/cogfight/pkg/ui"
+)
+
+const (
+	doneOption string = "Done"
+)
+
+// bodyField is the part of the fighter's body the player can change in the builder
+type bodyField struct {
+	name     string
+	unit     string
+	min, max int
+	help     string
+	value    func(b *Build) *int
+}
+
+var bodyFields = []bodyField{
+	{"Height", "cm", minHeight, maxHeight, "Taller fighters will have bonus to hit chance, while lower height will give make it easier to execute complex attacks", func(b *Build) *int { return &b.Height }},
+	{"Weight", "kg", minWeight, maxWeight, "Heavier fighters tend to have increased damage and health, while lighter fighters will have better hit chance", func(b *Build) *int { return &b.Weight }},
+	{"Age", "years", minAge, maxAge, "Older fighters tend to have better chance to execute complex attacks, while younger fighters will have better damage", func(b *Build) *int { return &b.Age }},
+}
+
+// balanceAxis is the balance between two opposite traits the player can change in the builder
+type balanceAxis struct {
+	first, second string
+	help          string
+	value         func(b *Build) *float64
+}
+
+var balanceAxes = []balanceAxis{
+	{"Agility", "Strength", "High Agility will allow fighter to get better chances to hit and block, while high Strength will increase damage", func(b *Build) *float64 { return &b.AgilityStrength }},
+	{"Burst", "Endurance", "Fighters with high Burst will be able to execute complex attacks with better chance of special attacks, but high Endurance will increase hit chance. Endurance also gives more stamina and faster recovery, so the fighter keeps up the pace when the fight goes long, while heavy fighters tire faster", func(b *Build) *float64 { return &b.BurstEndurance }},
+	{"Defense", "Offense", "Increasing Defense will improve your chances of blocking attacks, while increasing Offense will increase damage and chance to hit", func(b *Build) *float64 { return &b.DefenseOffense }},
+	{"Speed", "Control", "Increasing Speed will improve your chances of performing special attack, while high Control will allow to execute complex attacks with increased damage", func(b *Build) *float64 { return &b.SpeedControl }},
+	{"Intelligence", "Instinct", "Increasing Intelligence will help with executing more complex attacks, while increasing Instinct will improve your chances of successfully blocking attacks and execute specials", func(b *Build) *float64 { return &b.IntelligenceInstinct }},
+}
+
+// label returns the answer of the balance value, like "High Agility, Low Strength"
+func (a balanceAxis) label(value float64) string {
+	switch balanceIndex(value) {
+	case 0:
+		return fmt.Sprintf("Very high %s, Very low %s", a.first, a.second)
+	case 1:
+		return fmt.Sprintf("High %s, Low %s", a.first, a.second)
+	case 3:
+		return fmt.Sprintf("Low %s, High %s", a.first, a.second)
+	case 4:
+		return fmt.Sprintf("Very low %s, Very high %s", a.first, a.second)
+	default:
+		return "Balanced"
+	}
+}
+
+// BuildFighter lets the player build the fighter one part at a time, starting from the defaults fighter if it's set,
+// and shows the derived stats after every change. If the budget is positive, the build can't cost more points than
+// the budget, see Build.Cost. It returns nil if the player cancels.
+func BuildFighter(defaults *Fighter, budget int) *Fighter {
+	name := ""
+	build := DefaultBuild()
+	if defaults != nil {
+		name = defaults.Name
+		build = defaults.Build()
+	}
+	err := survey.AskOne(&survey.Input{Message: "Enter fighter name:", Default: name}, &name, survey.WithValidator(survey.Required))
+	if err != nil {
+		fmt.Println("Error during the fighter creation:", err)
+		return nil
+	}
+
+	previous := build
+	for {
+		printBuild(name, build, previous, budget)
+		previous = build
+
+		options := []string{}
+		for _, field := range bodyFields {
+			options = append(options, fmt.Sprintf("%s: %d %s", field.name, *field.value(&build), field.unit))
+		}
+		for _, axis := range balanceAxes {
+			options = append(options, fmt.Sprintf("%s/%s: %s", axis.first, axis.second, axis.label(*axis.value(&build))))
+		}
+		options = append(options, doneOption)
+		selected := 0
+		err := survey.AskOne(&survey.Select{
+			Message:  "Choose what to change:",
+			Options:  options,
+			PageSize: len(options),
+			Default:  len(options) - 1,
+		}, &selected)
+		if err != nil {
+			fmt.Println("Error during the fighter creation:", err)
+			return nil
+		}
+
+		switch {
+		case selected < len(bodyFields):
+			askBodyField(&build, bodyFields[selected], budget)
+		case selected < len(bodyFields)+len(balanceAxes):
+			askBalance(&build, balanceAxes[selected-len(bodyFields)], budget)
+		default:
+			// The edited fighters could be built before the budget was set
+			if err := build.CheckBudget(budget); err != nil {
+				fmt.Println(color.HiRedString("The %s, lower some balances", err))
+				continue
+			}
+			fighter := NewFighter(name, build)
+			fmt.Printf("\n%s has been created!\n", fighter.Name)
+			return fighter
+		}
+	}
+}
+
+// askBodyField asks the player for the new value of the body field.
+// The value is kept if the player cancels or the answer is over the budget.
+func askBodyField(build *Build, field bodyField, budget int) {
+	answer := ""
+	err := survey.AskOne(&survey.Input{
+		Message: fmt.Sprintf("Enter fighter %s (%d-%d %s):", field.name, field.min, field.max, field.unit),
+		Help:    field.help + ". The values closer to the limits than to the average cost 1 point",
+		Default: strconv.Itoa(*field.value(build)),
+	}, &answer, survey.WithValidator(validateNumber(field.min, field.max)))
+	if err != nil {
+		return
+	}
+	candidate := *build
+	*field.value(&candidate), _ = strconv.Atoi(answer)
+	if err := candidate.CheckBudget(budget); err != nil {
+		fmt.Println(color.HiRedString("Can't choose %s %s %s, the %s", field.name, answer, field.unit, err))
+		return
+	}
+	*build = candidate
+}
+
+// askBalance asks the player for the new value of the balance, showing the stats and the cost of every answer.
+// The value is kept if the player cancels or the answer is over the budget.
+func askBalance(build *Build, axis balanceAxis, budget int) {
+	options := []string{}
+	candidates := []Build{}
+	for value := -maxBalance; value <= maxBalance; value++ {
+		candidate := *build
+		*axis.value(&candidate) = value
+		options = append(options, axis.label(value))
+		candidates = append(candidates, candidate)
+	}
+	selected := 0
+	err := survey.AskOne(&survey.Select{
+		Message:  fmt.Sprintf("Choose fighter %s/%s balance:", axis.first, axis.second),
+		Options:  options,
+		Help:     fmt.Sprintf("This parameter determines the balance between %s and %s. %s", axis.first, axis.second, axis.help),
+		Default:  balanceIndex(*axis.value(build)),
+		PageSize: len(options),
+		Description: func(value string, index int) string {
+			return statsSummary(candidates[index], budget)
+		},
+	}, &selected)
+	if err != nil {
+		return
+	}
+	if err := candidates[selected].CheckBudget(budget); err != nil {
+		fmt.Println(color.HiRedString("Can't choose %s, the %s", options[selected], err))
+		return
+	}
+	*build = candidates[selected]
+}
+
+// statsSummary returns the short line of the build's bonuses and cost for the answer descriptions
+func statsSummary(build Build, budget int) string {
+	stats := build.Stats()
+	summary := fmt.Sprintf("[DMG: %+6.2f, CMP: %+6.2f, HIT: %+6.2f, BLK: %+6.2f, SPC: %+6.2f] %s",
+		stats.DamageBonus, stats.ComplexityBonus, stats.HitChanceBonus, stats.BlockChanceBonus, stats.SpecialChanceBonus, pointsText(build, budget))
+	if build.CheckBudget(budget) != nil {
+		return color.HiRedString(summary + " over budget")
+	}
+	return summary
+}
+
+// pointsText returns the points the build costs out of the budget, or just the cost without the budget
+func pointsText(build Build, budget int) string {
+	if budget > 0 {
+		return fmt.Sprintf("%d/%d points", build.Cost(), budget)
+	}
+	return fmt.Sprintf("%d points", build.Cost())
+}
+
+// printBuild prints the build with the derived stats the same way as DisplayFighters, the stats that changed
+// since the previous build are highlighted
+func printBuild(name string, build, previous Build, budget int) {
+	blue := color.New(color.BgBlue).SprintFunc()
+	hiredfg := color.New(color.FgHiRed).SprintFunc()
+	higreenfg := color.New(color.FgHiGreen).SprintFunc()
+
+	stats := build.Stats()
+	changes := stats.minus(previous.Stats())
+
+	text := []string{}
+	text = append(text, "Name: "+name)
+	for _, field := range bodyFields {
+		text = append(text, fmt.Sprintf("%s: %d", field.name, *field.value(&build)))
+	}
+	text = append(text, "")
+	text = append(text, balanceLines(build)...)
+	text = append(text, "")
+	text = append(text, bonusLines(stats, changes)...)
+	text = append(text, "")
+	text = append(text, fmt.Sprintf("%s %s", "Health: ", ui.ColorModifiedValue(float64(stats.MaxHealth), float64(changes.MaxHealth), "%.0f", higreenfg, hiredfg)))
+	text = append(text, fmt.Sprintf("%s %s", "Stamina:", ui.ColorModifiedValue(float64(stats.MaxStamina), float64(changes.MaxStamina), "%.0f", higreenfg, hiredfg)))
+	points := pointsText(build, budget)
+	if build.CheckBudget(budget) != nil {
+		points = hiredfg(points + " over budget")
+	}
+	text = append(text, "Cost: "+points)
+
+	fmt.Println()
+	for _, line := range ui.BoxPrint(20, blue, text) {
+		fmt.Println(line)
+	}
+}
@@ -1,20 +1,23 @@
 package fighter
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
 	"github.com/fatih/color"
-	"github.com/gorilla/websocket"
 
 	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/llm"
 	"github.com/zerobugdebug/cogfight/pkg/modifiers"
 	"github.com/zerobugdebug/cogfight/pkg/ui"
 )
@@ -27,6 +30,10 @@ const (
 	maxWeight      = 120
 	minAge         = 18
 	maxAge         = 60
+	// balanceScaleRange is the range of the balance scales in the fighter's box
+	balanceScaleRange float64 = 3.00
+	// scaleSize is the width of the scales in the fighter's box
+	scaleSize = 12
 )
 
 var fighterNames = []string{
@@ -99,78 +106,19 @@ type Fighter struct {
 	HitChanceBonus              float64
 	BlockChanceBonus            float64
 	SpecialChanceBonus          float64
-	TempDamageBonus             float64
-	TempComplexityBonus         float64
-	TempHitChanceBonus          float64
-	TempBlockChanceBonus        float64
-	TempSpecialChanceBonus      float64
 	CustomAttacks               []*attack.Attack
 	Conditions                  map[modifiers.Condition]int
-	CurrentHealth               int
-	MaxHealth                   int
-}
-
-type proxyRequestData struct {
-	PromptTemplate string `json:"prompt_template"`
-	// PromptData1    string        `json:"prompt_data1"`
-	// PromptData2    string        `json:"prompt_data2,omitempty"`
-	// PromptData3    string        `json:"prompt_data3,omitempty"`
-	Messages     []ChatMessage `json:"messages"`
-	ResponseType string        `json:"response_type"`
-}
-
-type ChatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-
-type proxyResponseData struct {
-	Int    int    `json:"int,omitempty"`
-	String string `json:"string,omitempty"`
-	Full   string `json:"full,omitempty"`
-}
-
-func getDescriptions(valueType string) []string {
-	switch valueType {
-	case "weight":
-		return []string{"Very lightweight", "Lightweight", "Middleweight", "Heavyweight", "Very heavyweight"}
-	case "age":
-		return []string{"Very young", "Young", "Average", "Old", "Very old"}
-	case "height":
-		return []string{"Very short", "Short", "Average", "Tall", "Very tall"}
-	case "complexity":
-		return []string{"Basic", "Moderate", "Advanced", "Expert", "Master"}
-	default:
-		return []string{"Very low", "Low", "Average", "High", "Very high"}
-	}
-}
-
-// Function with valueType
-func getPercentileWithType(value, min, max float64, valueType string) string {
-	return getPercentileDesc(value, min, max, getDescriptions(valueType))
-}
-
-// Function without valueType (default behavior)
-func getPercentileDefault(value, min, max float64) string {
-	return getPercentileDesc(value, min, max, getDescriptions(""))
-}
-
-func getPercentileDesc(value, min, max float64, descriptions []string) string {
-	if value <= min {
-		return descriptions[0]
-	}
-	if value >= max {
-		return descriptions[len(descriptions)-1]
-	}
-
-	rangePerGroup := (max - min) / float64(len(descriptions))
-	index := int((value - min) / rangePerGroup)
-
-	if index >= len(descriptions) {
-		index = len(descriptions) - 1
-	}
-
-	return descriptions[index]
+	// ConditionStacks holds the intensity of the conditions, conditions without stacks have the intensity 1
+	ConditionStacks map[modifiers.Condition]int `json:",omitempty"`
+	// Immunities holds the number of the opponent's turns the fighter can't get the expired conditions again
+	Immunities map[modifiers.Condition]int `json:",omitempty"`
+	// Stance is the defensive action the fighter takes against the opponent's next attack
+	Stance        attack.Stance
+	CurrentHealth int
+	MaxHealth     int
+	// CurrentStamina is spent on the attacks and recovers every turn, low stamina makes the attacks harder to execute and to land
+	CurrentStamina int
+	MaxStamina     int
 }
 
 func (f *Fighter) String() string {
@@ -178,44 +126,65 @@ func (f *Fighter) String() string {
 	var scaleRange float64 = 4
 
 	text = fmt.Sprintf("Name: %s\n", f.Name)
-	text += fmt.Sprintf("Height: %s\n", getPercentileWithType(float64(f.Height), minHeight, maxHeight, "height"))
-	text += fmt.Sprintf("Weight: %s\n", getPercentileWithType(float64(f.Weight), minWeight, maxWeight, "weight"))
-	text += fmt.Sprintf("Age: %s\n", getPercentileWithType(float64(f.Age), minAge, maxAge, "age"))
-	text += fmt.Sprintf("%s agility (%.f), ", getPercentileDefault(scaleRange-f.AgilityStrengthBalance, 0, scaleRange*2), scaleRange-f.AgilityStrengthBalance)
-	text += fmt.Sprintf("%s strength (%.f), ", getPercentileDefault(scaleRange+f.AgilityStrengthBalance, 0, scaleRange*2), scaleRange+f.AgilityStrengthBalance)
-	text += fmt.Sprintf("%s burst (%.f), ", getPercentileDefault(scaleRange-f.BurstEnduranceBalance, 0, scaleRange*2), scaleRange-f.BurstEnduranceBalance)
-	text += fmt.Sprintf("%s endurance (%.f), ", getPercentileDefault(scaleRange+f.BurstEnduranceBalance, 0, scaleRange*2), scaleRange+f.BurstEnduranceBalance)
-	text += fmt.Sprintf("%s defense (%.f), ", getPercentileDefault(scaleRange-f.DefenseOffenseBalance, 0, scaleRange*2), scaleRange-f.DefenseOffenseBalance)
-	text += fmt.Sprintf("%s offense (%.f), ", getPercentileDefault(scaleRange+f.DefenseOffenseBalance, 0, scaleRange*2), scaleRange+f.DefenseOffenseBalance)
-	text += fmt.Sprintf("%s speed (%.f), ", getPercentileDefault(scaleRange-f.SpeedControlBalance, 0, scaleRange*2), scaleRange-f.SpeedControlBalance)
-	text += fmt.Sprintf("%s control (%.f), ", getPercentileDefault(scaleRange+f.SpeedControlBalance, 0, scaleRange*2), scaleRange+f.SpeedControlBalance)
-	text += fmt.Sprintf("%s intelligence (%.f), ", getPercentileDefault(scaleRange-f.IntelligenceInstinctBalance, 0, scaleRange*2), scaleRange-f.IntelligenceInstinctBalance)
-	text += fmt.Sprintf("%s instinct (%.f)\n", getPercentileDefault(scaleRange+f.IntelligenceInstinctBalance, 0, scaleRange*2), scaleRange+f.IntelligenceInstinctBalance)
-
-	/* 	text = fmt.Sprintf("Name: %s, Height: %d cm, Weight %d kg, Age %d years\n", f.Name, f.Height, f.Weight, f.Age)
-	   	text += fmt.Sprintf("Agility: %.f, Strength: %.f, ", scaleRange-f.AgilityStrengthBalance, scaleRange+f.AgilityStrengthBalance)
-	   	text += fmt.Sprintf("Burst: %.f, Endurance: %.f, ", scaleRange-f.BurstEnduranceBalance, scaleRange+f.BurstEnduranceBalance)
-	   	text += fmt.Sprintf("Defense: %.f, Offense: %.f, ", scaleRange-f.DefenseOffenseBalance, scaleRange+f.DefenseOffenseBalance)
-	   	text += fmt.Sprintf("Speed: %.f, Control: %.f, ", scaleRange-f.SpeedControlBalance, scaleRange+f.SpeedControlBalance)
-	   	text += fmt.Sprintf("Intelligence: %.f, Instinct: %.f\n", scaleRange-f.IntelligenceInstinctBalance, scaleRange+f.IntelligenceInstinctBalance)
-	*/
+	text += fmt.Sprintf("Height: %s\n", ui.PercentileWithType(float64(f.Height), minHeight, maxHeight, "height"))
+	text += fmt.Sprintf("Weight: %s\n", ui.PercentileWithType(float64(f.Weight), minWeight, maxWeight, "weight"))
+	text += fmt.Sprintf("Age: %s\n", ui.PercentileWithType(float64(f.Age), minAge, maxAge, "age"))
+	text += fmt.Sprintf("%s agility (%.f), ", ui.PercentileDefault(scaleRange-f.AgilityStrengthBalance, 0, scaleRange*2), scaleRange-f.AgilityStrengthBalance)
+	text += fmt.Sprintf("%s strength (%.f), ", ui.PercentileDefault(scaleRange+f.AgilityStrengthBalance, 0, scaleRange*2), scaleRange+f.AgilityStrengthBalance)
+	text += fmt.Sprintf("%s burst (%.f), ", ui.PercentileDefault(scaleRange-f.BurstEnduranceBalance, 0, scaleRange*2), scaleRange-f.BurstEnduranceBalance)
+	text += fmt.Sprintf("%s endurance (%.f), ", ui.PercentileDefault(scaleRange+f.BurstEnduranceBalance, 0, scaleRange*2), scaleRange+f.BurstEnduranceBalance)
+	text += fmt.Sprintf("%s defense (%.f), ", ui.PercentileDefault(scaleRange-f.DefenseOffenseBalance, 0, scaleRange*2), scaleRange-f.DefenseOffenseBalance)
+	text += fmt.Sprintf("%s offense (%.f), ", ui.PercentileDefault(scaleRange+f.DefenseOffenseBalance, 0, scaleRange*2), scaleRange+f.DefenseOffenseBalance)
+	text += fmt.Sprintf("%s speed (%.f), ", ui.PercentileDefault(scaleRange-f.SpeedControlBalance, 0, scaleRange*2), scaleRange-f.SpeedControlBalance)
+	text += fmt.Sprintf("%s control (%.f), ", ui.PercentileDefault(scaleRange+f.SpeedControlBalance, 0, scaleRange*2), scaleRange+f.SpeedControlBalance)
+	text += fmt.Sprintf("%s intelligence (%.f), ", ui.PercentileDefault(scaleRange-f.IntelligenceInstinctBalance, 0, scaleRange*2), scaleRange-f.IntelligenceInstinctBalance)
+	text += fmt.Sprintf("%s instinct (%.f)\n", ui.PercentileDefault(scaleRange+f.IntelligenceInstinctBalance, 0, scaleRange*2), scaleRange+f.IntelligenceInstinctBalance)
 
 	conditionsText := []string{}
-	for condition := range f.Conditions {
+	for _, condition := range modifiers.SortedConditions(f.Conditions) {
 		conditionsText = append(conditionsText, fmt.Sprintf("%s", condition.String()))
 	}
 	text += fmt.Sprintf("Conditions: %s", strings.Join(conditionsText, ", ")) + "\n"
-	//fmt.Printf("text: %v\n", text)
 	return text
 }
 
-func (f *Fighter) SelectAttack(opponent *Fighter) *attack.Attack {
+// Archetype returns the name of the fighter's most pronounced trait, or "Balanced" if all traits are balanced
+func (f *Fighter) Archetype() string {
+	traits := []struct {
+		balance   float64
+		low, high string
+	}{
+		{f.AgilityStrengthBalance, "Agility", "Strength"},
+		{f.BurstEnduranceBalance, "Burst", "Endurance"},
+		{f.DefenseOffenseBalance, "Defense", "Offense"},
+		{f.SpeedControlBalance, "Speed", "Control"},
+		{f.IntelligenceInstinctBalance, "Intelligence", "Instinct"},
+	}
+
+	archetype := "Balanced"
+	strongest := 0.0
+	for _, trait := range traits {
+		if math.Abs(trait.balance) > strongest {
+			strongest = math.Abs(trait.balance)
+			if trait.balance < 0 {
+				archetype = trait.low
+			} else {
+				archetype = trait.high
+			}
+		}
+	}
+	return archetype
+}
+
+// SelectAttack asks the player for the attack from the catalog against the opponent, custom attacks are designed with the LLM client if it's set.
+// If the prompts fail, it falls back to the first attack of the catalog the fighter can use.
+// It returns nil if the player forfeits the fight by interrupting the attack type prompt.
+func (f *Fighter) SelectAttack(opponent *Fighter, defaultAttacks *attack.Attacks, client llm.Client) *attack.Attack {
 	attackType := attack.AttackType(0)
 	attackTypePromptOptions := []string{}
 
 	for attackType.String() != "" {
 		attackTypePromptOptions = append(attackTypePromptOptions, attackType.String())
-		//+": "+attackType.Hint())
 		attackType++
 	}
 
@@ -226,32 +195,28 @@ func (f *Fighter) SelectAttack(opponent *Fighter) *attack.Attack {
 		Help:     "Punch: Closed fist attacks, high damage, low complexity, high hit chance, high block chance\nSlap: Open fist or back hand attacks, very low damage, low complexity, high hit chance, high block chance\nKick: Leg attacks, high damage, average complexity, high hit chance, high block chance\nKnee strike: Attacks with a knee, very high damage, average complexity, high hit chance, average block chance\nElbow strike: Attacks with an elbow, very high damage, low complexity, high hit chance, high block chance\nThrow: Attacks to knockdown opponent, average damage, average complexity, average hit chance, average block chance, can knockdown opponent\nLock: Grapple attacks to block joint movement, very low damage, high complexity, low hit chance, low block chance, decrease opponent's hit and block chances\nChoke: Grapple attacks to block airways, low damage, high complexity, low hit chance, low block chance, decrease opponent's damage and increase complexity\nCustom: Custom free text attack",
 	}
 
-	defaultAttacks := attack.NewDefaultAttacks()
-	attacks := []*attack.Attack{}
-	for _, a := range defaultAttacks.ByName {
-		attacks = append(attacks, a)
-	}
-
-	hiredfg := color.New(color.FgHiRed).SprintFunc()
-	higreenfg := color.New(color.FgHiGreen).SprintFunc()
-
 	for {
-		//fmt.Printf("Attack %d from %d\n", i+1, numAttacks)
 		attackTypeSelected := 0
 		// Ask for attack type
 		err := survey.AskOne(attackTypePrompt, &attackTypeSelected, survey.WithValidator(survey.Required))
+		if errors.Is(err, terminal.InterruptErr) {
+			return nil
+		}
 		if err != nil {
 			fmt.Println("Error during the attack type selection:", err)
 			break
 		}
 		attackType = attack.AttackType(attackTypeSelected)
-		//fmt.Println("defaultAttacks.GetAttacksByType(attackType)=", defaultAttacks.GetAttacksByType(attackType))
 
 		// If non-custom type, ask for specific attack
 		if attackType != attack.Custom {
 			attackNamePromptOptions := []string{}
+			attackNameHelp := []string{}
 			for _, value := range defaultAttacks.GetAttacksByType(attackType) {
 				attackNamePromptOptions = append(attackNamePromptOptions, value.Name)
+				if value.Description != "" {
+					attackNameHelp = append(attackNameHelp, value.Name+": "+value.Description)
+				}
 			}
 			attackNamePromptOptions = append(attackNamePromptOptions, "<-Back")
 
@@ -259,15 +224,10 @@ func (f *Fighter) SelectAttack(opponent *Fighter) *attack.Attack {
 				Message:  "Select an attack:",
 				Options:  attackNamePromptOptions,
 				PageSize: len(attackNamePromptOptions),
+				Help:     strings.Join(attackNameHelp, "\n"),
 				Description: func(value string, index int) string {
 					if value != "<-Back" {
-						selectedAttack := defaultAttacks.GetAttackByName(value)
-						damage := ui.ColorModifiedValue(attack.Clamp(selectedAttack.Damage*(1+f.DamageBonus/100+f.TempDamageBonus/100), attack.MinDamage, attack.MaxDamage), f.TempDamageBonus, "%.2f", higreenfg, hiredfg)
-						complexity := ui.ColorModifiedValue(attack.Clamp(selectedAttack.Complexity+f.ComplexityBonus+f.TempComplexityBonus, attack.MinComplexity, attack.MaxComplexity), f.TempComplexityBonus, "%.2f", hiredfg, higreenfg)
-						hitChance := ui.ColorModifiedValue(attack.Clamp(selectedAttack.HitChance+f.HitChanceBonus+f.TempHitChanceBonus, attack.MinHitChance, attack.MaxHitChance), f.TempHitChanceBonus, "%.2f", higreenfg, hiredfg)
-						blockChance := ui.ColorModifiedValue(attack.Clamp(selectedAttack.BlockChance+opponent.BlockChanceBonus+opponent.TempBlockChanceBonus, attack.MinBlockChance, attack.MaxBlockChance), opponent.TempBlockChanceBonus, "%.2f", hiredfg, higreenfg)
-						specialChance := ui.ColorModifiedValue(attack.Clamp(selectedAttack.SpecialChance+f.SpecialChanceBonus+f.TempSpecialChanceBonus, attack.MinSpecialChance, attack.MaxSpecialChance), f.TempSpecialChanceBonus, "%.2f", higreenfg, hiredfg)
-						return fmt.Sprintf("[DMG: %s, CMP: %s, HIT: %s, BLK: %s, SPC: %s]", damage, complexity, hitChance, blockChance, specialChance)
+						return f.describeAttack(opponent, defaultAttacks.GetAttackByName(value))
 					}
 					return ""
 				},
@@ -279,407 +239,492 @@ func (f *Fighter) SelectAttack(opponent *Fighter) *attack.Attack {
 				break
 			}
 			if attackName != "<-Back" {
-				// Add attack to attacks array
-				return defaultAttacks.GetAttackByName(attackName)
+				selectedAttack := defaultAttacks.GetAttackByName(attackName)
+				if reason := f.unusableReason(opponent, selectedAttack); reason != "" {
+					fmt.Println(color.HiRedString("%s can only be used when %s.", selectedAttack.Name, reason))
+					continue
+				}
+				return selectedAttack
 			}
 			continue
 		} else {
+			customAttack := f.selectCustomAttack(opponent, client, defaultAttacks)
+			if customAttack != nil {
+				return customAttack
+			}
 			continue
 		}
 	}
 
-	/* 		// Ask for the description of the custom attack
-		customAttackName := ""
-		customAttackPrompt := &survey.Input{
-			Message: "Enter the description for the custom attack:",
-		}
-		err = survey.AskOne(customAttackPrompt, &customAttackName)
-		fmt.Println("customAttackName=" + customAttackName)
-		if err != nil {
-			fmt.Println("Error during the custom attack creation:", err)
-			break
+	return f.firstUsableAttack(opponent, defaultAttacks)
+}
+
+// firstUsableAttack returns the first attack of the catalog, in the order of the attack types, the fighter can use against the opponent,
+// or nil if there is none
+func (f *Fighter) firstUsableAttack(opponent *Fighter, attacks *attack.Attacks) *attack.Attack {
+	for _, typeAttacks := range attacks.ByType {
+		for _, a := range typeAttacks {
+			if f.CanUse(opponent, a) {
+				return a
+			}
 		}
+	}
+	return nil
+}
 
-		// Validate the attack name and get the attack parameters using OpenAI API
-		validAttack, err := validateAttackName(customAttackName)
-		if err != nil {
-			fmt.Printf("Error validating attack name: %s\n", err)
-			continue
+// describeAttack returns the attack stats against the opponent, colored by the current bonuses and penalties
+func (f *Fighter) describeAttack(opponent *Fighter, originalAttack *attack.Attack) string {
+	hiredfg := color.New(color.FgHiRed).SprintFunc()
+	higreenfg := color.New(color.FgHiGreen).SprintFunc()
+
+	selectedAttack := f.ModifiedAttack(opponent, originalAttack)
+	ledger := f.Ledger(opponent)
+	damage := ui.ColorModifiedValue(selectedAttack.Damage, ledger[modifiers.Damage], "%.2f", higreenfg, hiredfg)
+	staminaPenalty := f.StaminaPenalty()
+	complexity := ui.ColorModifiedValue(selectedAttack.Complexity, ledger[modifiers.Complexity]+staminaPenalty, "%.2f", hiredfg, higreenfg)
+	hitChance := ui.ColorModifiedValue(selectedAttack.HitChance, ledger[modifiers.HitChance]-staminaPenalty, "%.2f", higreenfg, hiredfg)
+	blockChance := ui.ColorModifiedValue(selectedAttack.BlockChance, opponent.Ledger(f)[modifiers.BlockChance]+opponent.Stance.BlockBonus(), "%.2f", hiredfg, higreenfg)
+	specialChance := ui.ColorModifiedValue(selectedAttack.SpecialChance, 0, "%.2f", higreenfg, hiredfg)
+	if len(selectedAttack.Specials) > 0 {
+		specialChances := []string{}
+		for _, special := range selectedAttack.Specials {
+			specialChances = append(specialChances, special.Condition.String()+" "+ui.ColorModifiedValue(special.Chance, 0, "%.2f", higreenfg, hiredfg))
 		}
+		specialChance = strings.Join(specialChances, "/")
+	}
+	description := fmt.Sprintf("[DMG: %s, CMP: %s, HIT: %s, BLK: %s, SPC: %s, STA: %d]", damage, complexity, hitChance, blockChance, specialChance, originalAttack.StaminaCost())
+	if !f.CanUse(opponent, originalAttack) {
+		description += hiredfg(" " + f.unusableLabel(opponent, originalAttack))
+	}
+	return description
+}
 
-		if validAttack {
-			fmt.Println("Valid attack")
-			customAttackType, err := getOpenAIResponse("COG_TYPE_ATTACK_PROMPT", customAttackName)
-			if err != nil {
-				fmt.Printf("Error getting data for COG_COMPLEXITY_ATTACK_PROMPT: %s\n", err)
-				continue
+// selectCustomAttack asks the player to pick one of the fighter's custom attacks or to describe a new one, returns nil to go back
+func (f *Fighter) selectCustomAttack(opponent *Fighter, client llm.Client, defaultAttacks *attack.Attacks) *attack.Attack {
+	customAttackPromptOptions := []string{}
+	for _, customAttack := range f.CustomAttacks {
+		customAttackPromptOptions = append(customAttackPromptOptions, customAttack.Name)
+	}
+	customAttackPromptOptions = append(customAttackPromptOptions, "<-New", "<-Back")
+
+	customAttackPrompt := &survey.Select{
+		Message:  "Select a custom attack:",
+		Options:  customAttackPromptOptions,
+		PageSize: len(customAttackPromptOptions),
+		Description: func(value string, index int) string {
+			if index < len(f.CustomAttacks) {
+				return fmt.Sprintf("%s %s", f.CustomAttacks[index].Type.String(), f.describeAttack(opponent, f.CustomAttacks[index]))
 			}
-			fmt.Println("customAttackType=", customAttackType.(string))
+			return ""
+		},
+	}
+	customAttackIndex := 0
+	err := survey.AskOne(customAttackPrompt, &customAttackIndex)
+	if err != nil {
+		fmt.Println("Error during the custom attack selection:", err)
+		return nil
+	}
+	switch {
+	case customAttackIndex < len(f.CustomAttacks):
+		return f.CustomAttacks[customAttackIndex]
+	case customAttackPromptOptions[customAttackIndex] == "<-New":
+		return f.newCustomAttack(client, defaultAttacks)
+	default:
+		return nil
+	}
+}
 
-			complexityValue, err := getOpenAIResponse("COG_COMPLEXITY_ATTACK_PROMPT", customAttackName)
-			if err != nil {
-				fmt.Printf("Error getting data for COG_COMPLEXITY_ATTACK_PROMPT: %s\n", err)
-				continue
-			}
-			fmt.Println("complexityValue=", complexityValue.(int))
-		}
+// newCustomAttack asks the player to describe the attack, has it rated with the LLM client and adds it to the fighter's custom attacks
+func (f *Fighter) newCustomAttack(client llm.Client, defaultAttacks *attack.Attacks) *attack.Attack {
+	if client == nil {
+		fmt.Println(color.HiRedString("Custom attacks require the LLM backend, check the LLM config."))
+		return nil
+	}
+
+	description := ""
+	descriptionPrompt := &survey.Input{
+		Message: "Describe the attack:",
+		Help:    "A single martial arts move in free text, e.g. \"jumping spinning hook kick to the head\"",
+	}
+	err := survey.AskOne(descriptionPrompt, &description, survey.WithValidator(survey.Required))
+	if err != nil {
+		fmt.Println("Error during the custom attack creation:", err)
+		return nil
 	}
-	fmt.Printf("attacks_outside= %v\n", attacks)
 
-	// Create the fighter attacks
-	fighter.Attacks = append(fighter.Attacks, attacks...)
-	fmt.Printf("fighter.Attacks= %v\n", fighter.Attacks)
-	*/
+	// Ctrl-C while waiting for the judges cancels only the new attack
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	fmt.Println("The judges are reviewing the attack...")
+	customAttack, err := attack.DesignCustomAttack(ctx, client, defaultAttacks, description)
+	if errors.Is(err, attack.ErrInvalidAttack) {
+		fmt.Println(color.HiRedString("The judges rejected the attack: %v", err))
+		return nil
+	}
+	if err != nil {
+		fmt.Println("Error getting the custom attack:", err)
+		return nil
+	}
 
-	return defaultAttacks.ByName["Jab"]
+	// Keep the names unique, so the recorded fights can be played back
+	name := customAttack.Name
+	for i := 2; defaultAttacks.GetAttackByName(customAttack.Name) != nil || f.customAttackByName(customAttack.Name) != nil; i++ {
+		customAttack.Name = fmt.Sprintf("%s %d", name, i)
+	}
 
+	fmt.Printf("%s (%s): DMG: %.0f, CMP: %.0f, HIT: %.0f, BLK: %.0f, CRT: %.0f, SPC: %.0f\n", color.CyanString(customAttack.Name), customAttack.Type.String(),
+		customAttack.Damage, customAttack.Complexity, customAttack.HitChance, customAttack.BlockChance, customAttack.CriticalChance, customAttack.SpecialChance)
+	accept := false
+	err = survey.AskOne(&survey.Confirm{Message: "Add the attack to " + f.Name + "'s custom attacks?", Default: true}, &accept)
+	if err != nil || !accept {
+		return nil
+	}
+	f.CustomAttacks = append(f.CustomAttacks, customAttack)
+	return customAttack
 }
 
-func (f *Fighter) AddCondition(opponent *Fighter, condition modifiers.Condition) {
-	//Add temp bonuses/penalties due to opponent condition
-	for modifier, value := range modifiers.DefaultConditionAttributes[condition] {
-		switch modifier {
-		case modifiers.BlockChance:
-			{
-				opponent.TempBlockChanceBonus += float64(value)
-			}
-		case modifiers.HitChance:
-			{
-				opponent.TempHitChanceBonus += float64(value)
-			}
-		case modifiers.Damage:
-			{
-				opponent.TempDamageBonus += float64(value)
-			}
-		case modifiers.Complexity:
-			{
-				opponent.TempComplexityBonus += float64(value)
-			}
-		case modifiers.OpponentHitChance:
-			{
-				f.TempHitChanceBonus += float64(value)
-			}
-		case modifiers.OpponentBlockChance:
-			{
-				f.TempBlockChanceBonus += float64(value)
-			}
+// customAttackByName returns the fighter's custom attack with the name, or nil if there is none
+func (f *Fighter) customAttackByName(name string) *attack.Attack {
+	for _, customAttack := range f.CustomAttacks {
+		if customAttack.Name == name {
+			return customAttack
 		}
 	}
+	return nil
 }
 
-func (f *Fighter) RemoveCondition(opponent *Fighter, condition modifiers.Condition) {
-	//Remove temp bonuses/penalties due to opponent condition
-	for modifier, value := range modifiers.DefaultConditionAttributes[condition] {
-		switch modifier {
-		case modifiers.BlockChance:
-			{
-				opponent.TempBlockChanceBonus -= float64(value)
-			}
-		case modifiers.HitChance:
-			{
-				opponent.TempHitChanceBonus -= float64(value)
-			}
-		case modifiers.Damage:
-			{
-				opponent.TempDamageBonus -= float64(value)
-			}
-		case modifiers.Complexity:
-			{
-				opponent.TempComplexityBonus -= float64(value)
-			}
-		case modifiers.OpponentHitChance:
-			{
-				f.TempHitChanceBonus -= float64(value)
-			}
-		case modifiers.OpponentBlockChance:
-			{
-				f.TempBlockChanceBonus -= float64(value)
+// inflict puts the opponent into the condition following its stacking policy and immunity, and returns the resulting event
+func (f *Fighter) inflict(opponent *Fighter, condition modifiers.Condition) event.Event {
+	definition := modifiers.Lookup(condition)
+	if turns := opponent.Immunities[condition]; turns > 0 {
+		return event.ConditionResisted{Attacker: f.Name, Defender: opponent.Name, Condition: condition, Immunity: turns}
+	}
+	if opponent.ConditionStacks == nil {
+		opponent.ConditionStacks = make(map[modifiers.Condition]int)
+	}
+
+	if _, conditionExist := opponent.Conditions[condition]; !conditionExist {
+		opponent.Conditions[condition] = definition.Duration
+		opponent.ConditionStacks[condition] = 1
+	} else {
+		switch definition.Stacking {
+		case modifiers.Ignore:
+			return event.ConditionResisted{Attacker: f.Name, Defender: opponent.Name, Condition: condition}
+		case modifiers.Extend:
+			opponent.Conditions[condition] += definition.Duration
+		case modifiers.Stack:
+			if stacks := opponent.ConditionStack(condition); stacks < definition.MaxStacks {
+				opponent.ConditionStacks[condition] = stacks + 1
 			}
+			opponent.Conditions[condition] = definition.Duration
+		default:
+			opponent.Conditions[condition] = definition.Duration
 		}
 	}
+	return event.SpecialApplied{Attacker: f.Name, Defender: opponent.Name, Condition: condition, Duration: opponent.Conditions[condition], Stacks: opponent.ConditionStack(condition)}
 }
 
-func (f *Fighter) ApplyAttack(opponent *Fighter, originalAttack *attack.Attack) string {
-	modifiedAttack := &attack.Attack{
-		Name:          originalAttack.Name,
-		Type:          originalAttack.Type,
-		Damage:        originalAttack.Damage * (1 + (f.DamageBonus+f.TempDamageBonus)/100),
-		Complexity:    originalAttack.Complexity + f.ComplexityBonus + f.TempComplexityBonus,
-		HitChance:     originalAttack.HitChance + f.HitChanceBonus + f.TempHitChanceBonus,
-		BlockChance:   originalAttack.BlockChance + opponent.BlockChanceBonus + opponent.TempBlockChanceBonus,
-		SpecialChance: originalAttack.SpecialChance + f.SpecialChanceBonus + f.TempSpecialChanceBonus,
+// ExpireCondition removes the fighter's condition with all its stacks and starts the immunity to it
+func (f *Fighter) ExpireCondition(condition modifiers.Condition) {
+	delete(f.Conditions, condition)
+	delete(f.ConditionStacks, condition)
+	if immunity := modifiers.Lookup(condition).Immunity; immunity > 0 {
+		if f.Immunities == nil {
+			f.Immunities = make(map[modifiers.Condition]int)
+		}
+		f.Immunities[condition] = immunity
 	}
+}
 
-	sureStrike := 0
-	result := ""
-	//skipTurn := 0
+// TickImmunities counts down the fighter's immunities at the start of its turn, removing the expired ones
+func (f *Fighter) TickImmunities() {
+	for condition := range f.Immunities {
+		f.Immunities[condition]--
+		if f.Immunities[condition] < 1 {
+			delete(f.Immunities, condition)
+		}
+	}
+}
 
-	//Calculate bonuses/penalties from opponent conditions
-	for condition := range opponent.Conditions {
-		for modifier, value := range modifiers.DefaultConditionAttributes[condition] {
-			switch modifier {
-			case modifiers.SureStrike:
-				{
-					sureStrike = value
-					result += opponent.Name + " is currently " + condition.String() + ". "
-				}
-			}
+// ConditionStack returns the intensity of the fighter's condition, or 0 if the fighter doesn't have it
+func (f *Fighter) ConditionStack(condition modifiers.Condition) int {
+	if _, conditionExist := f.Conditions[condition]; !conditionExist {
+		return 0
+	}
+	if stacks := f.ConditionStacks[condition]; stacks > 1 {
+		return stacks
+	}
+	return 1
+}
+
+// Affectable reports whether the condition applied to the fighter would make it worse,
+// i.e. the fighter isn't immune and either doesn't have the condition or it can stack further
+func (f *Fighter) Affectable(condition modifiers.Condition) bool {
+	if f.Immunities[condition] > 0 {
+		return false
+	}
+	if _, conditionExist := f.Conditions[condition]; !conditionExist {
+		return true
+	}
+	definition := modifiers.Lookup(condition)
+	return definition.Stacking == modifiers.Stack && f.ConditionStack(condition) < definition.MaxStacks
+}
+
+// Ledger returns the stat modifiers of the fighter from its own conditions and from the opponent's conditions
+func (f *Fighter) Ledger(opponent *Fighter) modifiers.Ledger {
+	ledger := modifiers.Ledger{}
+	for condition := range f.Conditions {
+		ledger.Add(condition, f.ConditionStack(condition), false)
+	}
+	if opponent != nil {
+		for condition := range opponent.Conditions {
+			ledger.Add(condition, opponent.ConditionStack(condition), true)
 		}
 	}
+	return ledger
+}
 
-	//Calculate bonuses/penalties from attacker conditions
-	/* 	for condition := range f.Conditions {
-		for modifier, value := range modifiers.DefaultConditionAttributes[condition] {
-			switch modifier {
-			case modifiers.SkipTurn:
-				{
-					skipTurn = value
-				}
+// ModifiedAttack returns the attack adjusted by the fighter and opponent bonuses and clamped to the allowed ranges
+func (f *Fighter) ModifiedAttack(opponent *Fighter, originalAttack *attack.Attack) *attack.Attack {
+	ledger := f.Ledger(opponent)
+	modifiedAttack := *originalAttack
+	modifiedAttack.Damage = attack.Clamp(originalAttack.Damage*(1+(f.DamageBonus+ledger[modifiers.Damage])/100), attack.MinDamage, attack.MaxDamage)
+	staminaPenalty := f.StaminaPenalty()
+	modifiedAttack.Complexity = attack.Clamp(originalAttack.Complexity+f.ComplexityBonus+ledger[modifiers.Complexity]+staminaPenalty, attack.MinComplexity, attack.MaxComplexity)
+	modifiedAttack.HitChance = attack.Clamp(originalAttack.HitChance+f.HitChanceBonus+ledger[modifiers.HitChance]-staminaPenalty+f.clinchBonus(originalAttack), attack.MinHitChance, attack.MaxHitChance)
+	modifiedAttack.BlockChance = attack.Clamp(originalAttack.BlockChance+opponent.BlockChanceBonus+opponent.Ledger(f)[modifiers.BlockChance]+opponent.Stance.BlockBonus(), attack.MinBlockChance, attack.MaxBlockChance)
+	modifiedAttack.SpecialChance = attack.Clamp(originalAttack.SpecialChance+f.SpecialChanceBonus, attack.MinSpecialChance, attack.MaxSpecialChance)
+	modifiedAttack.Specials = nil
+	for _, special := range originalAttack.Specials {
+		special.Chance = attack.Clamp(special.Chance+f.SpecialChanceBonus, attack.MinSpecialChance, attack.MaxSpecialChance)
+		modifiedAttack.Specials = append(modifiedAttack.Specials, special)
+	}
+	return &modifiedAttack
+}
+
+// CanUse reports whether the fighter can use the attack against the opponent, ground-only attacks require the opponent to be Prone,
+// strikes can't land while the opponent holds the clinch and the fighter needs the stamina to pay for the attack
+func (f *Fighter) CanUse(opponent *Fighter, a *attack.Attack) bool {
+	return f.unusableReason(opponent, a) == ""
+}
+
+// unusableReason returns what the attack requires from the fighter or the opponent, or an empty string if the attack can be used
+func (f *Fighter) unusableReason(opponent *Fighter, a *attack.Attack) string {
+	if a.HasTag(attack.TagGroundOnly) {
+		if _, prone := opponent.Conditions[modifiers.Prone]; !prone {
+			return opponent.Name + " is on the ground"
+		}
+	}
+	if opponent.cancelsStrike(a) {
+		return opponent.Name + " is not in the clinch"
+	}
+	if cost := a.StaminaCost(); cost > f.CurrentStamina {
+		return fmt.Sprintf("%s has %d stamina", f.Name, cost)
+	}
+	return ""
+}
+
+// unusableLabel returns the short requirement of the attack the fighter can't use against the opponent
+func (f *Fighter) unusableLabel(opponent *Fighter, a *attack.Attack) string {
+	if _, prone := opponent.Conditions[modifiers.Prone]; a.HasTag(attack.TagGroundOnly) && !prone {
+		return "ground only"
+	}
+	if opponent.cancelsStrike(a) {
+		return "can't land in clinch"
+	}
+	return "not enough stamina"
+}
+
+// ExpectedDamage estimates the average damage of the attack against the opponent, taking into account all chances and current conditions
+func (f *Fighter) ExpectedDamage(opponent *Fighter, originalAttack *attack.Attack) float64 {
+	if opponent.cancelsStrike(originalAttack) {
+		return 0
+	}
+	modifiedAttack := f.ModifiedAttack(opponent, originalAttack)
+	executeChance := (100 - modifiedAttack.Complexity) / 100
+	landChance := f.landChance(opponent, modifiedAttack)
+	multiplier := 1.0
+	for _, condition := range modifiers.SortedConditions(opponent.Conditions) {
+		if damageMult, ok := modifiers.Lookup(condition).Effects[modifiers.DamageMult]; ok {
+			multiplier *= float64(damageMult)
+		}
+	}
+	for _, special := range modifiedAttack.SpecialChances() {
+		if opponent.Affectable(special.Condition) {
+			if damageMult, ok := modifiers.Lookup(special.Condition).Effects[modifiers.DamageMult]; ok {
+				multiplier *= 1 + special.Chance/100*float64(damageMult-1)
 			}
 		}
+	}
+	return executeChance * landChance * modifiedAttack.Damage * multiplier
+}
+
+// landChance returns the chance of the executed attack to land on the opponent, taking into account the opponent's stance
+func (f *Fighter) landChance(opponent *Fighter, modifiedAttack *attack.Attack) float64 {
+	if opponent.defenseless() {
+		return 1
+	}
+	landChance := modifiedAttack.HitChance / 100 * (100 - modifiedAttack.BlockChance) / 100
+	if opponent.Stance == attack.Dodge {
+		landChance *= (100 - opponent.DodgeChance()) / 100
+	}
+	return landChance
+}
+
+// defenseless reports whether the fighter has a condition that makes all attacks against it land
+func (f *Fighter) defenseless() bool {
+	for condition := range f.Conditions {
+		if modifiers.Lookup(condition).Effect(modifiers.SureStrike) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Clone returns a deep copy of the fighter, which can be changed without affecting the original
+func (f *Fighter) Clone() *Fighter {
+	clone := *f
+	clone.CustomAttacks = append([]*attack.Attack{}, f.CustomAttacks...)
+	clone.Conditions = make(map[modifiers.Condition]int, len(f.Conditions))
+	for condition, duration := range f.Conditions {
+		clone.Conditions[condition] = duration
+	}
+	clone.ConditionStacks = copyConditions(f.ConditionStacks)
+	clone.Immunities = copyConditions(f.Immunities)
+	return &clone
+}
+
+// Reset restores the fighter to the state before the fight: full health and stamina, no conditions and the default stance
+func (f *Fighter) Reset() {
+	f.CurrentHealth = f.MaxHealth
+	f.CurrentStamina = f.MaxStamina
+	f.Conditions = make(map[modifiers.Condition]int)
+	f.ConditionStacks = nil
+	f.Immunities = nil
+	f.Stance = attack.Block
+}
+
+func copyConditions(conditions map[modifiers.Condition]int) map[modifiers.Condition]int {
+	if conditions == nil {
+		return nil
+	}
+	copied := make(map[modifiers.Condition]int, len(conditions))
+	for condition, value := range conditions {
+		copied[condition] = value
+	}
+	return copied
+}
+
+// ApplyAttack rolls the dice for the attack against the opponent using the provided random source, applies the outcome and returns the resulting events
+func (f *Fighter) ApplyAttack(opponent *Fighter, originalAttack *attack.Attack, rng *rand.Rand) []event.Event {
+	// The attack is harder when the fighter is already tired, it gets tired after the attack is chosen
+	modifiedAttack := f.ModifiedAttack(opponent, originalAttack)
+	staminaCost := f.SpendStamina(originalAttack)
+
+	sureStrike := 0
+	events := []event.Event{event.AttackAttempted{Attacker: f.Name, Defender: opponent.Name, Attack: originalAttack.Name, Type: originalAttack.Type, StaminaCost: staminaCost, Stamina: f.CurrentStamina}}
+	if opponent.cancelsStrike(originalAttack) {
+		return append(events, event.StrikeCancelled{Attacker: f.Name, Defender: opponent.Name, Attack: originalAttack.Name})
+	}
 
-	} */
+	//Calculate bonuses/penalties from opponent conditions
+	for _, condition := range modifiers.SortedConditions(opponent.Conditions) {
+		if value, ok := modifiers.Lookup(condition).Effects[modifiers.SureStrike]; ok {
+			sureStrike = value
+		}
+	}
 
 	var attackDamage float64 = 0
 	var chance float64 = 0
 
 	// Determine the skill of the attacked
-	result += f.Name + " executing " + modifiedAttack.Name + ". "
-	attackComplexity := attack.Clamp(modifiedAttack.Complexity, attack.MinComplexity, attack.MaxComplexity)
-	fmt.Printf("Complexity: %s =>  ", color.HiMagentaString("%.1f%%", attackComplexity))
-	result += fmt.Sprintf("That is a %s level attack. ", getPercentileWithType(attackComplexity, attack.MinComplexity, attack.MaxComplexity, "complexity"))
-	chance = 100 * rand.Float64()
+	attackComplexity := modifiedAttack.Complexity
+	chance = 100 * rng.Float64()
+	events = append(events, event.ComplexityRoll{Fighter: f.Name, Complexity: attackComplexity, Dice: chance, Success: chance > attackComplexity})
 	if chance > attackComplexity {
-		fmt.Printf("%s %s\n", color.HiGreenString("Attack performed flawlessly!"), color.HiBlackString("[Dice = %.1f%%]", chance))
-		//color.HiGreen("Attack performed flawlessly!")
-		//color.HiBlack(" [Dice = %.1f%%]\n", chance)
-		//		fmt.Printf("%s (Dice = %.1f%%)\n", color.HiGreenString("Attack performed flawlessly!"), chance)
-		result += "Attack executed successfully! "
 		// Determine the attack hit chance
-		attackHitChance := attack.Clamp(modifiedAttack.HitChance, attack.MinHitChance, attack.MaxHitChance)
-		fmt.Printf("Hit Chance: %s => ", color.HiMagentaString("%.1f%%", attackHitChance))
-		result += fmt.Sprintf("Attack has a %s chance to hit. ", getPercentileDefault(attackHitChance, attack.MinHitChance, attack.MaxHitChance))
-		chance = 100 * rand.Float64()
-		if chance < attackHitChance || sureStrike == 1 {
-			fmt.Printf("%s %s\n", color.HiGreenString("Successfull hit!"), color.HiBlackString("[Dice = %.1f%%]", chance))
-			result += "Attack sucessfully hit the " + opponent.Name + ". "
-			attackBlockChance := attack.Clamp(modifiedAttack.BlockChance, attack.MinBlockChance, attack.MaxBlockChance)
-			fmt.Printf("Block Chance: %s => ", color.HiMagentaString("%.1f%%", attackBlockChance))
-			result += fmt.Sprintf("%s has a %s chance to block the attack. ", opponent.Name, getPercentileDefault(attackBlockChance, attack.MinBlockChance, attack.MaxBlockChance))
-			chance = 100 * rand.Float64()
-			if chance > attackBlockChance || sureStrike == 1 {
-				fmt.Printf("%s %s\n", color.HiGreenString("Attack not blocked!"), color.HiBlackString("[Dice = %.1f%%]", chance))
-				result += opponent.Name + " was not able to block the attack. "
-				attackDamage = attack.Clamp(modifiedAttack.Damage, attack.MinDamage, attack.MaxDamage)
-				attackSpecialChance := attack.Clamp(modifiedAttack.SpecialChance, attack.MinSpecialChance, attack.MaxSpecialChance)
-				fmt.Printf("Special: %s, %s => ", color.HiBlueString(modifiedAttack.Type.Special().ActionString()), color.HiMagentaString("%.1f%%", attackSpecialChance))
-				//fmt.Printf("Current Special: %s\n", color.HiBlueString(modifiedAttack.Type.Special().ActionString()))
-				chance = 100 * rand.Float64()
-				if chance < attackSpecialChance {
-					fmt.Printf("%s %s\n", color.HiGreenString("Success! Opponent got "+modifiedAttack.Type.Special().String()), color.HiBlackString("[Dice = %.1f%%]", chance))
-					//if modifiedAttack.Type.Special() == modifiers.CriticalHit {
-					//	attackDamage = attack.Clamp(attackDamage*float64(modifiers.DefaultConditionAttributes[modifiedAttack.Type.Special()][modifiers.DamageMult]), attack.MinDamage, attack.MaxDamage)
-					//} else {
-					_, conditionExist := opponent.Conditions[modifiedAttack.Type.Special()]
-					if !conditionExist {
-						f.AddCondition(opponent, modifiedAttack.Type.Special())
+		attackHitChance := modifiedAttack.HitChance
+		chance = 100 * rng.Float64()
+		hit := chance < attackHitChance || sureStrike == 1
+		events = append(events, event.HitRoll{Attacker: f.Name, Defender: opponent.Name, Chance: attackHitChance, Dice: chance, Success: hit, SureStrike: sureStrike == 1})
+		// The dodging defender can still evade the attack that hit, unless it's defenseless
+		if hit && opponent.Stance == attack.Dodge && sureStrike != 1 {
+			dodgeChance := opponent.DodgeChance()
+			chance = 100 * rng.Float64()
+			hit = chance >= dodgeChance
+			events = append(events, event.DodgeRoll{Defender: opponent.Name, Chance: dodgeChance, Dice: chance, Dodged: !hit})
+		}
+		landed := false
+		if hit {
+			attackBlockChance := modifiedAttack.BlockChance
+			chance = 100 * rng.Float64()
+			blocked := chance <= attackBlockChance && sureStrike != 1
+			events = append(events, event.BlockRoll{Defender: opponent.Name, Chance: attackBlockChance, Dice: chance, Blocked: blocked})
+			if !blocked {
+				landed = true
+				attackDamage = modifiedAttack.Damage
+				// Every special is rolled separately
+				for _, attackSpecial := range modifiedAttack.SpecialChances() {
+					special := attackSpecial.Condition
+					attackSpecialChance := attackSpecial.Chance
+					chance = 100 * rng.Float64()
+					events = append(events, event.SpecialRoll{Special: special, Chance: attackSpecialChance, Dice: chance, Success: chance < attackSpecialChance})
+					if chance < attackSpecialChance {
+						events = append(events, f.inflict(opponent, special))
 					}
-					opponent.Conditions[modifiedAttack.Type.Special()] = modifiers.DefaultConditionAttributes[modifiedAttack.Type.Special()][modifiers.Duration]
-					result += opponent.Name + " become " + modifiedAttack.Type.Special().String() + ". "
-					//}
-					/* 						switch modifiedAttack.Type.Special() {
-					   						case modifiers.Bleeding:
-					   							{
-					   								opponent.Conditions[modifiers.Bleeding] = modifiers.DefaultConditionAttributes[modifiers.Bleeding][modifiers.Duration]
-					   							}
-					   						}
-					*/ //attackDamage = clamp(attackDamage*2, MinDamage, MaxDamage)
-				} else {
-					fmt.Printf("%s %s\n", color.HiRedString("Special failed!"), color.HiBlackString("[Dice = %.1f%%]", chance))
 				}
-				//fmt.Printf("Damage dealt: %s%.1f%s\n", clrDamage, attackDamage, clrReset)
-				//defender.CurrentHealth -= int(attackDamage)
-				//fmt.Printf("%s%s takes %d damage! (%d/%d)%s\n", clrBadMessage, defender.Name, int(attackDamage), defender.CurrentHealth, defender.MaxHealth, clrReset)
-			} else {
-				fmt.Printf("%s %s\n", color.HiRedString("Attack blocked!"), color.HiBlackString("[Dice = %.1f%%]", chance))
-				result += opponent.Name + " blocked the attack. "
 			}
-		} else {
-			fmt.Printf("%s %s\n", color.HiRedString("Missed!"), color.HiBlackString("[Dice = %.1f%%]", chance))
-			result += f.Name + " attack missed the " + opponent.Name + ". "
 		}
-	} else {
-		fmt.Printf("%s %s\n", color.HiRedString(f.Name+" failed to execute attack!"), color.HiBlackString("[Dice = %.1f%%]", chance))
-		result += f.Name + " failed to execute attack! "
-
+		if !landed && opponent.Stance == attack.Counter {
+			events = append(events, opponent.counter(f, originalAttack, rng)...)
+		}
 	}
-	//}
 
 	//Process conditions and specials
-	//Calculate effect from opponent conditions
-	for condition := range opponent.Conditions {
-		for modifier, value := range modifiers.DefaultConditionAttributes[condition] {
-			switch modifier {
-			case modifiers.DamageMult:
-				{
-					attackDamage = attackDamage * float64(value)
-					result += f.Name + " executed " + condition.String() + ". "
-				}
+	//Calculate effect from opponent conditions, the damage multipliers are used up by the landed attack
+	multipliers := []modifiers.Condition{}
+	if attackDamage > 0 {
+		for _, condition := range modifiers.SortedConditions(opponent.Conditions) {
+			if value, ok := modifiers.Lookup(condition).Effects[modifiers.DamageMult]; ok {
+				attackDamage = attackDamage * float64(value)
+				multipliers = append(multipliers, condition)
+				opponent.ExpireCondition(condition)
 			}
 		}
 	}
 	if attackDamage > 0 {
-		//fmt.Printf("Damage dealt: %s\n", color.HiRedString("%.1f%%", attackDamage))
 		opponent.CurrentHealth -= int(attackDamage)
-		fmt.Printf("%s takes %s damage! (%s/%s)\n", color.HiBlueString(opponent.Name), color.HiRedString("%d", int(attackDamage)), color.HiBlueString("%d", opponent.CurrentHealth), color.HiBlueString("%d", opponent.MaxHealth))
-		result += fmt.Sprintf("%s takes a %s damage", opponent.Name, getPercentileDefault(attackDamage, attack.MinDamage, attack.MaxDamage))
+		events = append(events, event.DamageDealt{Attacker: f.Name, Target: opponent.Name, Amount: int(attackDamage), Health: opponent.CurrentHealth, MaxHealth: opponent.MaxHealth, Attack: originalAttack.Name, Multipliers: multipliers})
 	}
-	//fmt.Printf("result: %v\n", result)
-	return result
-
-	//Calculate effect from attacker conditions
-	/* 	for condition := range f.Conditions {
-		for modifier, value := range modifiers.DefaultConditionAttributes[condition] {
-			switch modifier {
-			case modifiers.HPPerTurn:
-				{
-					f.CurrentHealth += int(value)
-					if int(value) < 0 {
-						fmt.Printf("%s takes %d damage! (%d/%d) due to %s\n", f.Name, -int(value), f.CurrentHealth, f.MaxHealth, condition.String())
-					}
-				}
-			}
-		}
-		f.Conditions[condition] -= 1
-		if f.Conditions[condition] < 1 {
-			delete(f.Conditions, condition)
-		}
-
-	} */
-
+	return events
 }
 
-/*
-func (f *Fighter) DisplayFighter() {
-	topBorder := "╔══════════════════════════════════════════════════════════╗"
-	bottomBorder := "╚══════════════════════════════════════════════════════════╝"
-	spacer := "║                                                          ║"
-
-	fmt.Println(topBorder)
-	fmt.Println(spacer)
-	fmt.Printf("║ Name: %-50s ║\n", f.Name)
-	fmt.Printf("║ Height: %-48d ║\n", f.Height)
-	fmt.Printf("║ Weight: %-48d ║\n", f.Weight)
-	fmt.Printf("║ Age: %-51d ║\n", f.Age)
-	fmt.Printf("║ Agility Strength Balance: %-30.2f ║\n", f.AgilityStrengthBalance)
-	fmt.Printf("║ Burst Endurance Balance: %-31.2f ║\n", f.BurstEnduranceBalance)
-	fmt.Printf("║ Defense Offense Balance: %-31.2f ║\n", f.DefenseOffenseBalance)
-	fmt.Printf("║ Speed Control Balance: %-33.2f ║\n", f.SpeedControlBalance)
-	fmt.Printf("║ Intelligence Instinct Balance: %-25.2f ║\n", f.IntelligenceInstinctBalance)
-	fmt.Printf("║ Damage Bonus: %-42.2f ║\n", f.DamageBonus)
-	fmt.Printf("║ Complexity Bonus: %-38.2f ║\n", f.ComplexityBonus)
-	fmt.Printf("║ Hit Chance Bonus: %-38.2f ║\n", f.HitChanceBonus)
-	fmt.Printf("║ Block Chance Bonus: %-36.2f ║\n", f.BlockChanceBonus)
-	fmt.Printf("║ Critical Chance Bonus: %-33.2f ║\n", f.CriticalChanceBonus)
-	fmt.Printf("║ Special Chance Bonus: %-34.2f ║\n", f.SpecialChanceBonus)
-	fmt.Println(spacer)
-	fmt.Println("║ Attacks:                                                 ║")
-	for i, attack := range f.Attacks {
-		fmt.Printf("║ %d. %-53s ║\n", i+1, attack.Name)
-	}
-	fmt.Println(spacer)
-	fmt.Printf("║ Current Health: %-40d ║\n", f.CurrentHealth)
-	fmt.Printf("║ Max Health: %-44d ║\n", f.MaxHealth)
-	fmt.Println(spacer)
-	fmt.Println(bottomBorder)
-} */
-
 func DisplayFighters(f1, f2 *Fighter) {
-	//boxWidth := 50
 	numSpacesBetweenFighters := 10
 	spaceBetweenFighters := strings.Repeat(" ", numSpacesBetweenFighters)
-	var scaleRange float64 = 3.00
-	scaleSize := 12
-
-	/*
-		blue := color.New(color.FgBlue).SprintFunc()
-		red := color.New(color.FgRed).SprintFunc()
-
-		topBorder := "╔" + strings.Repeat("═", boxWidth-2) + "╗"
-		bottomBorder := "╚" + strings.Repeat("═", boxWidth-2) + "╝"
-		spacer := "║" + strings.Repeat(" ", boxWidth-2) + "║"
-		spaceBetweenFighters := strings.Repeat(" ", numSpacesBetweenFighters)
-
-		leftTopBorder := blue(topBorder)
-		rightTopBorder := red(topBorder)
-		leftBottomBorder := blue(bottomBorder)
-		rightBottomBorder := red(bottomBorder)
-		leftSpacer := blue(spacer)
-		rightSpacer := red(spacer)
-		leftEdge := blue("║")
-		rightEdge := red("║")
-	*/
-	/* 	valueFormat
-	   	leftFormat := blue("║") + " %-" + strconv.Itoa(halfBoxWidth-4) + "s: %-" + strconv.Itoa(halfBoxWidth-16) + "v " + blue("║") + SpaceBetweenFighters
-	   	rightFormat := "║ %-" + strconv.Itoa(halfBoxWidth-4) + "s: %-" + strconv.Itoa(halfBoxWidth-16) + "v ║"
-	   	headerFormat := "║ %-" + strconv.Itoa(halfBoxWidth-4) + "s: %-" + strconv.Itoa(halfBoxWidth-16) + "s ║"
-	*/
-	// ... (previous code)
-	/*
-		for i, param := range parameters {
-			fmt.Printf(leftFormat+rightFormat+"\n", param, values1[i], param, values2[i])
-		}
-	*/
+
 	blue := color.New(color.BgBlue).SprintFunc()
-	//bluefg := color.New(color.FgBlue).SprintFunc()
 	red := color.New(color.BgRed).SprintFunc()
-	//hired := color.New(color.BgHiRed).SprintFunc()
 	hiblue := color.New(color.BgHiBlue).SprintFunc()
 	hiblack := color.New(color.BgHiBlack, color.Faint).SprintFunc()
-	green := color.New(color.BgGreen).SprintFunc()
 	higreen := color.New(color.BgHiGreen).SprintFunc()
-	//magenta := color.New(color.BgMagenta).SprintFunc()
-	//himagenta := color.New(color.BgHiMagenta).SprintFunc()
 
-	hiredfg := color.New(color.FgHiRed).SprintFunc()
-	higreenfg := color.New(color.FgHiGreen).SprintFunc()
-
-	conditionsText := []string{}
 	textLeft := []string{}
-	var value float64
 
 	textLeft = append(textLeft, "Name: "+f1.Name)
 	textLeft = append(textLeft, fmt.Sprintf("Height: %d", f1.Height))
 	textLeft = append(textLeft, fmt.Sprintf("Weight: %d", f1.Weight))
 	textLeft = append(textLeft, fmt.Sprintf("Age: %d", f1.Age))
-	conditionsText = []string{}
-	for condition, duration := range f1.Conditions {
-		conditionsText = append(conditionsText, fmt.Sprintf("%s[%d]", condition.String(), duration))
-	}
-	textLeft = append(textLeft, fmt.Sprintf("Conditions: %s", strings.Join(conditionsText, ", ")))
+	textLeft = append(textLeft, "Conditions: "+f1.conditionsText())
+	textLeft = append(textLeft, "Stance: "+f1.Stance.String())
+	textLeft = append(textLeft, "Immune: "+f1.immunitiesText())
 	textLeft = append(textLeft, "")
-	textLeft = append(textLeft, fmt.Sprintf("%12s %5.2f %v %5.2f %-12s", "Agility", scaleRange-f1.AgilityStrengthBalance, ui.ScalePrint(-f1.AgilityStrengthBalance, -scaleRange, scaleRange, higreen, hiblue, scaleSize), scaleRange+f1.AgilityStrengthBalance, "Strength"))
-	textLeft = append(textLeft, fmt.Sprintf("%12s %5.2f %v %5.2f %-12s", "Burst", scaleRange-f1.BurstEnduranceBalance, ui.ScalePrint(-f1.BurstEnduranceBalance, -scaleRange, scaleRange, higreen, hiblue, scaleSize), scaleRange+f1.BurstEnduranceBalance, "Endurance"))
-	textLeft = append(textLeft, fmt.Sprintf("%12s %5.2f %v %5.2f %-12s", "Defense", scaleRange-f1.DefenseOffenseBalance, ui.ScalePrint(-f1.DefenseOffenseBalance, -scaleRange, scaleRange, higreen, hiblue, scaleSize), scaleRange+f1.DefenseOffenseBalance, "Offense"))
-	textLeft = append(textLeft, fmt.Sprintf("%12s %5.2f %v %5.2f %-12s", "Speed", scaleRange-f1.SpeedControlBalance, ui.ScalePrint(-f1.SpeedControlBalance, -scaleRange, scaleRange, higreen, hiblue, scaleSize), scaleRange+f1.SpeedControlBalance, "Control"))
-	textLeft = append(textLeft, fmt.Sprintf("%12s %5.2f %v %5.2f %-12s", "Intelligence", scaleRange-f1.IntelligenceInstinctBalance, ui.ScalePrint(-f1.IntelligenceInstinctBalance, -scaleRange, scaleRange, higreen, hiblue, scaleSize), scaleRange+f1.IntelligenceInstinctBalance, "Instinct"))
+	textLeft = append(textLeft, balanceLines(f1.Build())...)
 	textLeft = append(textLeft, "")
-	value = f1.DamageBonus + f1.TempDamageBonus
-	textLeft = append(textLeft, fmt.Sprintf("%20s %s%% %v", "Damage Bonus", ui.ColorModifiedValue(value, f1.TempDamageBonus, "%7.2f", higreenfg, hiredfg), ui.DoubleScalePrint(value, -100, 0, 100, red, green, hiblack, scaleSize)))
-	value = f1.ComplexityBonus + f1.TempComplexityBonus
-	textLeft = append(textLeft, fmt.Sprintf("%20s %s%% %v", "Complexity Bonus", ui.ColorModifiedValue(value, f1.TempComplexityBonus, "%7.2f", hiredfg, higreenfg), ui.DoubleScalePrint(-value, -100, 0, 100, red, green, hiblack, scaleSize)))
-	value = f1.HitChanceBonus + f1.TempHitChanceBonus
-	textLeft = append(textLeft, fmt.Sprintf("%20s %s%% %v", "Hit Chance Bonus", ui.ColorModifiedValue(value, f1.TempHitChanceBonus, "%7.2f", higreenfg, hiredfg), ui.DoubleScalePrint(value, -100, 0, 100, red, green, hiblack, scaleSize)))
-	value = f1.BlockChanceBonus + f1.TempBlockChanceBonus
-	textLeft = append(textLeft, fmt.Sprintf("%20s %s%% %v", "Block Chance Bonus", ui.ColorModifiedValue(value, f1.TempBlockChanceBonus, "%7.2f", higreenfg, hiredfg), ui.DoubleScalePrint(value, -100, 0, 100, red, green, hiblack, scaleSize)))
-	value = f1.SpecialChanceBonus + f1.TempSpecialChanceBonus
-	textLeft = append(textLeft, fmt.Sprintf("%20s %s%% %v", "Special Chance Bonus", ui.ColorModifiedValue(value, f1.TempSpecialChanceBonus, "%7.2f", higreenfg, hiredfg), ui.DoubleScalePrint(value, -100, 0, 100, red, green, hiblack, scaleSize)))
-	//textLeft = append(textLeft, fmt.Sprintf("%20s %7.2f%% %v", "Complexity Bonus", f1.ComplexityBonus, ui.DoubleScalePrint(-f1.ComplexityBonus, -100, 0, 100, red, green, hiblack, scaleSize)))
-	//textLeft = append(textLeft, fmt.Sprintf("%20s %7.2f%% %v", "Hit Chance Bonus", f1.HitChanceBonus, ui.DoubleScalePrint(f1.HitChanceBonus, -100, 0, 100, red, green, hiblack, scaleSize)))
-	//textLeft = append(textLeft, fmt.Sprintf("%20s %7.2f%% %v", "Block Chance Bonus", f1.BlockChanceBonus, ui.DoubleScalePrint(f1.BlockChanceBonus, -100, 0, 100, red, green, hiblack, scaleSize)))
-	//textLeft = append(textLeft, fmt.Sprintf("%20s %7.2f%% %v", "Special Chance Bonus", f1.SpecialChanceBonus, ui.DoubleScalePrint(f1.SpecialChanceBonus, -100, 0, 100, red, green, hiblack, scaleSize)))
+	textLeft = append(textLeft, bonusLines(f1.modifiedBonuses(f2))...)
 	textLeft = append(textLeft, "")
 	textLeft = append(textLeft, fmt.Sprintf("%s %d/%d %v", "Health: ", f1.CurrentHealth, f1.MaxHealth, ui.ScalePrint(float64(f1.CurrentHealth), 0, float64(f1.MaxHealth), hiblue, hiblack, scaleSize*2)))
+	textLeft = append(textLeft, fmt.Sprintf("%s %d/%d %v", "Stamina:", f1.CurrentStamina, f1.MaxStamina, ui.ScalePrint(float64(f1.CurrentStamina), 0, float64(f1.MaxStamina), higreen, hiblack, scaleSize*2)))
 	textLeft = append(textLeft, "")
 
 	textRight := []string{}
@@ -687,36 +732,16 @@ func DisplayFighters(f1, f2 *Fighter) {
 	textRight = append(textRight, fmt.Sprintf("Height: %d", f2.Height))
 	textRight = append(textRight, fmt.Sprintf("Weight: %d", f2.Weight))
 	textRight = append(textRight, fmt.Sprintf("Age: %d", f2.Age))
-	conditionsText = []string{}
-	for condition, duration := range f2.Conditions {
-		conditionsText = append(conditionsText, fmt.Sprintf("%s[%d]", condition.String(), duration))
-	}
-	textRight = append(textRight, fmt.Sprintf("Conditions: %s", strings.Join(conditionsText, ", ")))
+	textRight = append(textRight, "Conditions: "+f2.conditionsText())
+	textRight = append(textRight, "Stance: "+f2.Stance.String())
+	textRight = append(textRight, "Immune: "+f2.immunitiesText())
 	textRight = append(textRight, "")
-	textRight = append(textRight, fmt.Sprintf("%12s %5.2f %v %5.2f %-12s", "Agility", scaleRange-f2.AgilityStrengthBalance, ui.ScalePrint(-f2.AgilityStrengthBalance, -scaleRange, scaleRange, higreen, hiblue, scaleSize), scaleRange+f2.AgilityStrengthBalance, "Strength"))
-	textRight = append(textRight, fmt.Sprintf("%12s %5.2f %v %5.2f %-12s", "Burst", scaleRange-f2.BurstEnduranceBalance, ui.ScalePrint(-f2.BurstEnduranceBalance, -scaleRange, scaleRange, higreen, hiblue, scaleSize), scaleRange+f2.BurstEnduranceBalance, "Endurance"))
-	textRight = append(textRight, fmt.Sprintf("%12s %5.2f %v %5.2f %-12s", "Defense", scaleRange-f2.DefenseOffenseBalance, ui.ScalePrint(-f2.DefenseOffenseBalance, -scaleRange, scaleRange, higreen, hiblue, scaleSize), scaleRange+f2.DefenseOffenseBalance, "Offense"))
-	textRight = append(textRight, fmt.Sprintf("%12s %5.2f %v %5.2f %-12s", "Speed", scaleRange-f2.SpeedControlBalance, ui.ScalePrint(-f2.SpeedControlBalance, -scaleRange, scaleRange, higreen, hiblue, scaleSize), scaleRange+f2.SpeedControlBalance, "Control"))
-	textRight = append(textRight, fmt.Sprintf("%12s %5.2f %v %5.2f %-12s", "Intelligence", scaleRange-f2.IntelligenceInstinctBalance, ui.ScalePrint(-f2.IntelligenceInstinctBalance, -scaleRange, scaleRange, higreen, hiblue, scaleSize), scaleRange+f2.IntelligenceInstinctBalance, "Instinct"))
+	textRight = append(textRight, balanceLines(f2.Build())...)
 	textRight = append(textRight, "")
-	value = f2.DamageBonus + f2.TempDamageBonus
-	textRight = append(textRight, fmt.Sprintf("%20s %s%% %v", "Damage Bonus", ui.ColorModifiedValue(value, f2.TempDamageBonus, "%7.2f", higreenfg, hiredfg), ui.DoubleScalePrint(value, -100, 0, 100, red, green, hiblack, scaleSize)))
-	value = f2.ComplexityBonus + f2.TempComplexityBonus
-	textRight = append(textRight, fmt.Sprintf("%20s %s%% %v", "Complexity Bonus", ui.ColorModifiedValue(value, f2.TempComplexityBonus, "%7.2f", hiredfg, higreenfg), ui.DoubleScalePrint(-value, -100, 0, 100, red, green, hiblack, scaleSize)))
-	value = f2.HitChanceBonus + f2.TempHitChanceBonus
-	textRight = append(textRight, fmt.Sprintf("%20s %s%% %v", "Hit Chance Bonus", ui.ColorModifiedValue(value, f2.TempHitChanceBonus, "%7.2f", higreenfg, hiredfg), ui.DoubleScalePrint(value, -100, 0, 100, red, green, hiblack, scaleSize)))
-	value = f2.BlockChanceBonus + f2.TempBlockChanceBonus
-	textRight = append(textRight, fmt.Sprintf("%20s %s%% %v", "Block Chance Bonus", ui.ColorModifiedValue(value, f2.TempBlockChanceBonus, "%7.2f", higreenfg, hiredfg), ui.DoubleScalePrint(value, -100, 0, 100, red, green, hiblack, scaleSize)))
-	value = f2.SpecialChanceBonus + f2.TempSpecialChanceBonus
-	textRight = append(textRight, fmt.Sprintf("%20s %s%% %v", "Special Chance Bonus", ui.ColorModifiedValue(value, f2.TempSpecialChanceBonus, "%7.2f", higreenfg, hiredfg), ui.DoubleScalePrint(value, -100, 0, 100, red, green, hiblack, scaleSize)))
-
-	// textRight = append(textRight, fmt.Sprintf("%20s %7.2f%% %v", "Damage Bonus", f2.DamageBonus, ui.DoubleScalePrint(f2.DamageBonus, -100, 0, 100, red, green, hiblack, scaleSize)))
-	// textRight = append(textRight, fmt.Sprintf("%20s %7.2f%% %v", "Complexity Bonus", f2.ComplexityBonus, ui.DoubleScalePrint(-f2.ComplexityBonus, -100, 0, 100, red, green, hiblack, scaleSize)))
-	// textRight = append(textRight, fmt.Sprintf("%20s %7.2f%% %v", "Hit Chance Bonus", f2.HitChanceBonus, ui.DoubleScalePrint(f2.HitChanceBonus, -100, 0, 100, red, green, hiblack, scaleSize)))
-	// textRight = append(textRight, fmt.Sprintf("%20s %7.2f%% %v", "Block Chance Bonus", f2.BlockChanceBonus, ui.DoubleScalePrint(f2.BlockChanceBonus, -100, 0, 100, red, green, hiblack, scaleSize)))
-	// textRight = append(textRight, fmt.Sprintf("%20s %7.2f%% %v", "Special Chance Bonus", f2.SpecialChanceBonus, ui.DoubleScalePrint(f2.SpecialChanceBonus, -100, 0, 100, red, green, hiblack, scaleSize)))
+	textRight = append(textRight, bonusLines(f2.modifiedBonuses(f1))...)
 	textRight = append(textRight, "")
 	textRight = append(textRight, fmt.Sprintf("%s %d/%d %v", "Health: ", f2.CurrentHealth, f2.MaxHealth, ui.ScalePrint(float64(f2.CurrentHealth), 0, float64(f2.MaxHealth), hiblue, hiblack, scaleSize*2)))
+	textRight = append(textRight, fmt.Sprintf("%s %d/%d %v", "Stamina:", f2.CurrentStamina, f2.MaxStamina, ui.ScalePrint(float64(f2.CurrentStamina), 0, float64(f2.MaxStamina), higreen, hiblack, scaleSize*2)))
 	textRight = append(textRight, "")
 
 	boxLeft := ui.BoxPrint(20, blue, textLeft)
@@ -728,6 +753,80 @@ func DisplayFighters(f1, f2 *Fighter) {
 
 }
 
+// balanceLines returns the rows of the build's balances with their scales for the fighter's box
+func balanceLines(build Build) []string {
+	hiblue := color.New(color.BgHiBlue).SprintFunc()
+	higreen := color.New(color.BgHiGreen).SprintFunc()
+
+	lines := []string{}
+	for _, axis := range balanceAxes {
+		balance := *axis.value(&build)
+		lines = append(lines, fmt.Sprintf("%12s %5.2f %v %5.2f %-12s", axis.first, balanceScaleRange-balance, ui.ScalePrint(-balance, -balanceScaleRange, balanceScaleRange, higreen, hiblue, scaleSize), balanceScaleRange+balance, axis.second))
+	}
+	return lines
+}
+
+// bonusLines returns the rows of the bonuses with their scales for the fighter's box, the changes of the bonuses are
+// highlighted in green if they help the fighter and in red otherwise
+func bonusLines(bonuses, changes Stats) []string {
+	red := color.New(color.BgRed).SprintFunc()
+	hiblack := color.New(color.BgHiBlack, color.Faint).SprintFunc()
+	green := color.New(color.BgGreen).SprintFunc()
+
+	hiredfg := color.New(color.FgHiRed).SprintFunc()
+	higreenfg := color.New(color.FgHiGreen).SprintFunc()
+
+	return []string{
+		fmt.Sprintf("%20s %s%% %v", "Damage Bonus", ui.ColorModifiedValue(bonuses.DamageBonus, changes.DamageBonus, "%7.2f", higreenfg, hiredfg), ui.DoubleScalePrint(bonuses.DamageBonus, -100, 0, 100, red, green, hiblack, scaleSize)),
+		// Lower complexity is better
+		fmt.Sprintf("%20s %s%% %v", "Complexity Bonus", ui.ColorModifiedValue(bonuses.ComplexityBonus, changes.ComplexityBonus, "%7.2f", hiredfg, higreenfg), ui.DoubleScalePrint(-bonuses.ComplexityBonus, -100, 0, 100, red, green, hiblack, scaleSize)),
+		fmt.Sprintf("%20s %s%% %v", "Hit Chance Bonus", ui.ColorModifiedValue(bonuses.HitChanceBonus, changes.HitChanceBonus, "%7.2f", higreenfg, hiredfg), ui.DoubleScalePrint(bonuses.HitChanceBonus, -100, 0, 100, red, green, hiblack, scaleSize)),
+		fmt.Sprintf("%20s %s%% %v", "Block Chance Bonus", ui.ColorModifiedValue(bonuses.BlockChanceBonus, changes.BlockChanceBonus, "%7.2f", higreenfg, hiredfg), ui.DoubleScalePrint(bonuses.BlockChanceBonus, -100, 0, 100, red, green, hiblack, scaleSize)),
+		fmt.Sprintf("%20s %s%% %v", "Special Chance Bonus", ui.ColorModifiedValue(bonuses.SpecialChanceBonus, changes.SpecialChanceBonus, "%7.2f", higreenfg, hiredfg), ui.DoubleScalePrint(bonuses.SpecialChanceBonus, -100, 0, 100, red, green, hiblack, scaleSize)),
+	}
+}
+
+// modifiedBonuses returns the fighter's bonuses with the modifiers of the conditions against the opponent, and the modifiers alone
+func (f *Fighter) modifiedBonuses(opponent *Fighter) (bonuses, changes Stats) {
+	ledger := f.Ledger(opponent)
+	changes = Stats{
+		DamageBonus:      ledger[modifiers.Damage],
+		ComplexityBonus:  ledger[modifiers.Complexity],
+		HitChanceBonus:   ledger[modifiers.HitChance],
+		BlockChanceBonus: ledger[modifiers.BlockChance],
+	}
+	bonuses = Stats{
+		DamageBonus:        f.DamageBonus + changes.DamageBonus,
+		ComplexityBonus:    f.ComplexityBonus + changes.ComplexityBonus,
+		HitChanceBonus:     f.HitChanceBonus + changes.HitChanceBonus,
+		BlockChanceBonus:   f.BlockChanceBonus + changes.BlockChanceBonus,
+		SpecialChanceBonus: f.SpecialChanceBonus,
+	}
+	return bonuses, changes
+}
+
+// conditionsText returns the fighter's conditions with the remaining turns and the intensity of the stacked ones
+func (f *Fighter) conditionsText() string {
+	conditionsText := []string{}
+	for _, condition := range modifiers.SortedConditions(f.Conditions) {
+		text := fmt.Sprintf("%s[%d]", condition.String(), f.Conditions[condition])
+		if stacks := f.ConditionStack(condition); stacks > 1 {
+			text += fmt.Sprintf("x%d", stacks)
+		}
+		conditionsText = append(conditionsText, text)
+	}
+	return strings.Join(conditionsText, ", ")
+}
+
+// immunitiesText returns the conditions the fighter is immune to with the remaining turns
+func (f *Fighter) immunitiesText() string {
+	immunitiesText := []string{}
+	for _, condition := range modifiers.SortedConditions(f.Immunities) {
+		immunitiesText = append(immunitiesText, fmt.Sprintf("%s[%d]", condition.String(), f.Immunities[condition]))
+	}
+	return strings.Join(immunitiesText, ", ")
+}
+
 // validateNumber requires that the number provided was between min and max
 func validateNumber(optParams ...int) survey.Validator {
 	var min, max int
@@ -748,8 +847,8 @@ func validateNumber(optParams ...int) survey.Validator {
 			if err != nil {
 				return errors.New("Answer should be a number")
 			}
-			if len(optParams) > 0 && (height < min || height > max) {
-				return fmt.Errorf("Answer should be a number between %d and %d", min, max)
+			if len(optParams) > 0 {
+				return checkRange("Answer", height, min, max)
 			}
 		}
 		return nil
@@ -757,315 +856,28 @@ func validateNumber(optParams ...int) survey.Validator {
 
 }
 
-// CreateFighter creates a new fighter object based on user input
-func CreateFighter() *Fighter {
-	// Collect user input
-	// Define the survey questions array
-	qs := []*survey.Question{}
-
-	// Define the survey questions
-	nameQuestion := &survey.Question{
-		Name: "name",
-		Prompt: &survey.Input{
-			Message: "Enter fighter name:",
-		},
-		Validate: survey.Required,
-	}
-	qs = append(qs, nameQuestion)
-
-	heightQuestion := &survey.Question{
-		Name: "height",
-		Prompt: &survey.Input{
-			Message: fmt.Sprintf("Enter fighter height (%d-%d cm):", minHeight, maxHeight),
-			Help:    "Please enter your fighter height. Taller fighters will have bonus to hit chance, while lower height will give make it easier to execute complex attacks",
-			Default: fmt.Sprintf("%d", (minHeight+maxHeight)/2),
-		},
-		Validate: validateNumber(minHeight, maxHeight),
-	}
-	qs = append(qs, heightQuestion)
-
-	weightQuestion := &survey.Question{
-		Name: "weight",
-		Prompt: &survey.Input{
-			Message: fmt.Sprintf("Enter fighter weight (%d-%d kg):", minWeight, maxWeight),
-			Help:    "Please enter your fighter weight. Heavier fighters tend to have increased damage, while lighter fighters will have better hit chance",
-			Default: fmt.Sprintf("%d", (minWeight+maxWeight)/2),
-		},
-		Validate: validateNumber(minWeight, maxWeight),
-	}
-	qs = append(qs, weightQuestion)
-
-	ageQuestion := &survey.Question{
-		Name: "age",
-		Prompt: &survey.Input{
-			Message: fmt.Sprintf("Enter fighter age (%d-%d years):", minAge, maxAge),
-			Help:    "Please enter your fighter age. Older fighters tend to have better chance to execute complex attacks, while younger fighters will have better damage",
-			Default: fmt.Sprintf("%d", (minAge+maxAge)/2),
-		},
-		Validate: validateNumber(minAge, maxAge),
-	}
-	qs = append(qs, ageQuestion)
-
-	agilityStrengthBalanceQuestion := &survey.Question{
-		Name: "agilityStrengthBalance",
-		Prompt: &survey.Select{
-			Message: "Choose fighter agility/strength balance:",
-			Options: []string{"Very high Agility, Very low Strength", "High Agility, Low Strength", "Balanced", "Low Agility, High Strength", "Very low Agility, Very high Strength"},
-			Help:    "This parameter determines the balance between Agility and Strength. High Agility will allow fighter to get better chances to hit and block, while high Strength will increase damage",
-			Default: "Balanced",
-		},
-	}
-	qs = append(qs, agilityStrengthBalanceQuestion)
-
-	burstEnduranceBalanceQuestion := &survey.Question{
-		Name: "burstEnduranceBalance",
-		Prompt: &survey.Select{
-			Message: "Choose fighter burst/endurance balance:",
-			Options: []string{"Very high Burst, Very low Endurance", "High Burst, Low Endurance", "Balanced", "Low Burst, High Endurance", "Very low Burst, Very high Endurance"},
-			Help:    "This parameter determines the balance between Burst and Endurance. Fighters with high Burst will be able to execute complex attacks with better chance of special attacks, but high Endurance will increase hit chance",
-			Default: "Balanced",
-		},
-	}
-	qs = append(qs, burstEnduranceBalanceQuestion)
-
-	defenseOffenseBalanceQuestion := &survey.Question{
-		Name: "defenseOffenseBalance",
-		Prompt: &survey.Select{
-			Message: "Choose fighter defense/offense balance:",
-			Options: []string{"Very high Defense, Very low Offense", "High Defense, Low Offense", "Balanced", "Low Defense, High Offense", "Very low Defense, Very high Offense"},
-			Help:    "This parameter determines the balance between Defense and Offense. Increasing Defense will improve your chances of blocking attacks, while increasing Offense will increase damage and chance to hit",
-			Default: "Balanced",
-		},
-	}
-	qs = append(qs, defenseOffenseBalanceQuestion)
-
-	speedControlBalanceQuestion := &survey.Question{
-		Name: "speedControlBalance",
-		Prompt: &survey.Select{
-			Message: "Choose fighter speed/control balance:",
-			Options: []string{"Very high Speed, Very low Control", "High Speed, Low Control", "Balanced", "Low Speed, High Control", "Very low Speed, Very high Control"},
-			Help:    "This parameter determines the balance between Speed and Control. Increasing Speed will improve your chances of performing special attack, while high Control will allow to execute complex attacks with increased damage",
-			Default: "Balanced",
-		},
-	}
-	qs = append(qs, speedControlBalanceQuestion)
-
-	intelligenceInstinctBalanceQuestion := &survey.Question{
-		Name: "intelligenceInstinctBalance",
-		Prompt: &survey.Select{
-			Message: "Choose fighter intelligence/instinct balance:",
-			Options: []string{"Very high Intelligence, Very low Instinct", "High Intelligence, Low Instinct", "Balanced", "Low Intelligence, High Instinct", "Very low Intelligence, Very high Instinct"},
-			Help:    "This parameter determines the balance between Intelligence and Instinct. Increasing Intelligence will help with executing more complex attacks, while increasing Instinct will improve your chances of successfully blocking attacks and execute specials",
-			Default: "Balanced",
-		},
-	}
-	qs = append(qs, intelligenceInstinctBalanceQuestion)
-
-	// Ask the user for input
-	answers := struct {
-		Name                        string
-		Height                      int
-		Weight                      int
-		Age                         int
-		AgilityStrengthBalance      int
-		BurstEnduranceBalance       int
-		DefenseOffenseBalance       int
-		SpeedControlBalance         int
-		IntelligenceInstinctBalance int
-	}{}
-
-	err := survey.Ask(qs, &answers)
-	if err != nil {
-		fmt.Println("Error during the fighter creation:", err)
-		return nil
-	}
-
-	//fmt.Println(answers)
-
-	/* 	attackType := attack.AttackType(0)
-	   	attackTypePromptOptions := []string{}
-
-	   	for attackType.String() != "" {
-	   		attackTypePromptOptions = append(attackTypePromptOptions, attackType.String())
-	   		//+": "+attackType.Hint())
-	   		attackType++
-	   	}
-
-	   	attackTypePrompt := &survey.Select{
-	   		Message:  "Select an attack type:",
-	   		Options:  attackTypePromptOptions,
-	   		PageSize: attack.MaxAttackTypes,
-	   		Help:     "Punch: Closed fist attacks, high damage, low complexity, high hit chance, high block chance\nSlap: Open fist or back hand attacks, very low damage, low complexity, high hit chance, high block chance\nKick: Leg attacks, high damage, average complexity, high hit chance, high block chance\nKnee strike: Attacks with a knee, very high damage, average complexity, high hit chance, average block chance\nElbow strike: Attacks with an elbow, very high damage, low complexity, high hit chance, high block chance\nThrow: Attacks to knockdown opponent, average damage, average complexity, average hit chance, average block chance, can knockdown opponent\nLock: Grapple attacks to block joint movement, very low damage, high complexity, low hit chance, low block chance, decrease opponent's hit and block chances\nChoke: Grapple attacks to block airways, low damage, high complexity, low hit chance, low block chance, decrease opponent's damage and increase complexity\nCustom: Custom free text attack",
-	   	} */
-
-	//Calculate bonuses from Age, Weight and Height, i.e. normalize the value across [-2;+2] scale
-	ageBonus := float64(answers.Age-minAge)/(maxAge-minAge)*2 - 1
-	weightBonus := float64(answers.Weight-minWeight)/(maxWeight-minWeight)*2 - 1
-	heightBonus := float64(answers.Height-minHeight)/(maxHeight-minHeight)*2 - 1
+// CreateFighter creates a new fighter object based on user input, the build can't cost more than the positive budget
+func CreateFighter(budget int) *Fighter {
+	return BuildFighter(nil, budget)
+}
 
-	// Create the fighter object
-	fighter := &Fighter{
-		Name:   answers.Name,
-		Height: answers.Height,
-		Weight: answers.Weight,
-		Age:    answers.Age,
-		// AgilityStrengthBalance:      float64(answers.AgilityStrengthBalance) - 2 + 2*weightBonus + heightBonus,
-		// BurstEnduranceBalance:       float64(answers.BurstEnduranceBalance) - 2 + 2*weightBonus + ageBonus,
-		// DefenseOffenseBalance:       float64(answers.DefenseOffenseBalance) - 2 + 2*heightBonus - weightBonus,
-		// SpeedControlBalance:         float64(answers.SpeedControlBalance) - 2 + 2*heightBonus + ageBonus,
-		// IntelligenceInstinctBalance: float64(answers.IntelligenceInstinctBalance) - 2 - 3*ageBonus,
-		AgilityStrengthBalance:      float64(answers.AgilityStrengthBalance) - 2,
-		BurstEnduranceBalance:       float64(answers.BurstEnduranceBalance) - 2,
-		DefenseOffenseBalance:       float64(answers.DefenseOffenseBalance) - 2,
-		SpeedControlBalance:         float64(answers.SpeedControlBalance) - 2,
-		IntelligenceInstinctBalance: float64(answers.IntelligenceInstinctBalance) - 2,
-
-		CurrentHealth: 250 + (answers.Weight - (maxWeight+minWeight)/2),
-		MaxHealth:     250 + (answers.Weight - (maxWeight+minWeight)/2),
-		Conditions:    make(map[modifiers.Condition]int),
+// EditFighter asks the player to change the attributes of the fighter, starting from the current ones, and returns the changed fighter.
+// The custom attacks are kept, it returns nil if the player cancels.
+func EditFighter(f *Fighter, budget int) *Fighter {
+	edited := BuildFighter(f, budget)
+	if edited != nil {
+		edited.CustomAttacks = f.CustomAttacks
 	}
-	fmt.Printf("fighter: %v\n", fighter.String())
-	fmt.Printf("ageBonus: %v\n", ageBonus)
-	fmt.Printf("weightBonus: %v\n", weightBonus)
-	fmt.Printf("heightBonus: %v\n", heightBonus)
-
-	/* 	//Min is -48%, max is +48%
-	   	fighter.DamageBonus = (2*fighter.AgilityStrengthBalance + fighter.DefenseOffenseBalance + fighter.SpeedControlBalance + 4*weightBonus - 4*ageBonus) * 4
-	   	fighter.ComplexityBonus = (fighter.BurstEnduranceBalance - fighter.SpeedControlBalance + 2*fighter.IntelligenceInstinctBalance + 4*heightBonus - 4*ageBonus) * 4
-	   	fighter.HitChanceBonus = (fighter.DefenseOffenseBalance + 2*fighter.BurstEnduranceBalance - fighter.AgilityStrengthBalance - 4*weightBonus + 4*heightBonus) * 4
-	   	fighter.BlockChanceBonus = (fighter.IntelligenceInstinctBalance - 2*fighter.DefenseOffenseBalance - fighter.AgilityStrengthBalance) * 6
-	   	fighter.SpecialChanceBonus = (fighter.IntelligenceInstinctBalance - 2*fighter.SpeedControlBalance - fighter.BurstEnduranceBalance) * 2
-	*/
-
-	//Min is -48%, max is +48%
-	fighter.DamageBonus = (4*fighter.AgilityStrengthBalance + 4*fighter.DefenseOffenseBalance + 4*weightBonus - 4*ageBonus) * 4
-	fighter.ComplexityBonus = (4*fighter.SpeedControlBalance + 4*fighter.IntelligenceInstinctBalance + 4*heightBonus - 4*ageBonus) * 4
-	fighter.HitChanceBonus = (-4*fighter.BurstEnduranceBalance - 4*fighter.AgilityStrengthBalance - 4*weightBonus + 4*heightBonus) * 4
-	fighter.BlockChanceBonus = (4*fighter.IntelligenceInstinctBalance - 4*fighter.DefenseOffenseBalance) * 6
-	fighter.SpecialChanceBonus = (4*fighter.SpeedControlBalance + 4*fighter.BurstEnduranceBalance) * 6
-
-	/* 	defaultAttacks := attack.NewDefaultAttacks()
-	   	attacks := []*attack.Attack{}
-	   	for _, a := range defaultAttacks.ByName {
-	   		attacks = append(attacks, a)
-	   	}
-
-	   	i := 0
-	   	for i < numAttacks {
-	   		fmt.Printf("Attack %d from %d\n", i+1, numAttacks)
-	   		attackTypeSelected := 0
-	   		// Ask for attack type
-	   		err := survey.AskOne(attackTypePrompt, &attackTypeSelected, survey.WithValidator(survey.Required))
-	   		if err != nil {
-	   			fmt.Println("Error during the attack type selection:", err)
-	   			break
-	   		}
-	   		attackType = attack.AttackType(attackTypeSelected)
-	   		fmt.Println("defaultAttacks.GetAttacksByType(attackType)=", defaultAttacks.GetAttacksByType(attackType))
-
-	   		// If non-custom type, ask for specific attack
-	   		if attackType != attack.Custom {
-	   			attackNamePromptOptions := []string{}
-	   			for _, value := range defaultAttacks.GetAttacksByType(attackType) {
-	   				//				damage:=clamp(value.Damage+fighter.DamageBonus,)
-	   				//attackDescription := fmt.Sprintf("%s [DMG: %5.2f, CMP: %5.2f, HIT: %5.2f, BLK: %5.2f, SPC: %5.2f]", value.Name, value.Damage+fighter.DamageBonus, value.Complexity+fighter.ComplexityBonus, value.HitChance+fighter.HitChanceBonus, value.BlockChance+fighter.BlockChanceBonus, value.SpecialChance+fighter.SpecialChanceBonus)
-	   				attackNamePromptOptions = append(attackNamePromptOptions, value.Name)
-	   			}
-	   			attackNamePromptOptions = append(attackNamePromptOptions, "<-Back")
-
-	   			attackNamePrompt := &survey.Select{
-	   				Message:  "Select an attack:",
-	   				Options:  attackNamePromptOptions,
-	   				PageSize: len(attackNamePromptOptions),
-	   				Description: func(value string, index int) string {
-	   					if value != "<-Back" {
-	   						attack := defaultAttacks.GetAttackByName(value)
-	   						return fmt.Sprintf("[DMG: %5.2f, CMP: %5.2f, HIT: %5.2f, BLK: %5.2f, SPC: %5.2f]", attack.Damage+fighter.DamageBonus, attack.Complexity+fighter.ComplexityBonus, attack.HitChance+fighter.HitChanceBonus, attack.BlockChance+fighter.BlockChanceBonus, attack.SpecialChance+fighter.SpecialChanceBonus)
-	   					}
-	   					return ""
-	   				},
-	   			}
-	   			attackName := ""
-	   			err = survey.AskOne(attackNamePrompt, &attackName, survey.WithValidator(survey.Required))
-	   			if err != nil {
-	   				fmt.Println("Error during the attack selection:", err)
-	   				break
-	   			}
-	   			if attackName != "<-Back" {
-	   				// Add attack to attacks array
-	   				attacks = append(attacks, defaultAttacks.GetAttackByName(attackName))
-	   				fmt.Printf("attacks= %v\n", attacks)
-	   				i++
-	   			}
-	   			continue
-	   		}
-	*/
-
-	/*
-
-		answer := true
-		prompt := &survey.Confirm{
-			Message: "Do you want to add custom combos to the fighter?",
-		}
-		survey.AskOne(prompt, &answer)
-		for answer {
-
-			// Ask for the description of the custom attack
-			customAttackName := ""
-			customAttackPrompt := &survey.Input{
-				Message: "Enter the description for the custom attack:",
-			}
-			err = survey.AskOne(customAttackPrompt, &customAttackName)
-			fmt.Println("customAttackName=" + customAttackName)
-			if err != nil {
-				fmt.Println("Error during the custom attack creation:", err)
-				break
-			}
-
-			// Validate the attack name and get the attack parameters using OpenAI API
-			validAttack, err := validateAttackName(customAttackName)
-			if err != nil {
-				fmt.Printf("Error validating attack name: %s\n", err)
-				continue
-			}
-
-			if validAttack {
-				fmt.Println("Valid attack")
-				customAttackType, err := GetOpenAIResponse("COG_TYPE_ATTACK_PROMPT", customAttackName, "", "", "string")
-				if err != nil {
-					fmt.Printf("Error getting data for COG_COMPLEXITY_ATTACK_PROMPT: %s\n", err)
-					continue
-				}
-				fmt.Println("customAttackType=", customAttackType.(string))
-
-				complexityValue, err := GetOpenAIResponse("COG_COMPLEXITY_ATTACK_PROMPT", customAttackName, "", "", "int")
-				if err != nil {
-					fmt.Printf("Error getting data for COG_COMPLEXITY_ATTACK_PROMPT: %s\n", err)
-					continue
-				}
-				fmt.Println("complexityValue=", complexityValue.(int))
-			}
-			//fighter.CustomAttacks = append(fighter.CustomAttacks, attacks...)
-			prompt := &survey.Confirm{
-				Message: "Do you want to add more custom combos to the fighter?",
-			}
-			survey.AskOne(prompt, &answer)
-		}
-	*/
-	// Create the fighter attacks
-
-	//fmt.Printf("fighter.Attacks= %v\n", fighter.Attacks)
-
-	fmt.Printf("\n%s has been created!\n", fighter.Name)
-	//fighter.DisplayFighter()
-
-	return fighter
+	return edited
 }
 
-// GenerateComputerFighter generates a computer-controlled fighter
-func GenerateComputerFighter(playerFighter *Fighter) *Fighter {
-	rand.Seed(time.Now().UnixNano())
+// balanceIndex returns the index of the balance answer closest to the balance value
+func balanceIndex(balance float64) int {
+	return int(math.Max(0, math.Min(4, math.Round(balance)+2)))
+}
 
+// GenerateFighter generates a fighter with random attributes using the provided random source
+func GenerateFighter(rng *rand.Rand) *Fighter {
 	answers := struct {
 		Height                      int
 		Weight                      int
@@ -1078,210 +890,27 @@ func GenerateComputerFighter(playerFighter *Fighter) *Fighter {
 	}{}
 
 	// Generate random values for the computer fighter's attributes
-	answers.AgilityStrengthBalance = rand.Intn(5)
-	answers.BurstEnduranceBalance = rand.Intn(5)
-	answers.DefenseOffenseBalance = rand.Intn(5)
-	answers.SpeedControlBalance = rand.Intn(5)
-	answers.IntelligenceInstinctBalance = rand.Intn(5)
-
-	answers.Height = rand.Intn(maxHeight-minHeight+1) + minHeight // Height between 160 and 200 cm
-	answers.Weight = rand.Intn(maxWeight-minWeight+1) + minWeight // Weight between 60 and 120 kg
-	answers.Age = rand.Intn(maxAge-minAge+1) + minAge             // Age between 18 and 60 years
+	answers.AgilityStrengthBalance = rng.Intn(5)
+	answers.BurstEnduranceBalance = rng.Intn(5)
+	answers.DefenseOffenseBalance = rng.Intn(5)
+	answers.SpeedControlBalance = rng.Intn(5)
+	answers.IntelligenceInstinctBalance = rng.Intn(5)
 
-	//Calculate bonuses from Age, Weight and Height, i.e. normalize the value across [-2;+2] scale
-	ageBonus := float64((answers.Age-minAge)/(maxAge-minAge)*2 - 1)
-	weightBonus := float64((answers.Weight-minWeight)/(maxWeight-minWeight)*2 - 1)
-	heightBonus := float64((answers.Height-minHeight)/(maxHeight-minHeight)*2 - 1)
+	answers.Height = rng.Intn(maxHeight-minHeight+1) + minHeight // Height between 160 and 200 cm
+	answers.Weight = rng.Intn(maxWeight-minWeight+1) + minWeight // Weight between 60 and 120 kg
+	answers.Age = rng.Intn(maxAge-minAge+1) + minAge             // Age between 18 and 60 years
 
 	// Create the fighter object
-	computerFighter := &Fighter{
-		Name:                        fighterNames[rand.Intn(len(fighterNames))],
-		Height:                      answers.Height,
-		Weight:                      answers.Weight,
-		Age:                         answers.Age,
-		AgilityStrengthBalance:      float64(answers.AgilityStrengthBalance) - 2,
-		BurstEnduranceBalance:       float64(answers.BurstEnduranceBalance) - 2,
-		DefenseOffenseBalance:       float64(answers.DefenseOffenseBalance) - 2,
-		SpeedControlBalance:         float64(answers.SpeedControlBalance) - 2,
-		IntelligenceInstinctBalance: float64(answers.IntelligenceInstinctBalance) - 2,
-		CurrentHealth:               250 + (answers.Weight - (maxWeight+minWeight)/2),
-		MaxHealth:                   250 + (answers.Weight - (maxWeight+minWeight)/2),
-		Conditions:                  make(map[modifiers.Condition]int),
-	}
+	computerFighter := NewFighter(fighterNames[rng.Intn(len(fighterNames))], Build{
+		Height:               answers.Height,
+		Weight:               answers.Weight,
+		Age:                  answers.Age,
+		AgilityStrength:      float64(answers.AgilityStrengthBalance) - 2,
+		BurstEndurance:       float64(answers.BurstEnduranceBalance) - 2,
+		DefenseOffense:       float64(answers.DefenseOffenseBalance) - 2,
+		SpeedControl:         float64(answers.SpeedControlBalance) - 2,
+		IntelligenceInstinct: float64(answers.IntelligenceInstinctBalance) - 2,
+	})
 
-	/* 	computerFighter.DamageBonus = (2*computerFighter.AgilityStrengthBalance + computerFighter.DefenseOffenseBalance + computerFighter.SpeedControlBalance + 4*weightBonus - 4*ageBonus) * 4
-	   	computerFighter.ComplexityBonus = (computerFighter.BurstEnduranceBalance - computerFighter.SpeedControlBalance + 2*computerFighter.IntelligenceInstinctBalance + 4*heightBonus - 4*ageBonus) * 4
-	   	computerFighter.HitChanceBonus = (computerFighter.DefenseOffenseBalance - computerFighter.BurstEnduranceBalance - computerFighter.AgilityStrengthBalance - 4*weightBonus + 4*heightBonus) * 4
-	   	computerFighter.BlockChanceBonus = (computerFighter.IntelligenceInstinctBalance - 2*computerFighter.DefenseOffenseBalance - computerFighter.AgilityStrengthBalance) * 6
-	   	computerFighter.SpecialChanceBonus = (computerFighter.IntelligenceInstinctBalance - 2*computerFighter.SpeedControlBalance + 2*computerFighter.BurstEnduranceBalance) * 6
-	*/
-	computerFighter.DamageBonus = (4*computerFighter.AgilityStrengthBalance + 4*computerFighter.DefenseOffenseBalance + 4*weightBonus - 4*ageBonus) * 4
-	computerFighter.ComplexityBonus = (4*computerFighter.SpeedControlBalance + 4*computerFighter.IntelligenceInstinctBalance + 4*heightBonus - 4*ageBonus) * 4
-	computerFighter.HitChanceBonus = (-4*computerFighter.BurstEnduranceBalance - 4*computerFighter.AgilityStrengthBalance - 4*weightBonus + 4*heightBonus) * 4
-	computerFighter.BlockChanceBonus = (4*computerFighter.IntelligenceInstinctBalance - 4*computerFighter.DefenseOffenseBalance) * 6
-	computerFighter.SpecialChanceBonus = (4*computerFighter.SpeedControlBalance + 4*computerFighter.BurstEnduranceBalance) * 6
-
-	/* defaultAttacks := attack.NewDefaultAttacks()
-	for range playerFighter.Attacks {
-		attacksList := defaultAttacks.GetAttacksByType(attack.AttackType(rand.Intn(attack.MaxAttackTypes - 1)))
-		fmt.Println("attacksList=", attacksList)
-		fmt.Println("len(attacksList)=", len(attacksList))
-		computerAttack := attacksList[rand.Intn(len(attacksList))]
-		fmt.Println("computerAttack=", computerAttack)
-		computerFighter.Attacks = append(computerFighter.Attacks, computerAttack)
-	} */
-
-	fmt.Printf("\n%s has been generated!\n", computerFighter.Name)
-	fmt.Println(computerFighter.String())
 	return computerFighter
-
-}
-
-/*
-// validateAttackName validates the given attack name using OpenAI API and returns the attack parameters
-func validateAttackName(attackName string) (bool, error) {
-
-	attackValidation, err := GetOpenAIResponse("COG_VALIDATION_ATTACK_PROMPT", attackName, "", "", "string")
-	if err != nil {
-		return false, fmt.Errorf("error sending OpenAI API request: %s", err)
-	}
-	reply := attackValidation.(string)
-
-	if strings.Contains(reply, "Invalid") {
-		return false, errors.New("Not a valid attack")
-		// } else if strings.Contains(reply, "Multiple") {
-		// 	return false, errors.New("Attack is valid, but not a single attack")
-	} else if strings.Contains(reply, "Impossible") || strings.Contains(reply, "Valid") || strings.Contains(reply, "Multiple") {
-		return true, nil
-	}
-
-	return false, fmt.Errorf("Unknown response from OpenAI API: %s", reply)
-}
-*/
-
-// Get answer from OpenAI API Proxy
-func GetOpenAIResponse(promptEnvVariable string, chatMessages []ChatMessage, responseType string) (interface{}, error) {
-	//fmt.Printf("promptEnvVariable: %v\n", promptEnvVariable)
-	result := ""
-	proxyURL := os.Getenv("OPENAI_WSPROXY_URL")
-	if proxyURL == "" {
-		return nil, fmt.Errorf("OpenAI websocket proxy URL not found in environment variable OPENAI_WSPROXY_URL")
-	}
-
-	data := proxyRequestData{
-		PromptTemplate: promptEnvVariable,
-		Messages:       chatMessages,
-		ResponseType:   responseType,
-	}
-
-	conn, _, err := websocket.DefaultDialer.Dial(proxyURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("Error connecting to Websocket Server: %v\nProxy Url: %s", err, proxyURL)
-	}
-	defer conn.Close()
-
-	/* 	req := fasthttp.AcquireRequest()
-	   	defer fasthttp.ReleaseRequest(req)
-	   	req.SetRequestURI(proxyURL)
-	   	req.Header.SetContentType("application/json")
-	   	req.Header.SetMethod("POST") */
-
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return nil, fmt.Errorf("Error marshaling JSON: %v\nSource data: %v", err, data)
-	}
-
-	conn.WriteMessage(websocket.TextMessage, jsonData)
-	colorStack := []string{"default"}
-	for {
-		_, msg, err := conn.ReadMessage()
-		if err != nil {
-			return nil, fmt.Errorf("Error marshaling JSON: %v\nSource data: %v", err, data)
-		}
-		text := string(msg)
-		coloredText := ""
-		if !strings.Contains(text, ("Endpoint request timed out")) {
-			if text != "<END>" {
-				coloredText, colorStack = ui.ColorizeChunk(text, colorStack)
-				fmt.Print(coloredText)
-				result += text
-			} else {
-				return result, nil
-			}
-		}
-	}
-
-	/* 	req.SetBody(jsonData)
-	   	//fmt.Printf("req: %v\n", req)
-	   	resp := fasthttp.AcquireResponse()
-	   	defer fasthttp.ReleaseResponse(resp)
-
-	   	client := &fasthttp.Client{}
-	   	err = client.Do(req, resp)
-	   	if err != nil {
-	   		return nil, fmt.Errorf("Request failed: %v", err)
-	   	} */
-
-	//fmt.Println("Response status:", resp.StatusCode())
-	//fmt.Println("Response body:", string(resp.Body()))
-	/* 	var proxyResponse proxyResponseData
-	   	err := json.Unmarshal(resp.Body(), &proxyResponse)
-	   	if err != nil {
-	   		return nil, fmt.Errorf("Error unmarshaling JSON: %v\nOriginal JSON: %v", err, resp.Body())
-	   	} */
-
-	/* 	switch responseType {
-	   	case "int":
-	   		{
-	   			return proxyResponse.Int, nil
-	   		}
-	   	case "string":
-	   		{
-	   			return proxyResponse.String, nil
-	   		}
-	   	case "full":
-	   		{
-	   			return proxyResponse.Full, nil
-	   		}
-	   	default:
-	   		return nil, fmt.Errorf("Error parsing response")
-	   	} */
-
-}
-
-// SaveFighterToFile saves a fighter object to a JSON file
-func SaveFighterToFile(fighter *Fighter, filename string) error {
-	// Convert the fighter object to JSON
-	fighterJSON, err := json.MarshalIndent(fighter, "", "  ")
-	if err != nil {
-		return fmt.Errorf("error encoding fighter to JSON: %s", err)
-	}
-
-	// Write the JSON data to the file
-	err = os.WriteFile(filename, fighterJSON, 0644)
-	if err != nil {
-		return fmt.Errorf("error writing fighter data to file: %s", err)
-	}
-
-	fmt.Printf("Fighter data saved to %s!\n", filename)
-	return nil
-}
-
-// LoadFighterFromFile loads a fighter object from a JSON file
-func LoadFighterFromFile(filename string) (*Fighter, error) {
-	// Read the JSON data from the file
-	fighterJSON, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, fmt.Errorf("error reading fighter data from file: %s", err)
-	}
-
-	// Convert the JSON data to a fighter object
-	fighter := &Fighter{}
-	err = json.Unmarshal(fighterJSON, fighter)
-	if err != nil {
-		return nil, fmt.Errorf("error decoding fighter from JSON: %s", err)
-	}
-
-	fmt.Printf("Fighter data loaded from %s!\n", filename)
-	return fighter, nil
 }
@@ -0,0 +1,172 @@
+package fighter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+// testCatalog loads the catalog from the CSV rows without the header
+func testCatalog(t *testing.T, rows ...string) *attack.Attacks {
+	t.Helper()
+	csv := "Name,Type,Damage,Complexity,HitChance,BlockChance,CriticalChance,SpecialChance,Tags\n" + strings.Join(rows, "\n")
+	attacks, err := attack.LoadAttacksCSV(strings.NewReader(csv), "test.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return attacks
+}
+
+func TestFirstUsableAttack(t *testing.T) {
+	groundAndPound := "Ground and Pound,Punch,40,15,80,20,5,15,ground-only"
+	roundhouse := "Roundhouse Kick,Kick,55,25,70,25,5,20,"
+	tests := []struct {
+		name    string
+		catalog []string
+		prone   bool
+		want    string
+	}{
+		{"catalog without Jab", []string{groundAndPound, roundhouse}, false, "Roundhouse Kick"},
+		{"ground-only against prone", []string{groundAndPound, roundhouse}, true, "Ground and Pound"},
+		{"nothing usable", []string{groundAndPound}, false, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, opponent := NewFighter("Tom", DefaultBuild()), NewFighter("Jerry", DefaultBuild())
+			if test.prone {
+				opponent.Conditions[modifiers.Prone] = 1
+			}
+			got := f.firstUsableAttack(opponent, testCatalog(t, test.catalog...))
+			switch {
+			case test.want == "" && got != nil:
+				t.Errorf("got %s, want no attack", got.Name)
+			case test.want != "" && (got == nil || got.Name != test.want):
+				t.Errorf("got %v, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestInflictStacking(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition modifiers.Condition
+		// remaining is the duration left before the condition is applied again
+		remaining int
+		times     int
+		want      event.Event
+		duration  int
+		stacks    int
+	}{
+		{"refresh restarts the duration", modifiers.Disoriented, 1, 1, event.SpecialApplied{}, 3, 1},
+		{"extend adds the duration", modifiers.Insulted, 2, 1, event.SpecialApplied{}, 5, 1},
+		{"stack adds the intensity", modifiers.Bruised, 1, 1, event.SpecialApplied{}, 3, 2},
+		{"stack is capped by max_stacks", modifiers.Bleeding, 1, 5, event.SpecialApplied{}, 3, 3},
+		{"ignore keeps the condition", modifiers.Prone, 1, 1, event.ConditionResisted{}, 1, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, opponent := NewFighter("Tom", DefaultBuild()), NewFighter("Jerry", DefaultBuild())
+			if _, ok := f.inflict(opponent, test.condition).(event.SpecialApplied); !ok {
+				t.Fatalf("first %s wasn't applied", test.condition)
+			}
+			opponent.Conditions[test.condition] = test.remaining
+			var e event.Event
+			for i := 0; i < test.times; i++ {
+				e = f.inflict(opponent, test.condition)
+			}
+			if reflect.TypeOf(e) != reflect.TypeOf(test.want) {
+				t.Errorf("event %T, want %T", e, test.want)
+			}
+			if opponent.Conditions[test.condition] != test.duration || opponent.ConditionStack(test.condition) != test.stacks {
+				t.Errorf("%s lasts %d turns with %d stacks, want %d turns with %d stacks", test.condition,
+					opponent.Conditions[test.condition], opponent.ConditionStack(test.condition), test.duration, test.stacks)
+			}
+		})
+	}
+}
+
+func TestImmunityAfterExpiry(t *testing.T) {
+	f, opponent := NewFighter("Tom", DefaultBuild()), NewFighter("Jerry", DefaultBuild())
+	f.inflict(opponent, modifiers.Paralysed)
+	opponent.ExpireCondition(modifiers.Paralysed)
+	if opponent.Affectable(modifiers.Paralysed) {
+		t.Error("fighter is affectable right after the condition expired")
+	}
+	// Paralysed gives the immunity for 2 turns
+	for turn := 1; turn <= 2; turn++ {
+		resisted, ok := f.inflict(opponent, modifiers.Paralysed).(event.ConditionResisted)
+		if !ok || resisted.Immunity != 3-turn {
+			t.Fatalf("turn %d: got %+v, want resisted with %d immunity turns", turn, resisted, 3-turn)
+		}
+		if _, ok := opponent.Conditions[modifiers.Paralysed]; ok {
+			t.Fatalf("turn %d: immune fighter got the condition", turn)
+		}
+		opponent.TickImmunities()
+	}
+	if _, ok := opponent.Immunities[modifiers.Paralysed]; ok {
+		t.Error("expired immunity wasn't removed")
+	}
+	if _, ok := f.inflict(opponent, modifiers.Paralysed).(event.SpecialApplied); !ok {
+		t.Error("condition can't be applied after the immunity expired")
+	}
+}
+
+func TestResetClearsConditionState(t *testing.T) {
+	f, opponent := NewFighter("Tom", DefaultBuild()), NewFighter("Jerry", DefaultBuild())
+	f.inflict(opponent, modifiers.Bleeding)
+	f.inflict(opponent, modifiers.Bleeding)
+	opponent.ExpireCondition(modifiers.Prone)
+	opponent.Reset()
+	if len(opponent.Conditions) != 0 || len(opponent.ConditionStacks) != 0 || len(opponent.Immunities) != 0 {
+		t.Errorf("reset fighter has conditions %v, stacks %v, immunities %v", opponent.Conditions, opponent.ConditionStacks, opponent.Immunities)
+	}
+}
+
+func TestLedger(t *testing.T) {
+	tests := []struct {
+		name string
+		// own and opponent hold the conditions of the fighters with their stacks
+		own, opponent map[modifiers.Condition]int
+		want          modifiers.Ledger
+	}{
+		{"healthy", nil, nil, modifiers.Ledger{}},
+		{"own condition", map[modifiers.Condition]int{modifiers.Bruised: 1}, nil, modifiers.Ledger{modifiers.HitChance: -20, modifiers.BlockChance: -20}},
+		{"stacked condition", map[modifiers.Condition]int{modifiers.Bruised: 2}, nil, modifiers.Ledger{modifiers.HitChance: -40, modifiers.BlockChance: -40}},
+		{
+			"own conditions add up",
+			map[modifiers.Condition]int{modifiers.Bruised: 1, modifiers.Disoriented: 1},
+			nil,
+			modifiers.Ledger{modifiers.HitChance: -20, modifiers.BlockChance: -20, modifiers.Damage: -20, modifiers.Complexity: 20},
+		},
+		{"opponent condition", nil, map[modifiers.Condition]int{modifiers.Insulted: 1}, modifiers.Ledger{modifiers.HitChance: 20, modifiers.BlockChance: 20}},
+		{"opponent effects of own condition", map[modifiers.Condition]int{modifiers.Insulted: 1}, nil, modifiers.Ledger{}},
+		{"own effects of opponent condition", nil, map[modifiers.Condition]int{modifiers.Bruised: 2}, modifiers.Ledger{}},
+		{
+			"own and opponent conditions cancel out",
+			map[modifiers.Condition]int{modifiers.Bruised: 1},
+			map[modifiers.Condition]int{modifiers.Insulted: 1},
+			modifiers.Ledger{modifiers.HitChance: 0, modifiers.BlockChance: 0},
+		},
+		{"turn effects only", map[modifiers.Condition]int{modifiers.Bleeding: 3, modifiers.Prone: 1}, nil, modifiers.Ledger{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, opponent := NewFighter("Tom", DefaultBuild()), NewFighter("Jerry", DefaultBuild())
+			for fighter, conditions := range map[*Fighter]map[modifiers.Condition]int{f: test.own, opponent: test.opponent} {
+				fighter.ConditionStacks = make(map[modifiers.Condition]int)
+				for condition, stacks := range conditions {
+					fighter.Conditions[condition] = 1
+					fighter.ConditionStacks[condition] = stacks
+				}
+			}
+			if got := f.Ledger(opponent); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ledger is %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package fighter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+)
+
+const (
+	// fighterFileVersion is the version of the fighter file schema written by SaveFighterToFile
+	fighterFileVersion int = 3
+)
+
+// fighterFile is the fighter file schema. It holds only the fighter's build, the stats are derived from it on loading
+// and the battle state isn't saved, so the loaded fighter is ready for a new fight.
+type fighterFile struct {
+	Version       int              `json:"version"`
+	Name          string           `json:"name"`
+	Build         Build            `json:"build"`
+	CustomAttacks []*attack.Attack `json:"custom_attacks,omitempty"`
+}
+
+// fighterV2 is the fighter file that saved the stats derived from the build
+type fighterV2 struct {
+	Version       int              `json:"version"`
+	Name          string           `json:"name"`
+	Build         Build            `json:"build"`
+	Stats         statsFile        `json:"stats"`
+	CustomAttacks []*attack.Attack `json:"custom_attacks,omitempty"`
+}
+
+// statsFile holds the fighter's stats derived from the build in the version 2
+type statsFile struct {
+	MaxHealth          int     `json:"max_health"`
+	MaxStamina         int     `json:"max_stamina"`
+	DamageBonus        float64 `json:"damage_bonus"`
+	ComplexityBonus    float64 `json:"complexity_bonus"`
+	HitChanceBonus     float64 `json:"hit_chance_bonus"`
+	BlockChanceBonus   float64 `json:"block_chance_bonus"`
+	SpecialChanceBonus float64 `json:"special_chance_bonus"`
+}
+
+// fighterV1 is the fighter file before the versioning, a dump of the Fighter struct including the battle state
+type fighterV1 struct {
+	Name                        string
+	Height                      int
+	Weight                      int
+	Age                         int
+	AgilityStrengthBalance      float64
+	BurstEnduranceBalance       float64
+	DefenseOffenseBalance       float64
+	SpeedControlBalance         float64
+	IntelligenceInstinctBalance float64
+	DamageBonus                 float64
+	ComplexityBonus             float64
+	HitChanceBonus              float64
+	BlockChanceBonus            float64
+	SpecialChanceBonus          float64
+	// The battle state is dropped by the migration
+	TempDamageBonus        json.RawMessage
+	TempComplexityBonus    json.RawMessage
+	TempHitChanceBonus     json.RawMessage
+	TempBlockChanceBonus   json.RawMessage
+	TempSpecialChanceBonus json.RawMessage
+	CustomAttacks          []*attackV1
+	Conditions             json.RawMessage
+	CurrentHealth          json.RawMessage
+	MaxHealth              int
+}
+
+// attackV1 is the custom attack saved in the version 1, before the specials, the tags and the description
+type attackV1 struct {
+	Name           string
+	Type           attack.AttackType
+	Damage         float64
+	Complexity     float64
+	HitChance      float64
+	BlockChance    float64
+	CriticalChance float64
+	SpecialChance  float64
+}
+
+// migration upgrades the fighter file from its version to the next one
+type migration func(data []byte) ([]byte, error)
+
+// migrations holds the upgrade of every old fighter file version
+var migrations = map[int]migration{
+	1: migrateV1,
+	2: migrateV2,
+}
+
+// migrateV1 splits the dumped fighter into the build and the stats and drops the battle state
+func migrateV1(data []byte) ([]byte, error) {
+	old := &fighterV1{}
+	err := decodeStrict(data, old)
+	if err != nil {
+		return nil, err
+	}
+	customAttacks := []*attack.Attack{}
+	for _, a := range old.CustomAttacks {
+		customAttacks = append(customAttacks, &attack.Attack{Name: a.Name, Type: a.Type, Damage: a.Damage, Complexity: a.Complexity, HitChance: a.HitChance,
+			BlockChance: a.BlockChance, CriticalChance: a.CriticalChance, SpecialChance: a.SpecialChance})
+	}
+	return json.Marshal(fighterV2{
+		Version: 2,
+		Name:    old.Name,
+		Build: Build{
+			Height:               old.Height,
+			Weight:               old.Weight,
+			Age:                  old.Age,
+			AgilityStrength:      old.AgilityStrengthBalance,
+			BurstEndurance:       old.BurstEnduranceBalance,
+			DefenseOffense:       old.DefenseOffenseBalance,
+			SpeedControl:         old.SpeedControlBalance,
+			IntelligenceInstinct: old.IntelligenceInstinctBalance,
+		},
+		// The version 1 was saved before the stamina, it's derived from the build
+		Stats: statsFile{
+			MaxHealth:          old.MaxHealth,
+			MaxStamina:         maxStamina(old.BurstEnduranceBalance, old.Weight),
+			DamageBonus:        old.DamageBonus,
+			ComplexityBonus:    old.ComplexityBonus,
+			HitChanceBonus:     old.HitChanceBonus,
+			BlockChanceBonus:   old.BlockChanceBonus,
+			SpecialChanceBonus: old.SpecialChanceBonus,
+		},
+		CustomAttacks: customAttacks,
+	})
+}
+
+// migrateV2 drops the saved stats, they are derived from the build when the fighter is loaded
+func migrateV2(data []byte) ([]byte, error) {
+	old := &fighterV2{}
+	err := decodeStrict(data, old)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fighterFile{Version: 3, Name: old.Name, Build: old.Build, CustomAttacks: old.CustomAttacks})
+}
+
+// decodeStrict decodes the JSON data into the value, rejecting the fields the value doesn't have
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// DecodeFighter decodes the fighter file of any supported version, migrating it to the current version
+func DecodeFighter(data []byte) (*Fighter, error) {
+	header := struct {
+		Version *int `json:"version"`
+	}{}
+	err := json.Unmarshal(data, &header)
+	if err != nil {
+		return nil, err
+	}
+	// The files without the version were saved before the versioning
+	version := 1
+	if header.Version != nil {
+		version = *header.Version
+	}
+	if version > fighterFileVersion {
+		return nil, fmt.Errorf("fighter file version %d is newer than the supported version %d, update the game to load it", version, fighterFileVersion)
+	}
+	if version < 1 {
+		return nil, fmt.Errorf("invalid fighter file version %d", version)
+	}
+	for ; version < fighterFileVersion; version++ {
+		data, err = migrations[version](data)
+		if err != nil {
+			return nil, fmt.Errorf("can't migrate fighter file from version %d: %s", version, err)
+		}
+	}
+
+	file := &fighterFile{}
+	err = decodeStrict(data, file)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Build.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid build of %s: %s", file.Name, err)
+	}
+	fighter := NewFighter(file.Name, file.Build)
+	fighter.CustomAttacks = file.CustomAttacks
+	return fighter, nil
+}
+
+// EncodeFighter returns the fighter file of the current version, without the stats and the battle state
+func EncodeFighter(f *Fighter) ([]byte, error) {
+	return json.MarshalIndent(fighterFile{Version: fighterFileVersion, Name: f.Name, Build: f.Build(), CustomAttacks: f.CustomAttacks}, "", "  ")
+}
+
+// SaveFighterToFile saves the fighter's build to a JSON file
+func SaveFighterToFile(fighter *Fighter, filename string) error {
+	fighterJSON, err := EncodeFighter(fighter)
+	if err != nil {
+		return fmt.Errorf("error encoding fighter to JSON: %s", err)
+	}
+
+	// Write the JSON data to the file
+	err = os.WriteFile(filename, fighterJSON, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing fighter data to file: %s", err)
+	}
+	return nil
+}
+
+// LoadFighterFromFile loads a fighter from a JSON file of any supported version, the fighter is ready for a new fight
+func LoadFighterFromFile(filename string) (*Fighter, error) {
+	// Read the JSON data from the file
+	fighterJSON, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading fighter data from file: %s", err)
+	}
+
+	fighter, err := DecodeFighter(fighterJSON)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding fighter from %s: %s", filename, err)
+	}
+	return fighter, nil
+}
@@ -0,0 +1,147 @@
+package fighter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fixtureBuild is the build of Ivan Drago saved in all testdata fighter files.
+// fighter_v1.json is the output of SaveFighterToFile of the first release, saved in the middle of the fight.
+var fixtureBuild = Build{Height: 185, Weight: 95, Age: 31, AgilityStrength: 1, BurstEndurance: -1, DefenseOffense: 2, SpeedControl: 0, IntelligenceInstinct: -2}
+
+// fighterStats returns the stats the fighter has, to compare them with the stats derived from the build
+func fighterStats(f *Fighter) Stats {
+	return Stats{
+		MaxHealth:          f.MaxHealth,
+		MaxStamina:         f.MaxStamina,
+		DamageBonus:        f.DamageBonus,
+		ComplexityBonus:    f.ComplexityBonus,
+		HitChanceBonus:     f.HitChanceBonus,
+		BlockChanceBonus:   f.BlockChanceBonus,
+		SpecialChanceBonus: f.SpecialChanceBonus,
+	}
+}
+
+// readFixture returns the content of the testdata file
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestLoadFighterVersions(t *testing.T) {
+	for _, name := range []string{"fighter_v1.json", "fighter_v2.json", "fighter_v3.json"} {
+		t.Run(name, func(t *testing.T) {
+			f, err := LoadFighterFromFile(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.Name != "Ivan Drago" || f.Build() != fixtureBuild {
+				t.Errorf("loaded %s with %+v, want Ivan Drago with %+v", f.Name, f.Build(), fixtureBuild)
+			}
+			if fighterStats(f) != fixtureBuild.Stats() {
+				t.Errorf("stats are %+v, want %+v", fighterStats(f), fixtureBuild.Stats())
+			}
+			if len(f.CustomAttacks) != 1 || f.CustomAttacks[0].Name != "Siberian Hook" || f.CustomAttacks[0].Damage != 70 {
+				t.Errorf("custom attacks are %v, want the Siberian Hook", f.CustomAttacks)
+			}
+			if f.CurrentHealth != f.MaxHealth || f.CurrentStamina != f.MaxStamina || len(f.Conditions) != 0 || f.Stance != 0 {
+				t.Errorf("loaded fighter isn't ready for a new fight: health %d/%d, stamina %d/%d, conditions %v, stance %s",
+					f.CurrentHealth, f.MaxHealth, f.CurrentStamina, f.MaxStamina, f.Conditions, f.Stance)
+			}
+		})
+	}
+}
+
+func TestMigrationChain(t *testing.T) {
+	v2, err := migrateV1(readFixture(t, "fighter_v1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated := fighterV2{}
+	if err := decodeStrict(v2, &migrated); err != nil {
+		t.Fatalf("version 1 migrated to the invalid version 2: %s", err)
+	}
+	if migrated.Version != 2 || migrated.Build != fixtureBuild {
+		t.Errorf("migrated version %d with %+v, want version 2 with %+v", migrated.Version, migrated.Build, fixtureBuild)
+	}
+	// The version 1 file was saved before the stamina, the migration derives it from the build
+	if want := maxStamina(fixtureBuild.BurstEndurance, fixtureBuild.Weight); migrated.Stats.MaxStamina != want {
+		t.Errorf("migrated max stamina is %d, want %d", migrated.Stats.MaxStamina, want)
+	}
+	if migrated.Stats.MaxHealth != 255 || migrated.Stats.BlockChanceBonus != -96 {
+		t.Errorf("migration didn't keep the saved stats: %+v", migrated.Stats)
+	}
+
+	v3, err := migrateV2(v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, want := fighterFile{}, fighterFile{}
+	if err := decodeStrict(v3, &got); err != nil {
+		t.Fatalf("version 2 migrated to the invalid version 3: %s", err)
+	}
+	if err := decodeStrict(readFixture(t, "fighter_v3.json"), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("version 1 migrated to\n%s\nwant the same as fighter_v3.json", v3)
+	}
+}
+
+func TestDecodeFighterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		old     string
+		new     string
+		want    string
+	}{
+		{"newer version", "fighter_v3.json", `"version": 3`, `"version": 4`, "fighter file version 4 is newer than the supported version 3"},
+		{"zero version", "fighter_v3.json", `"version": 3`, `"version": 0`, "invalid fighter file version 0"},
+		{"unknown field", "fighter_v3.json", `"name":`, `"rank": 1, "name":`, `unknown field "rank"`},
+		{"unknown field in version 2", "fighter_v2.json", `"name":`, `"rank": 1, "name":`, `can't migrate fighter file from version 2: json: unknown field "rank"`},
+		{"unknown field in version 1", "fighter_v1.json", `"Name":`, `"Rank": 1, "Name":`, `can't migrate fighter file from version 1: json: unknown field "Rank"`},
+		{"stance in version 1", "fighter_v1.json", `"Name":`, `"Stance": 2, "Name":`, `can't migrate fighter file from version 1: json: unknown field "Stance"`},
+		{"attack description in version 1", "fighter_v1.json", `"SpecialChance": 20`, `"SpecialChance": 20, "Description": "Hook"`, `unknown field "Description"`},
+		{"invalid build", "fighter_v3.json", `"height": 185`, `"height": 250`, "invalid build of Ivan Drago: height should be a number between 160 and 200"},
+		{"invalid balance", "fighter_v1.json", `"DefenseOffenseBalance": 2`, `"DefenseOffenseBalance": 1.5`, "defense/offense balance should be one of -2, -1, 0, 1, 2, got 1.5"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := readFixture(t, test.fixture)
+			if !bytes.Contains(data, []byte(test.old)) {
+				t.Fatalf("%s doesn't contain %s", test.fixture, test.old)
+			}
+			_, err := DecodeFighter(bytes.Replace(data, []byte(test.old), []byte(test.new), 1))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestSaveFighterToFile(t *testing.T) {
+	f, err := LoadFighterFromFile(filepath.Join("testdata", "fighter_v1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "drago.json")
+	if err := SaveFighterToFile(f, filename); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := bytes.TrimSpace(readFixture(t, "fighter_v3.json")); !bytes.Equal(saved, want) {
+		t.Errorf("saved the migrated fighter as\n%s\nwant\n%s", saved, want)
+	}
+}
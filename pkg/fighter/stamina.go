@@ -0,0 +1,67 @@
+package fighter
+
+import (
+	"math"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+)
+
+const (
+	// baseStamina is the stamina pool of the balanced fighter of the average weight
+	baseStamina = 100
+	// staminaPerEndurance is the stamina added for every point of the burst/endurance balance towards endurance
+	staminaPerEndurance = 15
+	// staminaPerWeight is the stamina lost for every kg above the average weight, lighter fighters get it back
+	staminaPerWeight = 0.5
+	minStamina       = 20
+	// baseStaminaRecovery is the stamina recovered after every own turn by the balanced fighter
+	baseStaminaRecovery = 8
+	// staminaRecoveryPerEndurance is the recovery added for every point of the balance towards endurance
+	staminaRecoveryPerEndurance = 2
+	// tiredStamina is the part of the stamina pool below which the fighter starts to get the penalty
+	tiredStamina = 0.5
+	// maxStaminaPenalty is the penalty to the hit chance and complexity of the exhausted fighter
+	maxStaminaPenalty = 20
+)
+
+// maxStamina returns the stamina pool of the fighter with the burst/endurance balance and weight
+func maxStamina(burstEnduranceBalance float64, weight int) int {
+	stamina := baseStamina + staminaPerEndurance*burstEnduranceBalance - staminaPerWeight*float64(weight-(maxWeight+minWeight)/2)
+	return int(math.Max(math.Round(stamina), minStamina))
+}
+
+// StaminaRecovery returns the stamina the fighter recovers after every own turn
+func (f *Fighter) StaminaRecovery() int {
+	return int(math.Round(baseStaminaRecovery + staminaRecoveryPerEndurance*f.BurstEnduranceBalance))
+}
+
+// SpendStamina takes the stamina cost of the attack from the fighter and returns it, stamina doesn't go below zero
+func (f *Fighter) SpendStamina(a *attack.Attack) int {
+	cost := a.StaminaCost()
+	f.CurrentStamina -= cost
+	if f.CurrentStamina < 0 {
+		f.CurrentStamina = 0
+	}
+	return cost
+}
+
+// RecoverStamina restores the fighter's stamina after its turn up to the maximum
+func (f *Fighter) RecoverStamina() {
+	f.CurrentStamina += f.StaminaRecovery()
+	if f.CurrentStamina > f.MaxStamina {
+		f.CurrentStamina = f.MaxStamina
+	}
+}
+
+// StaminaPenalty returns the penalty to the hit chance and complexity of the tired fighter,
+// it grows from zero at the tired threshold up to the maximum when the fighter runs out of stamina
+func (f *Fighter) StaminaPenalty() float64 {
+	if f.MaxStamina <= 0 {
+		return 0
+	}
+	tired := tiredStamina * float64(f.MaxStamina)
+	if float64(f.CurrentStamina) >= tired {
+		return 0
+	}
+	return maxStaminaPenalty * (1 - float64(f.CurrentStamina)/tired)
+}
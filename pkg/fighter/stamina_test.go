@@ -0,0 +1,132 @@
+package fighter
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zerobugdebug/cogfight/pkg/event"
+)
+
+func TestMaxStamina(t *testing.T) {
+	tests := []struct {
+		name           string
+		burstEndurance float64
+		weight         int
+		want           int
+		wantRecovery   int
+	}{
+		{"balanced", 0, 90, 100, 8},
+		{"endurance", 2, 90, 130, 12},
+		{"heavy burst", -2, 120, 55, 4},
+		{"light", 0, 60, 115, 8},
+		{"minimum", -2, 300, minStamina, 4},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := maxStamina(test.burstEndurance, test.weight); got != test.want {
+				t.Errorf("max stamina is %d, want %d", got, test.want)
+			}
+			f := &Fighter{BurstEnduranceBalance: test.burstEndurance}
+			if got := f.StaminaRecovery(); got != test.wantRecovery {
+				t.Errorf("stamina recovery is %d, want %d", got, test.wantRecovery)
+			}
+		})
+	}
+}
+
+func TestSpendAndRecoverStamina(t *testing.T) {
+	// The cost is a tenth of the damage plus a fifth of the complexity
+	attacks := testCatalog(t, "Hook,Punch,60,20,1,40,5,5,")
+	hook := attacks.GetAttackByName("Hook")
+	f, opponent := NewFighter("Tom", DefaultBuild()), NewFighter("Jerry", DefaultBuild())
+	if f.CurrentStamina != 100 || hook.StaminaCost() != 10 {
+		t.Fatalf("fighter starts with %d stamina and the hook costs %d, want 100 and 10", f.CurrentStamina, hook.StaminaCost())
+	}
+
+	events := f.ApplyAttack(opponent, hook, rand.New(rand.NewSource(1)))
+	attempted, ok := events[0].(event.AttackAttempted)
+	if !ok || attempted.StaminaCost != 10 || attempted.Stamina != 90 || f.CurrentStamina != 90 {
+		t.Errorf("attack left %d stamina with the event %v, want 90 after spending 10", f.CurrentStamina, events[0])
+	}
+
+	steps := []struct {
+		name string
+		step func()
+		want int
+	}{
+		{"recover", f.RecoverStamina, 98},
+		{"recover up to the maximum", f.RecoverStamina, 100},
+		{"spend", func() { f.SpendStamina(hook) }, 90},
+		{"spend more than left", func() { f.CurrentStamina = 4; f.SpendStamina(hook) }, 0},
+		{"recover from zero", f.RecoverStamina, 8},
+	}
+	for _, step := range steps {
+		step.step()
+		if f.CurrentStamina != step.want {
+			t.Errorf("%s: stamina is %d, want %d", step.name, f.CurrentStamina, step.want)
+		}
+	}
+}
+
+func TestStaminaPenalty(t *testing.T) {
+	jab := testCatalog(t, "Jab,Punch,20,5,80,40,5,10,").GetAttackByName("Jab")
+	tests := []struct {
+		stamina int
+		want    float64
+	}{
+		{100, 0},
+		{50, 0},
+		{25, maxStaminaPenalty / 2},
+		{4, maxStaminaPenalty * 0.92},
+	}
+	for _, test := range tests {
+		f, opponent := NewFighter("Tom", DefaultBuild()), NewFighter("Jerry", DefaultBuild())
+		rested := f.ModifiedAttack(opponent, jab)
+		f.CurrentStamina = test.stamina
+		if got := f.StaminaPenalty(); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("penalty with %d stamina is %.2f, want %.2f", test.stamina, got, test.want)
+		}
+		tired := f.ModifiedAttack(opponent, jab)
+		if math.Abs(rested.HitChance-tired.HitChance-test.want) > 1e-9 || math.Abs(tired.Complexity-rested.Complexity-test.want) > 1e-9 {
+			t.Errorf("with %d stamina the hit chance is %.2f and the complexity %.2f, want %.2f and %.2f", test.stamina,
+				tired.HitChance, tired.Complexity, rested.HitChance-test.want, rested.Complexity+test.want)
+		}
+	}
+}
+
+func TestCanUseNeedsStamina(t *testing.T) {
+	hook := testCatalog(t, "Hook,Punch,60,20,1,40,5,5,").GetAttackByName("Hook")
+	tests := []struct {
+		stamina int
+		want    bool
+	}{
+		{100, true},
+		{10, true},
+		{9, false},
+		{0, false},
+	}
+	for _, test := range tests {
+		f, opponent := NewFighter("Tom", DefaultBuild()), NewFighter("Jerry", DefaultBuild())
+		f.CurrentStamina = test.stamina
+		if got := f.CanUse(opponent, hook); got != test.want {
+			t.Errorf("can use the hook with %d stamina is %v, want %v", test.stamina, got, test.want)
+		}
+		if test.want {
+			continue
+		}
+		if reason := f.unusableReason(opponent, hook); reason != "Tom has 10 stamina" {
+			t.Errorf("hook is unusable because %q, want the missing stamina", reason)
+		}
+		if label := f.unusableLabel(opponent, hook); label != "not enough stamina" {
+			t.Errorf("hook is labelled %q, want the missing stamina", label)
+		}
+	}
+	// The catalog fallback only offers the attacks the fighter can afford
+	f, opponent := NewFighter("Tom", DefaultBuild()), NewFighter("Jerry", DefaultBuild())
+	f.CurrentStamina = 5
+	attacks := testCatalog(t, "Hook,Punch,60,20,1,40,5,5,", "Slap,Slap,10,5,80,40,5,10,")
+	if got := f.firstUsableAttack(opponent, attacks); got == nil || got.Name != "Slap" {
+		t.Errorf("first usable attack is %v, want the affordable Slap", got)
+	}
+}
@@ -0,0 +1,118 @@
+package fighter
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/event"
+)
+
+const (
+	// baseDodgeChance is the chance of the balanced fighter to evade the attack in the Dodge stance
+	baseDodgeChance = 20
+	// dodgeChancePerAgility is the dodge chance added for every point of the agility/strength balance towards agility
+	dodgeChancePerAgility = 7.5
+	// baseCounterChance is the chance of the balanced fighter to strike back in the Counter stance
+	baseCounterChance = 30
+	// counterChancePerOffense is the counter chance added for every point of the defense/offense balance towards offense
+	counterChancePerOffense = 5
+	// counterDamageShare is the part of the damage of the failed attack the counter returns to the attacker,
+	// the damage is adjusted by the countering fighter's own bonuses and conditions
+	counterDamageShare = 0.5
+)
+
+// DodgeChance returns the chance of the fighter to evade the attack that hit in the Dodge stance
+func (f *Fighter) DodgeChance() float64 {
+	return math.Max(baseDodgeChance-dodgeChancePerAgility*f.AgilityStrengthBalance, 0)
+}
+
+// CounterChance returns the chance of the fighter to strike back in the Counter stance when the attack doesn't land
+func (f *Fighter) CounterChance() float64 {
+	return math.Max(baseCounterChance+counterChancePerOffense*f.DefenseOffenseBalance, 0)
+}
+
+// cancelsStrike reports whether the attack can't land on the fighter, because the fighter holds the clinch and the attack is a strike
+func (f *Fighter) cancelsStrike(a *attack.Attack) bool {
+	return f.Stance == attack.Clinch && !a.Type.Grapple()
+}
+
+// clinchBonus returns the hit chance bonus of the attack of the fighter, the grapples are easier to land while the fighter holds the clinch
+func (f *Fighter) clinchBonus(a *attack.Attack) float64 {
+	if f.Stance == attack.Clinch && a.Type.Grapple() {
+		return attack.ClinchGrappleBonus
+	}
+	return 0
+}
+
+// counterDamage returns the damage of the fighter's counter to the opponent's attack that didn't land.
+// The counter is as strong as the same attack thrown by the fighter, not by the opponent.
+func (f *Fighter) counterDamage(opponent *Fighter, failedAttack *attack.Attack) float64 {
+	return counterDamageShare * f.ModifiedAttack(opponent, failedAttack).Damage
+}
+
+// counter rolls the fighter's strike back at the opponent, whose attack didn't land, and returns the resulting events
+func (f *Fighter) counter(opponent *Fighter, failedAttack *attack.Attack, rng *rand.Rand) []event.Event {
+	counterChance := f.CounterChance()
+	chance := 100 * rng.Float64()
+	events := []event.Event{event.CounterRoll{Defender: f.Name, Chance: counterChance, Dice: chance, Success: chance < counterChance}}
+	if chance < counterChance {
+		damage := int(f.counterDamage(opponent, failedAttack))
+		opponent.CurrentHealth -= damage
+		events = append(events, event.DamageDealt{Attacker: f.Name, Target: opponent.Name, Amount: damage, Health: opponent.CurrentHealth, MaxHealth: opponent.MaxHealth, Counter: true})
+	}
+	return events
+}
+
+// ExpectedCounterDamage estimates the average damage the fighter in the Counter stance returns to the opponent using the attack
+func (f *Fighter) ExpectedCounterDamage(opponent *Fighter, originalAttack *attack.Attack) float64 {
+	if f.Stance != attack.Counter {
+		return 0
+	}
+	modifiedAttack := opponent.ModifiedAttack(f, originalAttack)
+	executeChance := (100 - modifiedAttack.Complexity) / 100
+	failChance := 1 - opponent.landChance(f, modifiedAttack)
+	return executeChance * failChance * f.CounterChance() / 100 * f.counterDamage(opponent, originalAttack)
+}
+
+// describeStance returns the hint of the stance with the fighter's chances
+func (f *Fighter) describeStance(stance attack.Stance) string {
+	switch stance {
+	case attack.Block:
+		return fmt.Sprintf("%s [BLK: +%.f]", stance.Hint(), stance.BlockBonus())
+	case attack.Dodge:
+		return fmt.Sprintf("%s [DODGE: %.1f%%]", stance.Hint(), f.DodgeChance())
+	case attack.Counter:
+		return fmt.Sprintf("%s [BLK: %.f, COUNTER: %.1f%%]", stance.Hint(), stance.BlockBonus(), f.CounterChance())
+	case attack.Clinch:
+		return fmt.Sprintf("%s [BLK: %.f, GRAPPLE HIT: +%.f]", stance.Hint(), stance.BlockBonus(), attack.ClinchGrappleBonus)
+	default:
+		return fmt.Sprintf("%s [BLK: %.f]", stance.Hint(), stance.BlockBonus())
+	}
+}
+
+// SelectStance asks the player for the stance against the opponent's next attack, the current stance is kept on errors
+func (f *Fighter) SelectStance(opponent *Fighter) attack.Stance {
+	stancePromptOptions := []string{}
+	for _, stance := range attack.Stances {
+		stancePromptOptions = append(stancePromptOptions, stance.String())
+	}
+	stancePrompt := &survey.Select{
+		Message: fmt.Sprintf("Select the stance against the next attack of %s:", opponent.Name),
+		Options: stancePromptOptions,
+		Default: f.Stance.String(),
+		Description: func(value string, index int) string {
+			return f.describeStance(attack.Stances[index])
+		},
+	}
+	selected := 0
+	err := survey.AskOne(stancePrompt, &selected)
+	if err != nil {
+		fmt.Println("Error during the stance selection:", err)
+		return f.Stance
+	}
+	return attack.Stances[selected]
+}
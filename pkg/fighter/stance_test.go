@@ -0,0 +1,82 @@
+package fighter
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/event"
+)
+
+func TestClinch(t *testing.T) {
+	attacks := testCatalog(t, "Jab,Punch,20,5,80,40,5,10,", "Hip Throw,Throw,40,30,50,20,5,40,")
+	jab, throw := attacks.GetAttackByName("Jab"), attacks.GetAttackByName("Hip Throw")
+
+	f, opponent := NewFighter("Tom", DefaultBuild()), NewFighter("Jerry", DefaultBuild())
+	if !f.CanUse(opponent, throw) || !f.CanUse(opponent, jab) {
+		t.Fatal("strikes and grapples should be usable when nobody holds the clinch")
+	}
+	hitChance := f.ModifiedAttack(opponent, throw).HitChance
+
+	f.Stance = attack.Clinch
+	if got := f.ModifiedAttack(opponent, throw).HitChance; got != hitChance+attack.ClinchGrappleBonus {
+		t.Errorf("grapple hit chance in the clinch is %.2f, want %.2f", got, hitChance+attack.ClinchGrappleBonus)
+	}
+	if got, want := f.ModifiedAttack(opponent, jab).HitChance, opponent.ModifiedAttack(f, jab).HitChance; got != want {
+		t.Errorf("strike hit chance in the clinch is %.2f, want %.2f", got, want)
+	}
+
+	f.Stance, opponent.Stance = attack.Block, attack.Clinch
+	if f.CanUse(opponent, jab) {
+		t.Error("strikes should be cancelled by the opponent's clinch")
+	}
+	if !f.CanUse(opponent, throw) {
+		t.Error("grapples should be usable against the opponent in the clinch")
+	}
+}
+
+func TestCounterDamage(t *testing.T) {
+	attacks := testCatalog(t, "Cross,Punch,40,5,1,40,5,5,")
+	cross := attacks.GetAttackByName("Cross")
+
+	tests := []struct {
+		name                         string
+		attackerBonus, defenderBonus float64
+		want                         int
+	}{
+		{"balanced", 0, 0, 20},
+		{"strong attacker", 50, 0, 20},
+		{"strong defender", 0, 50, 30},
+		{"weak defender", 50, -50, 10},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attacker, defender := NewFighter("Tom", DefaultBuild()), NewFighter("Jerry", DefaultBuild())
+			defender.Stance = attack.Counter
+			balanced := defender.ExpectedCounterDamage(attacker, cross)
+			attacker.DamageBonus, defender.DamageBonus = test.attackerBonus, test.defenderBonus
+
+			countered := false
+			for seed := int64(0); seed < 100 && !countered; seed++ {
+				attacker.CurrentHealth, defender.CurrentHealth = attacker.MaxHealth, defender.MaxHealth
+				for _, e := range attacker.ApplyAttack(defender, cross, rand.New(rand.NewSource(seed))) {
+					if damage, ok := e.(event.DamageDealt); ok && damage.Counter {
+						countered = true
+						if damage.Amount != test.want {
+							t.Errorf("counter dealt %d damage, want %d", damage.Amount, test.want)
+						}
+					}
+				}
+			}
+			if !countered {
+				t.Fatal("the attack wasn't countered with any seed")
+			}
+
+			// The balanced counter deals 20 damage, the expected damage scales the same way
+			if got, want := defender.ExpectedCounterDamage(attacker, cross), balanced*float64(test.want)/20; math.Abs(got-want) > 0.01 {
+				t.Errorf("expected counter damage is %.2f, want %.2f", got, want)
+			}
+		})
+	}
+}
@@ -3,9 +3,12 @@ package game
 import (
 	"fmt"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
 
 	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/commentary"
+	"github.com/zerobugdebug/cogfight/pkg/event"
 	"github.com/zerobugdebug/cogfight/pkg/fighter"
 	"github.com/zerobugdebug/cogfight/pkg/modifiers"
 )
@@ -20,152 +23,182 @@ const (
 	clrBadMessage  string = "\033[31m"
 )
 
-// Fight represents the fight match between two fighters
-func Fight(playerFighter *fighter.Fighter, computerFighter *fighter.Fighter) *fighter.Fighter {
-	//rand.Seed(time.Now().UnixNano())
-
-	currentTurn := 1 // keep track of whose turn it is
-	var attacker *fighter.Fighter
-	var defender *fighter.Fighter
+// ConsoleStrategy delegates the choice to the wrapped strategy and announces the selected attack in the terminal
+// if Announce is set, which is useful when the choice was not made by the player
+type ConsoleStrategy struct {
+	Strategy Strategy
+	Announce bool
+}
 
-	fmt.Printf("\n%s vs %s!\n", playerFighter.Name, computerFighter.Name)
-	fighter.DisplayFighters(playerFighter, computerFighter)
-	fmt.Println("Waiting for the comments...")
-	//stopChan := make(chan bool)
-	//var wg sync.WaitGroup
-	//wg.Add(1)
-	//go ui.RotatingPipe(stopChan, &wg)
-	situation := fmt.Sprintf("Fight not started yet. Commentators introduce themselves and talk about the fighters\nFirst fighter: %s Second fighter: %s", playerFighter.String(), computerFighter.String())
-	var chatMessages []fighter.ChatMessage = []fighter.ChatMessage{{Role: "user", Content: situation}}
-	comments, err := fighter.GetOpenAIResponse("COG_TURN_COMMENT_PROMPT", chatMessages, "stream")
-	if err != nil {
-		fmt.Printf("Can't get OpenAI response:\n%v\n", err)
-		return nil
+// ChooseAttack returns the attack chosen by the wrapped strategy
+func (s *ConsoleStrategy) ChooseAttack(m *Match, attacker, defender *fighter.Fighter) *attack.Attack {
+	selectedAttack := s.Strategy.ChooseAttack(m, attacker, defender)
+	if s.Announce && selectedAttack != nil {
+		fmt.Printf("Selected attack: %s\n", color.CyanString(selectedAttack.Name))
 	}
-	//stopChan <- true
-	//wg.Wait()
-	//strComments := strings.Replace(comments.(string), "\n\n", "\n", -1)
-	//fmt.Println("\n" + strComments)
-	chatMessages = append(chatMessages, fighter.ChatMessage{Role: "assistant", Content: comments.(string)})
-	color.HiBlue("\n\nPress 'Enter' to continue...")
-	fmt.Scanln()
+	return selectedAttack
+}
+
+// ChooseStance returns the stance chosen by the wrapped strategy
+func (s *ConsoleStrategy) ChooseStance(m *Match, attacker, defender *fighter.Fighter) attack.Stance {
+	return s.Strategy.ChooseStance(m, attacker, defender)
+}
+
+// ConsolePrinter prints the match events in the terminal with the fighters and the colored dice results
+type ConsolePrinter struct {
+	fighters [2]*fighter.Fighter
+}
+
+// NewConsolePrinter creates a printer for the match between the fighters
+func NewConsolePrinter(f1, f2 *fighter.Fighter) *ConsolePrinter {
+	return &ConsolePrinter{fighters: [2]*fighter.Fighter{f1, f2}}
+}
 
-	var situationDescription string
-	//situationDescription = fmt.Sprintf("First fighter: %s Second fighter: %s", playerFighter.String(), computerFighter.String())
-	//var chatMessages []fighter.ChatMessage = []fighter.ChatMessage{{Role: "system", Content: situationDescription}}
-	// Fight until one of the fighters' health is reduced to zero
-	for playerFighter.CurrentHealth > 0 && computerFighter.CurrentHealth > 0 {
-		//		prevSituationDescription = "Previous rounds: \n" + situationDescription + "\n Current round to be described: \n"
-		//prevSituationDescription = situationDescription
-		situationDescription = ""
-		// Determine who is attacking and who is defending based on the current turn
-		if currentTurn%2 != 0 {
-			attacker = playerFighter
-			defender = computerFighter
+// Notify prints the event
+func (p *ConsolePrinter) Notify(e event.Event) {
+	switch e := e.(type) {
+	case event.TurnStarted:
+		fighter.DisplayFighters(p.fighters[0], p.fighters[1])
+		fmt.Printf("\n%sTurn %d: %s attacks %s!%s\n\n", clrGoodMessage, e.Turn, e.Attacker, e.Defender, clrReset)
+	case event.TurnSkipped:
+		fmt.Printf("%s%s cannot attack, skipping turn!%s\n\n", clrGoodMessage, e.Fighter, clrReset)
+	case event.ComplexityRoll:
+		fmt.Printf("Complexity: %s =>  ", color.HiMagentaString("%.1f%%", e.Complexity))
+		if e.Success {
+			fmt.Printf("%s %s\n", color.HiGreenString("Attack performed flawlessly!"), color.HiBlackString("[Dice = %.1f%%]", e.Dice))
 		} else {
-			attacker = computerFighter
-			defender = playerFighter
+			fmt.Printf("%s %s\n", color.HiRedString(e.Fighter+" failed to execute attack!"), color.HiBlackString("[Dice = %.1f%%]", e.Dice))
 		}
-		fighter.DisplayFighters(playerFighter, computerFighter)
-		skipTurn := 0
-
-		//Apply pre-turn conditions
-		for condition := range attacker.Conditions {
-			if attacker.Conditions[condition] < 1 {
-				delete(attacker.Conditions, condition)
-				defender.RemoveCondition(attacker, condition)
-				situationDescription += attacker.Name + " is not " + condition.String() + " anymore. "
-			} else {
-				for modifier, value := range modifiers.DefaultConditionAttributes[condition] {
-					switch modifier {
-					case modifiers.SkipTurn:
-						{
-							skipTurn = value
-							situationDescription += attacker.Name + " is currently " + condition.String() + ". "
-						}
-					}
-				}
-				attacker.Conditions[condition] -= 1
-			}
-
+	case event.HitRoll:
+		fmt.Printf("Hit Chance: %s => ", color.HiMagentaString("%.1f%%", e.Chance))
+		if e.Success {
+			fmt.Printf("%s %s\n", color.HiGreenString("Successfull hit!"), color.HiBlackString("[Dice = %.1f%%]", e.Dice))
+		} else {
+			fmt.Printf("%s %s\n", color.HiRedString("Missed!"), color.HiBlackString("[Dice = %.1f%%]", e.Dice))
 		}
-
-		if skipTurn != 0 {
-			fmt.Printf("\n%sTurn %d: %s cannot attack, skipping turn!%s\n\n", clrGoodMessage, currentTurn, attacker.Name, clrReset)
-			situationDescription += attacker.Name + " cannot attack. "
+	case event.StrikeCancelled:
+		fmt.Printf("%s\n", color.HiRedString("%s smothers the %s in the clinch!", e.Defender, e.Attack))
+	case event.DodgeRoll:
+		fmt.Printf("Dodge Chance: %s => ", color.HiMagentaString("%.1f%%", e.Chance))
+		if e.Dodged {
+			fmt.Printf("%s %s\n", color.HiRedString("Attack dodged!"), color.HiBlackString("[Dice = %.1f%%]", e.Dice))
 		} else {
-			var selectedAttack *attack.Attack
-			fmt.Printf("\n%sTurn %d: %s attacks %s!%s\n\n", clrGoodMessage, currentTurn, attacker.Name, defender.Name, clrReset)
-			if currentTurn%2 != 0 {
-				selectedAttack = attacker.SelectAttack(defender)
-			} else {
-				selectedAttack = attack.NewDefaultAttacks().GetRandomAttack()
-				fmt.Printf("Selected attack: %s\n", color.CyanString(selectedAttack.Name))
-			}
-			//situationDescription += attacker.Name + " executing " + selectedAttack.Name + ". "
-			attackResultText := attacker.ApplyAttack(defender, selectedAttack)
-			situationDescription += attackResultText
+			fmt.Printf("%s %s\n", color.HiGreenString("Attack not dodged!"), color.HiBlackString("[Dice = %.1f%%]", e.Dice))
 		}
-
-		//Apply post-turn conditions
-		//Calculate effect from attacker conditions
-		for condition := range attacker.Conditions {
-			for modifier, value := range modifiers.DefaultConditionAttributes[condition] {
-				switch modifier {
-				case modifiers.HPPerTurn:
-					{
-						attacker.CurrentHealth += int(value)
-						if int(value) < 0 {
-							fmt.Printf("%s takes %d damage! (%d/%d) due to %s\n", attacker.Name, -int(value), attacker.CurrentHealth, attacker.MaxHealth, condition.String())
-						}
-					}
-				}
+	case event.CounterRoll:
+		fmt.Printf("Counter Chance: %s => ", color.HiMagentaString("%.1f%%", e.Chance))
+		if e.Success {
+			fmt.Printf("%s %s\n", color.HiRedString(e.Defender+" strikes back!"), color.HiBlackString("[Dice = %.1f%%]", e.Dice))
+		} else {
+			fmt.Printf("%s %s\n", color.HiGreenString("No counter!"), color.HiBlackString("[Dice = %.1f%%]", e.Dice))
+		}
+	case event.RoundStarted:
+		fmt.Printf("\n%s\n", color.HiYellowString("=== Round %d of %d ===", e.Round, e.Rounds))
+	case event.RoundEnded:
+		fmt.Printf("\n%s\n", color.HiYellowString("=== End of round %d ===", e.Round))
+		for i, judge := range e.Judges {
+			fmt.Printf("%s scores the round %d-%d\n", judge, e.Scores[i][0], e.Scores[i][1])
+		}
+		for i, f := range p.fighters {
+			if e.Recovered[i] > 0 || e.StaminaRecovered[i] > 0 {
+				fmt.Printf("%s recovers %d health and %d stamina in the corner.\n", color.HiBlueString(f.Name), e.Recovered[i], e.StaminaRecovered[i])
 			}
 		}
-		if defender.CurrentHealth < 0 {
-			situationDescription += defender.Name + " is knocked out. "
+	case event.Decision:
+		fmt.Printf("\n%s\n", color.HiYellowString("The fight goes to the judges' scorecards!"))
+		for i, judge := range e.Judges {
+			fmt.Printf("%s: %d-%d\n", judge, e.Cards[i][0], e.Cards[i][1])
 		}
-		if attacker.CurrentHealth < 0 {
-			situationDescription += attacker.Name + " lost consciousness. "
+		if e.Side < 0 {
+			fmt.Printf("%s\n", color.HiYellowString("It's a draw!"))
+		} else {
+			fmt.Printf("%s\n", color.HiGreenString("%s wins by %s decision!", e.Fighter, e.Type))
 		}
-		//wg.Add(1)
-		//go ui.RotatingPipe(stopChan, &wg)
-		//situation := fmt.Sprintf("Previous rounds:\n %s\n Current round to be described:\nTurn %d: %s attacks %s. %s", prevSituationDescription, currentTurn, attacker.Name, defender.Name, situationDescription)
-		situation := fmt.Sprintf("Turn %d: %s attacks %s. %s", currentTurn, attacker.Name, defender.Name, situationDescription)
-		chatMessages = append(chatMessages, fighter.ChatMessage{Role: "user", Content: situation})
-		// fmt.Printf("\n-------------------------------\n")
-		// fmt.Printf("prevSituationDescription: %v\n", prevSituationDescription)
-		// fmt.Printf("\n-------------------------------\n")
-		// fmt.Printf("situationDescription: %v\n", situationDescription)
-		// fmt.Printf("\n-------------------------------\n")
-		// fmt.Printf("situation: %v\n", situation)
-		comments, err := fighter.GetOpenAIResponse("COG_TURN_COMMENT_PROMPT", chatMessages, "stream")
-		if err != nil {
-			fmt.Printf("Can't get OpenAI response:\n%v\n", err)
-			return nil
+	case event.Forfeit:
+		fmt.Printf("%s\n", color.HiRedString("%s throws in the towel!", e.Fighter))
+	case event.StanceChosen:
+		if e.Condition != modifiers.Healthy {
+			fmt.Printf("%s can only %s.\n", color.HiBlueString(e.Fighter), color.CyanString(e.Stance.String()))
+		} else {
+			fmt.Printf("%s takes the %s stance.\n", color.HiBlueString(e.Fighter), color.CyanString(e.Stance.String()))
 		}
-		//stopChan <- true
-		//wg.Wait()
-		//strComments := strings.Replace(comments.(string), "\n\n", "\n", -1)
-		//fmt.Println("\n" + strComments)
-		chatMessages = append(chatMessages, fighter.ChatMessage{Role: "assistant", Content: comments.(string)})
-		//prevSituationDescription = fmt.Sprintf("%s\nTurn %d: %s attacks %s. \n%s\n%s\n", prevSituationDescription, currentTurn, attacker.Name, defender.Name, situationDescription, comments.(string))
-		//fmt.Printf("\n-------------------------------\n")
-		//fmt.Printf("chatMessages: %v\n", chatMessages)
-		//fmt.Printf("\n-------------------------------\n")
-		currentTurn++
-		color.HiBlue("\n\nPress 'Enter' to continue...")
-		fmt.Scanln()
+	case event.BlockRoll:
+		fmt.Printf("Block Chance: %s => ", color.HiMagentaString("%.1f%%", e.Chance))
+		if e.Blocked {
+			fmt.Printf("%s %s\n", color.HiRedString("Attack blocked!"), color.HiBlackString("[Dice = %.1f%%]", e.Dice))
+		} else {
+			fmt.Printf("%s %s\n", color.HiGreenString("Attack not blocked!"), color.HiBlackString("[Dice = %.1f%%]", e.Dice))
+		}
+	case event.SpecialRoll:
+		fmt.Printf("Special: %s, %s => ", color.HiBlueString(e.Special.ActionString()), color.HiMagentaString("%.1f%%", e.Chance))
+		if e.Success {
+			fmt.Printf("%s %s\n", color.HiGreenString("Success! Opponent got "+e.Special.String()), color.HiBlackString("[Dice = %.1f%%]", e.Dice))
+		} else {
+			fmt.Printf("%s %s\n", color.HiRedString("Special failed!"), color.HiBlackString("[Dice = %.1f%%]", e.Dice))
+		}
+	case event.SpecialApplied:
+		if e.Stacks > 1 {
+			fmt.Printf("%s is %s x%d!\n", color.HiBlueString(e.Defender), e.Condition.String(), e.Stacks)
+		}
+	case event.ConditionResisted:
+		if e.Immunity > 0 {
+			fmt.Printf("%s\n", color.HiRedString("%s is immune to %s for %d more turns!", e.Defender, e.Condition.String(), e.Immunity))
+		} else {
+			fmt.Printf("%s\n", color.HiRedString("%s is already %s!", e.Defender, e.Condition.String()))
+		}
+	case event.DamageDealt:
+		if e.Attack != "" || e.Counter {
+			fmt.Printf("%s takes %s damage! (%s/%s)\n", color.HiBlueString(e.Target), color.HiRedString("%d", e.Amount), color.HiBlueString("%d", e.Health), color.HiBlueString("%d", e.MaxHealth))
+		} else if e.Amount > 0 {
+			fmt.Printf("%s takes %d damage! (%d/%d) due to %s\n", e.Target, e.Amount, e.Health, e.MaxHealth, e.Condition.String())
+		}
+	}
+}
 
+// SelectDifficulty asks the player for the difficulty of the computer opponent
+func SelectDifficulty() (string, error) {
+	difficulty := ""
+	difficultyPrompt := &survey.Select{
+		Message: "Select the computer difficulty:",
+		Options: Difficulties,
+		Help:    "random: picks any attack\ngreedy: picks the attack with the highest expected damage\ntactical: also values the specials and presses the attack while you can't respond\nmontecarlo: simulates the next turns to find the best attack",
+		Default: "greedy",
 	}
+	err := survey.AskOne(difficultyPrompt, &difficulty)
+	return difficulty, err
+}
 
-	// Determine the winner and return the fighter object
-	var winner *fighter.Fighter
-	if computerFighter.CurrentHealth < 0 {
-		winner = playerFighter
-	} else {
-		winner = computerFighter
+// Fight represents the fight match between two fighters in the terminal under the rules, the subscribers receive all match events.
+// Commentary failures never stop the fight. It returns the result of the match.
+func Fight(playerFighter *fighter.Fighter, computerFighter *fighter.Fighter, playerStrategy, computerStrategy Strategy, commentator commentary.Commentator, rules Rules, seed int64, subscribers ...event.Subscriber) *MatchResult {
+	match := NewMatch(playerFighter, computerFighter, playerStrategy, &ConsoleStrategy{Strategy: computerStrategy, Announce: true}, seed)
+	match.SetRules(rules)
+	match.Subscribe(NewConsolePrinter(playerFighter, computerFighter))
+	match.Subscribe(event.Logger{})
+	for _, subscriber := range subscribers {
+		match.Subscribe(subscriber)
+	}
+
+	fmt.Printf("\n%s vs %s! (seed %d)\n", playerFighter.Name, computerFighter.Name, seed)
+	fighter.DisplayFighters(playerFighter, computerFighter)
+	err := commentator.Introduce(playerFighter, computerFighter)
+	if err != nil {
+		fmt.Printf("Can't comment the fight:\n%v\n", err)
+	}
+	color.HiBlue("\n\nPress 'Enter' to continue...")
+	fmt.Scanln()
+
+	// Fight until the knockout, the judges' decision or the forfeit
+	for !match.Over() {
+		events := match.Step()
+
+		err := commentator.Comment(events)
+		if err != nil {
+			fmt.Printf("Can't comment the turn:\n%v\n", err)
+		}
+		color.HiBlue("\n\nPress 'Enter' to continue...")
+		fmt.Scanln()
 	}
 
-	return winner
+	return match.Result()
 }
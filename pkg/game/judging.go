@@ -0,0 +1,133 @@
+package game
+
+import (
+	"math"
+
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+const (
+	// evenRoundMargin is the difference of the judge's points below which the round is scored even
+	evenRoundMargin float64 = 5
+	// dominantRoundMargin is the difference of the judge's points from which the loser of the round gets 8 instead of 9
+	dominantRoundMargin float64 = 75
+)
+
+// roundStats holds what the judges look at in the round for both sides
+type roundStats struct {
+	damage     [2]int
+	specials   [2]int
+	aggression [2]int
+}
+
+// scorecard is a Subscriber that collects the round stats from the match events and keeps the judges' cards
+type scorecard struct {
+	judges []Judge
+	side   int
+	round  roundStats
+	// cards holds the total scores of every judge for both sides
+	cards [][2]int
+}
+
+func newScorecard(judges []Judge) *scorecard {
+	return &scorecard{judges: judges, cards: make([][2]int, len(judges))}
+}
+
+func (s *scorecard) Notify(e event.Event) {
+	switch e := e.(type) {
+	case event.TurnStarted:
+		s.side = e.Side
+	case event.ComplexityRoll:
+		if e.Success {
+			s.round.aggression[s.side]++
+		}
+	case event.SpecialApplied:
+		s.round.specials[s.side]++
+	case event.DamageDealt:
+		if e.Amount > 0 {
+			s.round.damage[damageSide(e, s.side)] += e.Amount
+		}
+	}
+}
+
+// damageSide returns the side credited with the damage dealt in the turn of the attacking side
+func damageSide(e event.DamageDealt, side int) int {
+	switch {
+	case e.Counter:
+		return 1 - side
+	case e.Attack != "":
+		return side
+	case e.Condition != modifiers.Healthy && e.Attacker == "":
+		// The attacker's own condition hurts it, the point goes to the opponent who caused it
+		return 1 - side
+	default:
+		return side
+	}
+}
+
+// scoreRound scores the finished round by every judge, adds the scores to the cards and starts the next round
+func (s *scorecard) scoreRound() [][2]int {
+	scores := make([][2]int, len(s.judges))
+	for i, judge := range s.judges {
+		var points [2]float64
+		for side := 0; side < 2; side++ {
+			points[side] = judge.Damage*float64(s.round.damage[side]) + judge.Specials*float64(s.round.specials[side]) + judge.Aggression*float64(s.round.aggression[side])
+		}
+		scores[i] = [2]int{10, 10}
+		difference := points[0] - points[1]
+		if math.Abs(difference) >= evenRoundMargin {
+			loser := 0
+			if difference > 0 {
+				loser = 1
+			}
+			scores[i][loser] = 9
+			if math.Abs(difference) >= dominantRoundMargin {
+				scores[i][loser] = 8
+			}
+		}
+		s.cards[i][0] += scores[i][0]
+		s.cards[i][1] += scores[i][1]
+	}
+	s.round = roundStats{}
+	return scores
+}
+
+// decision returns how the judges decided the match and the side of the winner, or -1 for the draw
+func (s *scorecard) decision() (event.DecisionType, int) {
+	var wins [2]int
+	draws := 0
+	for _, card := range s.cards {
+		switch {
+		case card[0] > card[1]:
+			wins[0]++
+		case card[1] > card[0]:
+			wins[1]++
+		default:
+			draws++
+		}
+	}
+	winner := 0
+	if wins[1] > wins[0] {
+		winner = 1
+	}
+	switch {
+	case wins[0] == wins[1]:
+		return event.Draw, -1
+	case wins[winner] == len(s.cards):
+		return event.Unanimous, winner
+	case wins[1-winner] == 0:
+		return event.Majority, winner
+	default:
+		return event.Split, winner
+	}
+}
+
+// judgeNames returns the names of the judges in the order of the scores
+func (s *scorecard) judgeNames() []string {
+	names := make([]string, len(s.judges))
+	for i, judge := range s.judges {
+		names[i] = judge.Name
+	}
+	return names
+}
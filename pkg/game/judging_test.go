@@ -0,0 +1,136 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+func TestScoreRound(t *testing.T) {
+	tests := []struct {
+		name  string
+		round roundStats
+		want  [2]int
+	}{
+		{"even", roundStats{damage: [2]int{50, 46}}, [2]int{10, 10}},
+		{"close", roundStats{damage: [2]int{50, 45}}, [2]int{10, 9}},
+		{"dominant", roundStats{damage: [2]int{0, 75}}, [2]int{8, 10}},
+		{"specials and aggression", roundStats{damage: [2]int{20, 0}, specials: [2]int{0, 1}, aggression: [2]int{0, 3}}, [2]int{9, 10}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newScorecard([]Judge{{Name: "Judge", Damage: 1, Specials: 10, Aggression: 5}})
+			s.round = test.round
+			scores := s.scoreRound()
+			if scores[0] != test.want {
+				t.Errorf("round scored %v, want %v", scores[0], test.want)
+			}
+			if s.cards[0] != test.want {
+				t.Errorf("card is %v, want %v", s.cards[0], test.want)
+			}
+			if s.round != (roundStats{}) {
+				t.Error("round stats weren't reset for the next round")
+			}
+		})
+	}
+}
+
+func TestScorecardDecision(t *testing.T) {
+	tests := []struct {
+		name     string
+		cards    [][2]int
+		want     event.DecisionType
+		wantSide int
+	}{
+		{"unanimous", [][2]int{{30, 27}, {29, 28}, {30, 28}}, event.Unanimous, 0},
+		{"majority", [][2]int{{27, 30}, {28, 28}, {28, 29}}, event.Majority, 1},
+		{"split", [][2]int{{29, 28}, {28, 29}, {30, 27}}, event.Split, 0},
+		{"draw by even cards", [][2]int{{29, 28}, {28, 29}, {28, 28}}, event.Draw, -1},
+		{"draw by all even cards", [][2]int{{30, 30}, {29, 29}, {28, 28}}, event.Draw, -1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newScorecard(DefaultJudges)
+			s.cards = test.cards
+			decision, side := s.decision()
+			if decision != test.want || side != test.wantSide {
+				t.Errorf("got %s for side %d, want %s for side %d", decision, side, test.want, test.wantSide)
+			}
+		})
+	}
+}
+
+func TestDamageSide(t *testing.T) {
+	tests := []struct {
+		name   string
+		damage event.DamageDealt
+		want   int
+	}{
+		{"attack", event.DamageDealt{Attacker: "Tom", Attack: "Jab", Amount: 10}, 0},
+		{"counter", event.DamageDealt{Attacker: "Jerry", Amount: 10, Counter: true}, 1},
+		{"own bleeding", event.DamageDealt{Target: "Tom", Amount: 20, Condition: modifiers.Bleeding}, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := damageSide(test.damage, 0); got != test.want {
+				t.Errorf("damage credited to side %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+// judgingAttacks returns the catalog with the heavy Haymaker that rarely applies the specials and the light Poke that almost always does
+func judgingAttacks() *attack.Registry {
+	attacks := attack.NewAttacks()
+	attacks.AddAttack(&attack.Attack{Name: "Haymaker", Type: attack.Punch, Damage: 20, Complexity: 0, HitChance: 99, BlockChance: 0, CriticalChance: 5,
+		Specials: []attack.Special{{Condition: modifiers.Insulted, Chance: 0}}})
+	attacks.AddAttack(&attack.Attack{Name: "Poke", Type: attack.Slap, Damage: 5, Complexity: 0, HitChance: 99, BlockChance: 0, CriticalChance: 5,
+		Specials: []attack.Special{{Condition: modifiers.Insulted, Chance: 95}}})
+	return attack.NewStaticRegistry(attacks)
+}
+
+func TestDecision(t *testing.T) {
+	damage := Judge{Name: "Damage", Damage: 1}
+	specials := Judge{Name: "Specials", Specials: 10}
+	blind := Judge{Name: "Blind"}
+	tests := []struct {
+		name   string
+		judges []Judge
+		want   event.DecisionType
+		// side is the winner, or -1 for the draw
+		side int
+	}{
+		{"unanimous", []Judge{damage, damage, damage}, event.Unanimous, 0},
+		{"majority", []Judge{damage, damage, blind}, event.Majority, 0},
+		{"split", []Judge{damage, specials, specials}, event.Split, 1},
+		{"draw by split cards", []Judge{damage, specials}, event.Draw, -1},
+		{"draw by even cards", []Judge{blind, blind, blind}, event.Draw, -1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			catalog := judgingAttacks()
+			s1 := &ScriptedStrategy{Script: repeatScript(2, "Haymaker"), Catalog: catalog}
+			s2 := &ScriptedStrategy{Script: repeatScript(2, "Poke"), Catalog: catalog}
+			_, result := playMatch(5, s1, s2, Rules{Rounds: 1, TurnsPerRound: 4, Judges: test.judges})
+
+			if result.Decision == nil {
+				t.Fatalf("match ended by %s in turn %d without the decision", result.Method, result.Turn)
+			}
+			if result.Decision.Type != test.want || result.Side != test.side || result.Decision.Side != test.side {
+				t.Fatalf("got %s decision for side %d, want %s for side %d, cards %v", result.Decision.Type, result.Side, test.want, test.side, result.Decision.Cards)
+			}
+			wantMethod := Decision
+			if test.side < 0 {
+				wantMethod = Draw
+			}
+			if result.Method != wantMethod || result.Turn != 4 || result.Round != 1 {
+				t.Errorf("match ended by %s in round %d, turn %d, want %s in round 1, turn 4", result.Method, result.Round, result.Turn, wantMethod)
+			}
+			if (result.Winner == nil) != (test.side < 0) {
+				t.Errorf("winner is %v for side %d", result.Winner, test.side)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package game
+
+import (
+	"math/rand"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/fighter"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+// Match represents a headless fight between two fighters, driven by their strategies
+type Match struct {
+	fighters    [2]*fighter.Fighter
+	strategies  [2]Strategy
+	turn        int
+	seed        int64
+	dice        *rand.Rand
+	choices     *rand.Rand
+	subscribers []event.Subscriber
+	rules       Rules
+	scorecard   *scorecard
+	decision    *event.Decision
+	tally       tally
+	result      *MatchResult
+}
+
+// NewMatch creates a new match lasting until the knockout, the first fighter attacks first.
+// All combat rolls are derived from the seed, so the same seed, fighters and choices always produce the same fight.
+func NewMatch(f1, f2 *fighter.Fighter, s1, s2 Strategy, seed int64) *Match {
+	return &Match{
+		fighters:   [2]*fighter.Fighter{f1, f2},
+		strategies: [2]Strategy{s1, s2},
+		turn:       1,
+		seed:       seed,
+		dice:       rand.New(rand.NewSource(seed)),
+		// Strategies get their own stream, so the dice sequence doesn't depend on how the attacks were chosen
+		choices: rand.New(rand.NewSource(^seed)),
+	}
+}
+
+// Seed returns the seed used for the match random sources
+func (m *Match) Seed() int64 {
+	return m.seed
+}
+
+// Rand returns the random source for the strategies
+func (m *Match) Rand() *rand.Rand {
+	return m.choices
+}
+
+// SetRules changes the rules of the match, it should be called before the first turn
+func (m *Match) SetRules(rules Rules) {
+	m.rules = rules
+	m.scorecard = nil
+	if rules.Rounds > 0 {
+		m.scorecard = newScorecard(rules.judges())
+	}
+}
+
+// Rules returns the rules of the match
+func (m *Match) Rules() Rules {
+	return m.rules
+}
+
+// Round returns the number of the round the next turn belongs to, or 0 if the match has no rounds
+func (m *Match) Round() int {
+	if m.rules.Rounds == 0 {
+		return 0
+	}
+	return (m.turn-1)/m.rules.TurnsPerRound + 1
+}
+
+// Decision returns the judges' decision, or nil if the match wasn't decided by the judges
+func (m *Match) Decision() *event.Decision {
+	return m.decision
+}
+
+// Subscribe registers the subscriber to receive all match events
+func (m *Match) Subscribe(subscriber event.Subscriber) {
+	m.subscribers = append(m.subscribers, subscriber)
+}
+
+// Turn returns the number of the turn to be played next
+func (m *Match) Turn() int {
+	return m.turn
+}
+
+// Fighters returns both fighters in the order they were added to the match
+func (m *Match) Fighters() (*fighter.Fighter, *fighter.Fighter) {
+	return m.fighters[0], m.fighters[1]
+}
+
+// Over reports whether the match ended by the knockout, the judges' decision or the forfeit
+func (m *Match) Over() bool {
+	return m.result != nil
+}
+
+func (m *Match) knockedOut() bool {
+	return m.fighters[0].CurrentHealth <= 0 || m.fighters[1].CurrentHealth <= 0
+}
+
+// Result returns the result of the match, or nil if the match is not over yet
+func (m *Match) Result() *MatchResult {
+	return m.result
+}
+
+// Winner returns the winner of the match or nil if the match is not over yet or ended with the draw
+func (m *Match) Winner() *fighter.Fighter {
+	if m.result == nil {
+		return nil
+	}
+	return m.result.Winner
+}
+
+// Step plays a single turn and returns its events, or nil if the match is over
+func (m *Match) Step() []event.Event {
+	if m.Over() {
+		return nil
+	}
+
+	// Determine who is attacking and who is defending based on the current turn
+	side := (m.turn - 1) % 2
+	attacker := m.fighters[side]
+	defender := m.fighters[1-side]
+	events := []event.Event{}
+	emit := func(e ...event.Event) {
+		for _, e := range e {
+			events = append(events, e)
+			m.tally.Notify(e)
+			if m.scorecard != nil {
+				m.scorecard.Notify(e)
+			}
+			for _, subscriber := range m.subscribers {
+				subscriber.Notify(e)
+			}
+		}
+	}
+	if m.rules.Rounds > 0 && (m.turn-1)%m.rules.TurnsPerRound == 0 {
+		emit(event.RoundStarted{Round: m.Round(), Rounds: m.rules.Rounds})
+	}
+	emit(event.TurnStarted{Turn: m.turn, Side: side, Attacker: attacker.Name, Defender: defender.Name})
+	skipTurn := 0
+	skipCondition := modifiers.Healthy
+
+	//Apply pre-turn conditions
+	attacker.TickImmunities()
+	for _, condition := range modifiers.SortedConditions(attacker.Conditions) {
+		if attacker.Conditions[condition] < 1 {
+			attacker.ExpireCondition(condition)
+			emit(event.ConditionExpired{Fighter: attacker.Name, Condition: condition})
+		} else {
+			if value, ok := modifiers.Lookup(condition).Effects[modifiers.SkipTurn]; ok {
+				skipTurn = value
+				skipCondition = condition
+			}
+			attacker.Conditions[condition] -= 1
+		}
+	}
+
+	if skipTurn != 0 {
+		emit(event.TurnSkipped{Fighter: attacker.Name, Condition: skipCondition})
+		// The fighter who can't act can't dodge or counter either, the previous stance is dropped
+		attacker.Stance = attack.Block
+		emit(event.StanceChosen{Fighter: attacker.Name, Stance: attacker.Stance, Condition: skipCondition})
+	} else {
+		selectedAttack := m.strategies[side].ChooseAttack(m, attacker, defender)
+		if selectedAttack == nil {
+			emit(event.Forfeit{Side: side, Fighter: attacker.Name}, event.TurnEnded{Turn: m.turn})
+			m.finish(1-side, Forfeit, modifiers.Healthy)
+			m.turn++
+			return events
+		}
+		emit(attacker.ApplyAttack(defender, selectedAttack, m.dice)...)
+		// The stance is taken after the attack, it holds until the fighter's next turn
+		if !m.knockedOut() {
+			attacker.Stance = m.strategies[side].ChooseStance(m, attacker, defender)
+			emit(event.StanceChosen{Fighter: attacker.Name, Stance: attacker.Stance})
+		}
+	}
+	attacker.RecoverStamina()
+
+	//Apply post-turn conditions
+	//Calculate effect from attacker conditions
+	hpCondition := modifiers.Healthy
+	defenderHPCondition := modifiers.Healthy
+	// The fighters knocked out by the conditions are knocked out technically
+	attackerStanding := attacker.CurrentHealth > 0
+	defenderStanding := defender.CurrentHealth > 0
+	for _, condition := range modifiers.SortedConditions(attacker.Conditions) {
+		// Every stack of the condition adds the effect again
+		stacks := attacker.ConditionStack(condition)
+		if value, ok := modifiers.Lookup(condition).Effects[modifiers.HPPerTurn]; ok {
+			value *= stacks
+			attacker.CurrentHealth += value
+			hpCondition = condition
+			emit(event.DamageDealt{Target: attacker.Name, Amount: -value, Health: attacker.CurrentHealth, MaxHealth: attacker.MaxHealth, Condition: condition})
+		}
+		if value, ok := modifiers.Lookup(condition).Effects[modifiers.MyHPPerTurn]; ok {
+			value *= stacks
+			defender.CurrentHealth += value
+			defenderHPCondition = condition
+			emit(event.DamageDealt{Attacker: attacker.Name, Target: defender.Name, Amount: -value, Health: defender.CurrentHealth, MaxHealth: defender.MaxHealth, Condition: condition})
+		}
+	}
+	knockOuts := []event.KnockOut{}
+	if defender.CurrentHealth <= 0 {
+		knockOut := event.KnockOut{Side: 1 - side, Fighter: defender.Name}
+		if defenderStanding {
+			knockOut.Condition = defenderHPCondition
+		}
+		knockOuts = append(knockOuts, knockOut)
+	}
+	if attacker.CurrentHealth <= 0 {
+		knockOut := event.KnockOut{Side: side, Fighter: attacker.Name}
+		if attackerStanding {
+			knockOut.Condition = hpCondition
+		}
+		knockOuts = append(knockOuts, knockOut)
+	}
+	for _, knockOut := range knockOuts {
+		emit(knockOut)
+	}
+	emit(event.TurnEnded{Turn: m.turn})
+	switch {
+	case len(knockOuts) == 2:
+		// Both fighters are down after the same turn, nobody wins
+		m.finish(-1, Draw, modifiers.Healthy)
+	case len(knockOuts) == 1 && knockOuts[0].Condition != modifiers.Healthy:
+		m.finish(1-knockOuts[0].Side, TKO, knockOuts[0].Condition)
+	case len(knockOuts) == 1:
+		m.finish(1-knockOuts[0].Side, KO, modifiers.Healthy)
+	case m.rules.Rounds > 0 && m.turn%m.rules.TurnsPerRound == 0:
+		emit(m.endRound()...)
+	}
+
+	m.turn++
+	return events
+}
+
+// endRound scores the round that ended without the knockout and lets the fighters recover before the next one,
+// or returns the judges' decision after the last round
+func (m *Match) endRound() []event.Event {
+	round := m.Round()
+	roundEnded := event.RoundEnded{Round: round, Judges: m.scorecard.judgeNames(), Scores: m.scorecard.scoreRound()}
+	if round >= m.rules.Rounds {
+		decisionType, side := m.scorecard.decision()
+		m.decision = &event.Decision{Type: decisionType, Side: side, Judges: roundEnded.Judges, Cards: m.scorecard.cards}
+		if side >= 0 {
+			m.decision.Fighter = m.fighters[side].Name
+			m.finish(side, Decision, modifiers.Healthy)
+		} else {
+			m.finish(side, Draw, modifiers.Healthy)
+		}
+		return []event.Event{roundEnded, *m.decision}
+	}
+	for i, f := range m.fighters {
+		roundEnded.Recovered[i] = int(m.rules.Recovery * float64(f.MaxHealth-f.CurrentHealth))
+		f.CurrentHealth += roundEnded.Recovered[i]
+		roundEnded.StaminaRecovered[i] = int(m.rules.Recovery * float64(f.MaxStamina-f.CurrentStamina))
+		f.CurrentStamina += roundEnded.StaminaRecovered[i]
+	}
+	return []event.Event{roundEnded}
+}
+
+// Run plays the match until it's over and returns its result
+func (m *Match) Run() *MatchResult {
+	for !m.Over() {
+		m.Step()
+	}
+	return m.result
+}
@@ -0,0 +1,219 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/fighter"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+// eventLog is a Subscriber that keeps all events of the match
+type eventLog []event.Event
+
+func (l *eventLog) Notify(e event.Event) {
+	*l = append(*l, e)
+}
+
+// testFighters returns two fighters of the opposite builds, new for every match
+func testFighters() (*fighter.Fighter, *fighter.Fighter) {
+	striker := fighter.DefaultBuild()
+	striker.AgilityStrength = 2
+	striker.DefenseOffense = 1
+	grappler := fighter.DefaultBuild()
+	grappler.AgilityStrength = -1
+	grappler.IntelligenceInstinct = 2
+	return fighter.NewFighter("Striker", striker), fighter.NewFighter("Grappler", grappler)
+}
+
+// repeatScript returns the attacks repeated n times
+func repeatScript(n int, attacks ...string) []string {
+	script := []string{}
+	for i := 0; i < n; i++ {
+		script = append(script, attacks...)
+	}
+	return script
+}
+
+// newTestMatch returns the match of the test fighters and the log of all its events
+func newTestMatch(seed int64, s1, s2 Strategy, rules Rules) (*Match, *eventLog) {
+	f1, f2 := testFighters()
+	match := NewMatch(f1, f2, s1, s2, seed)
+	match.SetRules(rules)
+	log := &eventLog{}
+	match.Subscribe(log)
+	return match, log
+}
+
+// playMatch plays the match of the test fighters and returns all its events and the result
+func playMatch(seed int64, s1, s2 Strategy, rules Rules) ([]event.Event, *MatchResult) {
+	match, log := newTestMatch(seed, s1, s2, rules)
+	result := match.Run()
+	return *log, result
+}
+
+func TestMatchSameSeedSameEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		strategies func() (Strategy, Strategy)
+	}{
+		{"scripted", func() (Strategy, Strategy) {
+			return &ScriptedStrategy{Script: repeatScript(40, "Jab", "Roundhouse Kick", "Hip Throw")},
+				&ScriptedStrategy{Script: repeatScript(40, "Cross", "Armbar", "Front Kick")}
+		}},
+		{"random", func() (Strategy, Strategy) {
+			return &RandomStrategy{}, &RandomStrategy{}
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, rules := range []Rules{{}, DefaultRules()} {
+				s1, s2 := test.strategies()
+				first, firstResult := playMatch(42, s1, s2, rules)
+				s1, s2 = test.strategies()
+				second, secondResult := playMatch(42, s1, s2, rules)
+				if firstResult.Method == Forfeit {
+					t.Fatalf("match ended by forfeit in turn %d, the script is too short", firstResult.Turn)
+				}
+				if !reflect.DeepEqual(first, second) {
+					t.Fatalf("the same seed produced different events with %d rounds", rules.Rounds)
+				}
+				if firstResult.String() != secondResult.String() {
+					t.Errorf("the same seed produced different results: %q and %q", firstResult, secondResult)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchDifferentSeedsDifferentEvents(t *testing.T) {
+	script := func() (Strategy, Strategy) {
+		return &ScriptedStrategy{Script: repeatScript(40, "Jab", "Hook")}, &ScriptedStrategy{Script: repeatScript(40, "Cross", "Front Kick")}
+	}
+	s1, s2 := script()
+	first, _ := playMatch(1, s1, s2, Rules{})
+	s1, s2 = script()
+	second, _ := playMatch(2, s1, s2, Rules{})
+	if reflect.DeepEqual(first, second) {
+		t.Fatal("different seeds produced the same events")
+	}
+}
+
+func TestStepReturnsTurnEvents(t *testing.T) {
+	for _, rules := range []Rules{{}, DefaultRules()} {
+		match, log := newTestMatch(7, &RandomStrategy{}, &RandomStrategy{}, rules)
+		names := [2]string{"Striker", "Grappler"}
+		stepped := []event.Event{}
+		turn := 0
+		for !match.Over() {
+			turn++
+			events := match.Step()
+			if len(events) == 0 {
+				t.Fatalf("turn %d has no events", turn)
+			}
+			// The round starts before the turn in it
+			started := events[0]
+			if _, ok := started.(event.RoundStarted); ok {
+				started = events[1]
+			}
+			side := (turn - 1) % 2
+			want := event.TurnStarted{Turn: turn, Side: side, Attacker: names[side], Defender: names[1-side]}
+			if started != want {
+				t.Fatalf("turn %d starts with %v, want %v", turn, started, want)
+			}
+			stepped = append(stepped, events...)
+		}
+		if !reflect.DeepEqual(stepped, []event.Event(*log)) {
+			t.Errorf("steps with %d rounds returned %d events, the subscriber got %d", rules.Rounds, len(stepped), len(*log))
+		}
+		if events := match.Step(); events != nil {
+			t.Errorf("finished match played another turn with %d events", len(events))
+		}
+		if result := match.Result(); result == nil || result.Turn != turn || match.Turn() != turn+1 || result.Winner != match.Winner() {
+			t.Errorf("result is %v after turn %d, want the last turn and the winner", result, turn)
+		}
+	}
+}
+
+// sureAttacks returns the catalog with the attacks that almost always land and the Leg Sweep that almost always knocks down
+func sureAttacks() *attack.Registry {
+	attacks := attack.NewAttacks()
+	attacks.AddAttack(&attack.Attack{Name: "Leg Sweep", Type: attack.Kick, Damage: 5, Complexity: 0, HitChance: 99, BlockChance: 0, CriticalChance: 5,
+		Specials: []attack.Special{{Condition: modifiers.Prone, Chance: 95}}})
+	attacks.AddAttack(&attack.Attack{Name: "Jab", Type: attack.Punch, Damage: 5, Complexity: 0, HitChance: 99, BlockChance: 0, CriticalChance: 5, SpecialChance: 5})
+	return attack.NewStaticRegistry(attacks)
+}
+
+func TestProneCantChain(t *testing.T) {
+	catalog := sureAttacks()
+	sweeper := &ScriptedStrategy{Script: repeatScript(30, "Leg Sweep"), Catalog: catalog}
+	victim := &ScriptedStrategy{Script: repeatScript(30, "Jab"), Stances: []attack.Stance{attack.Counter}, Catalog: catalog}
+	events, _ := playMatch(3, sweeper, victim, Rules{})
+
+	side, knockdowns, skipped, skippedInRow := 0, 0, 0, 0
+	for _, e := range events {
+		switch e := e.(type) {
+		case event.TurnStarted:
+			side = e.Side
+		case event.SpecialApplied:
+			if e.Condition == modifiers.Prone {
+				knockdowns++
+			}
+		case event.TurnSkipped:
+			if side != 1 {
+				t.Fatalf("sweeper skipped the turn due to %s", e.Condition)
+			}
+			skipped++
+			skippedInRow++
+			if skippedInRow > 1 {
+				t.Fatal("victim skipped two turns in a row, Prone chained")
+			}
+		case event.AttackAttempted:
+			if side == 1 {
+				skippedInRow = 0
+			}
+		}
+	}
+	if knockdowns < 3 || skipped != knockdowns {
+		t.Errorf("victim was knocked down %d times and skipped %d turns, want every knockdown to skip a single turn", knockdowns, skipped)
+	}
+}
+
+func TestSkippedTurnDropsStance(t *testing.T) {
+	attacks := attack.NewAttacks()
+	attacks.AddAttack(&attack.Attack{Name: "Wild Swing", Type: attack.Punch, Damage: 20, Complexity: 0, HitChance: 1, BlockChance: 0, CriticalChance: 5, SpecialChance: 5})
+	catalog := attack.NewStaticRegistry(attacks)
+
+	for _, condition := range []modifiers.Condition{modifiers.Paralysed, modifiers.Prone} {
+		t.Run(condition.String(), func(t *testing.T) {
+			for seed := int64(1); seed <= 20; seed++ {
+				match, log := newTestMatch(seed, &ScriptedStrategy{Script: repeatScript(2, "Wild Swing"), Catalog: catalog}, &ScriptedStrategy{}, Rules{})
+				match.Step()
+				// The defender took the counter stance in the previous turn and can't act in this one
+				_, defender := match.Fighters()
+				defender.Stance = attack.Counter
+				defender.Conditions[condition] = 1
+				*log = nil
+				match.Step()
+
+				want := event.StanceChosen{Fighter: "Grappler", Stance: attack.Block, Condition: condition}
+				found := false
+				for _, e := range *log {
+					found = found || e == want
+				}
+				if !found || defender.Stance != attack.Block {
+					t.Fatalf("defender skipped the turn in the %s stance, events %v", defender.Stance, *log)
+				}
+
+				match.Step()
+				for _, e := range *log {
+					if damage, ok := e.(event.DamageDealt); ok && damage.Counter {
+						t.Fatalf("seed %d: defender countered after the skipped turn", seed)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/fighter"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+// Method tells how the match ended
+type Method string
+
+const (
+	// KO is the knockout by the attack or the counter
+	KO Method = "KO"
+	// TKO is the technical knockout by the damage of the condition, like bleeding
+	TKO Method = "TKO"
+	// Decision is the win on the judges' scorecards after the last round
+	Decision Method = "Decision"
+	// Draw is the mutual knockout or the judges' draw
+	Draw Method = "Draw"
+	// Forfeit is the win after the opponent threw in the towel
+	Forfeit Method = "Forfeit"
+)
+
+// Methods lists all methods in the order they are reported
+var Methods = []Method{KO, TKO, Decision, Draw, Forfeit}
+
+// FighterStats holds the final state of the fighter and what the fighter did in the match
+type FighterStats struct {
+	Name       string
+	Health     int
+	MaxHealth  int
+	Stamina    int
+	MaxStamina int
+	// Attacks is the number of the attempted attacks, Landed is the number of them that dealt damage
+	Attacks int
+	Landed  int
+	// DamageDealt includes the counters and the damage of the conditions the fighter caused
+	DamageDealt int
+}
+
+// MatchResult is the outcome of the finished match
+type MatchResult struct {
+	// Winner is nil for the draw, Side is the index of the winner in the match or -1 for the draw
+	Winner *fighter.Fighter
+	Side   int
+	Method Method
+	// Condition is the condition that caused the TKO
+	Condition modifiers.Condition
+	// Turn is the last played turn and Round is its round, or 0 if the match has no rounds
+	Turn  int
+	Round int
+	// Decision is set if the match went to the judges' scorecards
+	Decision *event.Decision
+	Stats    [2]FighterStats
+}
+
+// tally is a Subscriber that counts the attacks and the damage of both sides for the match result
+type tally struct {
+	side    int
+	attacks [2]int
+	landed  [2]int
+	damage  [2]int
+}
+
+func (t *tally) Notify(e event.Event) {
+	switch e := e.(type) {
+	case event.TurnStarted:
+		t.side = e.Side
+	case event.AttackAttempted:
+		t.attacks[t.side]++
+	case event.DamageDealt:
+		if e.Amount <= 0 {
+			return
+		}
+		if e.Attack != "" {
+			t.landed[t.side]++
+		}
+		t.damage[damageSide(e, t.side)] += e.Amount
+	}
+}
+
+// finish ends the match with the winning side, or -1 for the draw, and builds its result
+func (m *Match) finish(side int, method Method, condition modifiers.Condition) {
+	result := &MatchResult{Side: side, Method: method, Condition: condition, Turn: m.turn, Round: m.Round(), Decision: m.decision}
+	if side >= 0 {
+		result.Winner = m.fighters[side]
+	}
+	for i, f := range m.fighters {
+		result.Stats[i] = FighterStats{
+			Name:        f.Name,
+			Health:      f.CurrentHealth,
+			MaxHealth:   f.MaxHealth,
+			Stamina:     f.CurrentStamina,
+			MaxStamina:  f.MaxStamina,
+			Attacks:     m.tally.attacks[i],
+			Landed:      m.tally.landed[i],
+			DamageDealt: m.tally.damage[i],
+		}
+	}
+	m.result = result
+}
+
+// String returns the summary of the result, like "Tom wins by TKO (Bleeding) in turn 28"
+func (r *MatchResult) String() string {
+	summary := "The fight is a draw"
+	if r.Winner != nil {
+		summary = fmt.Sprintf("%s wins by %s", r.Winner.Name, r.Method)
+	}
+	switch {
+	case r.Method == TKO:
+		summary += fmt.Sprintf(" (%s)", r.Condition)
+	case r.Decision != nil && r.Winner != nil:
+		summary += fmt.Sprintf(" (%s)", r.Decision.Type)
+	}
+	if r.Round > 0 {
+		return fmt.Sprintf("%s in round %d, turn %d", summary, r.Round, r.Turn)
+	}
+	return fmt.Sprintf("%s in turn %d", summary, r.Turn)
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+// firstHit returns the damage of the striker's Jab landing in the first turn of the match with the seed, or 0 if it doesn't land
+func firstHit(t *testing.T, seed int64) int {
+	t.Helper()
+	match, log := newTestMatch(seed, &ScriptedStrategy{Script: []string{"Jab"}, Catalog: sureAttacks()}, &ScriptedStrategy{}, Rules{})
+	match.Step()
+	for _, e := range *log {
+		if damage, ok := e.(event.DamageDealt); ok && damage.Attack != "" {
+			return damage.Amount
+		}
+	}
+	return 0
+}
+
+// landingSeed returns the seed of the match, in which the striker's first Jab lands, and its damage
+func landingSeed(t *testing.T) (int64, int) {
+	t.Helper()
+	for seed := int64(1); seed < 100; seed++ {
+		if damage := firstHit(t, seed); damage > 0 {
+			return seed, damage
+		}
+	}
+	t.Fatal("the Jab didn't land with any seed")
+	return 0, 0
+}
+
+func TestKnockOutAtZeroHealth(t *testing.T) {
+	seed, damage := landingSeed(t)
+	tests := []struct {
+		name   string
+		health int
+		over   bool
+	}{
+		{"exactly zero", damage, true},
+		{"one left", damage + 1, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			match, _ := newTestMatch(seed, &ScriptedStrategy{Script: repeatScript(2, "Jab"), Catalog: sureAttacks()}, &ScriptedStrategy{Script: []string{"Jab"}, Catalog: sureAttacks()}, Rules{})
+			_, grappler := match.Fighters()
+			grappler.CurrentHealth = test.health
+			match.Step()
+
+			if match.Over() != test.over {
+				t.Fatalf("match over is %v with %d health left, want %v", match.Over(), grappler.CurrentHealth, test.over)
+			}
+			if !test.over {
+				return
+			}
+			result := match.Result()
+			if result.Method != KO || result.Side != 0 || result.Winner.Name != "Striker" || result.Stats[1].Health != 0 {
+				t.Errorf("got %q with %d health left, want the Striker's KO at 0 health", result, result.Stats[1].Health)
+			}
+		})
+	}
+}
+
+func TestConditionKnockOut(t *testing.T) {
+	seed, damage := landingSeed(t)
+	tests := []struct {
+		name string
+		// grapplerHealth is the health of the Grappler, the Striker has 20 health left and bleeds 20 after its turn
+		grapplerHealth int
+		method         Method
+		side           int
+		summary        string
+	}{
+		{"bleed-out", damage + 1, TKO, 1, "Grappler wins by TKO (Bleeding) in turn 1"},
+		{"bleed-out after the knockout", damage, Draw, -1, "The fight is a draw in turn 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			match, log := newTestMatch(seed, &ScriptedStrategy{Script: []string{"Jab"}, Catalog: sureAttacks()}, &ScriptedStrategy{}, Rules{})
+			striker, grappler := match.Fighters()
+			striker.CurrentHealth = 20
+			striker.Conditions[modifiers.Bleeding] = 3
+			grappler.CurrentHealth = test.grapplerHealth
+			result := match.Run()
+
+			if result.Method != test.method || result.Side != test.side || result.String() != test.summary {
+				t.Errorf("got %q by %s for side %d, want %q", result, result.Method, result.Side, test.summary)
+			}
+			knockOuts := map[string]modifiers.Condition{}
+			for _, e := range *log {
+				if knockOut, ok := e.(event.KnockOut); ok {
+					knockOuts[knockOut.Fighter] = knockOut.Condition
+				}
+			}
+			if condition, ok := knockOuts["Striker"]; !ok || condition != modifiers.Bleeding {
+				t.Errorf("striker knockouts are %v, want the knockout by Bleeding", knockOuts)
+			}
+			if _, ok := knockOuts["Grappler"]; ok != (test.method == Draw) {
+				t.Errorf("grappler knockouts are %v", knockOuts)
+			}
+		})
+	}
+}
+
+func TestForfeit(t *testing.T) {
+	catalog := sureAttacks()
+	_, result := playMatch(1, &ScriptedStrategy{Script: repeatScript(2, "Jab"), Catalog: catalog}, &ScriptedStrategy{Script: []string{"Jab"}, Catalog: catalog}, Rules{})
+	if result.Method != Forfeit || result.Side != 0 || result.Turn != 4 {
+		t.Errorf("got %q by %s, want the Striker's win by forfeit in turn 4", result, result.Method)
+	}
+	if result.Stats[0].Attacks != 2 || result.Stats[1].Attacks != 1 {
+		t.Errorf("fighters attempted %d and %d attacks, want 2 and 1", result.Stats[0].Attacks, result.Stats[1].Attacks)
+	}
+}
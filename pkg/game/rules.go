@@ -0,0 +1,62 @@
+package game
+
+import "fmt"
+
+const (
+	DefaultRounds        int     = 3
+	DefaultTurnsPerRound int     = 20
+	DefaultRecovery      float64 = 0.25
+)
+
+// Rules are the professional match rules, the match without rounds lasts until the knockout
+type Rules struct {
+	// Rounds is the number of rounds, 0 means the match lasts until the knockout
+	Rounds int
+	// TurnsPerRound is the number of turns in every round, the turns of both fighters are counted
+	TurnsPerRound int
+	// Recovery is the part of the lost health and stamina the fighters get back between the rounds
+	Recovery float64
+	// Judges score the rounds, DefaultJudges are used if it's empty
+	Judges []Judge `json:",omitempty"`
+}
+
+// Judge scores the rounds by the points for every damage dealt, successful special and executed attack
+type Judge struct {
+	Name       string
+	Damage     float64
+	Specials   float64
+	Aggression float64
+}
+
+// DefaultJudges value the same things differently, so the close fights can end with the split decision
+var DefaultJudges = []Judge{
+	{Name: "Judge Hart", Damage: 1.5, Specials: 10, Aggression: 3},
+	{Name: "Judge Moreno", Damage: 1, Specials: 15, Aggression: 5},
+	{Name: "Judge Okafor", Damage: 0.75, Specials: 15, Aggression: 10},
+}
+
+// DefaultRules returns the rules of the professional fight
+func DefaultRules() Rules {
+	return Rules{Rounds: DefaultRounds, TurnsPerRound: DefaultTurnsPerRound, Recovery: DefaultRecovery}
+}
+
+// Validate checks that the rules can be played
+func (r Rules) Validate() error {
+	switch {
+	case r.Rounds < 0:
+		return fmt.Errorf("number of rounds can't be negative, got %d", r.Rounds)
+	case r.Rounds > 0 && r.TurnsPerRound < 1:
+		return fmt.Errorf("round should last at least 1 turn, got %d", r.TurnsPerRound)
+	case r.Recovery < 0 || r.Recovery > 1:
+		return fmt.Errorf("recovery should be between 0 and 1, got %v", r.Recovery)
+	}
+	return nil
+}
+
+// judges returns the judges of the match
+func (r Rules) judges() []Judge {
+	if len(r.Judges) == 0 {
+		return DefaultJudges
+	}
+	return r.Judges
+}
@@ -0,0 +1,380 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/fighter"
+	"github.com/zerobugdebug/cogfight/pkg/llm"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+const (
+	defaultRollouts   int = 30
+	defaultDepth          = 6
+	defaultCandidates     = 6
+	maxRandomRerolls      = 10
+)
+
+// Strategy chooses the attack for the fighter on its turn and the stance the fighter takes against the opponent's next attack.
+// ChooseAttack returns nil if the fighter forfeits the match.
+type Strategy interface {
+	ChooseAttack(m *Match, attacker, defender *fighter.Fighter) *attack.Attack
+	ChooseStance(m *Match, attacker, defender *fighter.Fighter) attack.Stance
+}
+
+// Difficulties lists the names of the built-in computer strategies, from the easiest to the hardest
+var Difficulties = []string{"random", "greedy", "tactical", "montecarlo"}
+
+// StrategyByName returns the built-in computer strategy for the difficulty name
+func StrategyByName(name string, catalog *attack.Registry) (Strategy, error) {
+	switch strings.ToLower(name) {
+	case "random":
+		return &RandomStrategy{Catalog: catalog}, nil
+	case "greedy":
+		return &GreedyStrategy{Catalog: catalog}, nil
+	case "tactical":
+		return &TacticalStrategy{Catalog: catalog}, nil
+	case "montecarlo":
+		return &MonteCarloStrategy{Catalog: catalog}, nil
+	default:
+		return nil, fmt.Errorf("unknown difficulty %q, should be one of: %s", name, strings.Join(Difficulties, ", "))
+	}
+}
+
+// RandomStrategy picks a random attack from the catalog, or from the default attacks if Catalog is not set
+type RandomStrategy struct {
+	Catalog *attack.Registry
+}
+
+// ChooseAttack returns a random attack
+func (s *RandomStrategy) ChooseAttack(m *Match, attacker, defender *fighter.Fighter) *attack.Attack {
+	attacks := catalogAttacks(s.Catalog)
+	// Pick again if the attack can't be used against the defender, giving up after a few tries
+	randomAttack := attacks.GetRandomAttack(m.Rand())
+	for i := 0; i < maxRandomRerolls && !attacker.CanUse(defender, randomAttack); i++ {
+		randomAttack = attacks.GetRandomAttack(m.Rand())
+	}
+	return randomAttack
+}
+
+// ChooseStance returns a random stance
+func (s *RandomStrategy) ChooseStance(m *Match, attacker, defender *fighter.Fighter) attack.Stance {
+	return attack.Stances[m.Rand().Intn(len(attack.Stances))]
+}
+
+// PlayerStrategy asks the player to select the attack from the catalog in the terminal, custom attacks are available if Client is set
+type PlayerStrategy struct {
+	Catalog *attack.Registry
+	Client  llm.Client
+}
+
+// ChooseAttack prompts the player for the attack
+func (s *PlayerStrategy) ChooseAttack(m *Match, attacker, defender *fighter.Fighter) *attack.Attack {
+	return attacker.SelectAttack(defender, catalogAttacks(s.Catalog), s.Client)
+}
+
+// ChooseStance prompts the player for the stance
+func (s *PlayerStrategy) ChooseStance(m *Match, attacker, defender *fighter.Fighter) attack.Stance {
+	return attacker.SelectStance(defender)
+}
+
+// GreedyStrategy picks the attack with the highest expected damage against the defender
+type GreedyStrategy struct {
+	Catalog *attack.Registry
+}
+
+// ChooseAttack returns the attack with the highest expected damage
+func (s *GreedyStrategy) ChooseAttack(m *Match, attacker, defender *fighter.Fighter) *attack.Attack {
+	return bestAttack(candidateAttacks(catalogAttacks(s.Catalog), attacker, defender), func(a *attack.Attack) float64 {
+		return attacker.ExpectedDamage(defender, a)
+	})
+}
+
+// ChooseStance returns the stance that minimizes the damage of the opponent's best attack
+func (s *GreedyStrategy) ChooseStance(m *Match, attacker, defender *fighter.Fighter) attack.Stance {
+	return bestStance(catalogAttacks(s.Catalog), attacker, defender)
+}
+
+// TacticalStrategy picks the attack by the expected damage and the value of its special in the current situation.
+// It doesn't waste specials on conditions the defender already has and presses the attack while the defender can't respond.
+type TacticalStrategy struct {
+	Catalog *attack.Registry
+}
+
+// ChooseAttack returns the attack with the best expected damage and special value
+func (s *TacticalStrategy) ChooseAttack(m *Match, attacker, defender *fighter.Fighter) *attack.Attack {
+	attacks := catalogAttacks(s.Catalog)
+	return bestAttack(candidateAttacks(attacks, attacker, defender), tacticalScore(attacks, attacker, defender))
+}
+
+// ChooseStance returns the stance that minimizes the damage of the opponent's best attack
+func (s *TacticalStrategy) ChooseStance(m *Match, attacker, defender *fighter.Fighter) attack.Stance {
+	return bestStance(catalogAttacks(s.Catalog), attacker, defender)
+}
+
+// tacticalScore returns the function to rate the attacks for the attacker in the current situation
+func tacticalScore(attacks *attack.Attacks, attacker, defender *fighter.Fighter) func(a *attack.Attack) float64 {
+	// Damage the fighters are able to deal to each other, used to value the conditions
+	attackerDamage := maxExpectedDamage(candidateAttacks(attacks, attacker, defender), attacker, defender)
+	defenderDamage := maxExpectedDamage(candidateAttacks(attacks, defender, attacker), defender, attacker)
+
+	// While the defender can't attack back or can't avoid the attack, the raw damage is all that matters
+	pressing := false
+	for _, condition := range modifiers.SortedConditions(defender.Conditions) {
+		definition := modifiers.Lookup(condition)
+		if definition.Effect(modifiers.SureStrike) != 0 || (definition.Effect(modifiers.SkipTurn) != 0 && defender.Conditions[condition] > 0) {
+			pressing = true
+		}
+	}
+
+	return func(a *attack.Attack) float64 {
+		expectedDamage := attacker.ExpectedDamage(defender, a)
+		if pressing {
+			return expectedDamage
+		}
+		modifiedAttack := attacker.ModifiedAttack(defender, a)
+		landChance := (100 - modifiedAttack.Complexity) / 100 * modifiedAttack.HitChance / 100 * (100 - modifiedAttack.BlockChance) / 100
+		score := expectedDamage
+		for _, special := range modifiedAttack.SpecialChances() {
+			score += landChance * special.Chance / 100 * conditionValue(special.Condition, defender, attackerDamage, defenderDamage)
+		}
+		return score
+	}
+}
+
+// conditionValue estimates how much damage the condition is worth over its duration when applied to the defender
+func conditionValue(condition modifiers.Condition, defender *fighter.Fighter, attackerDamage, defenderDamage float64) float64 {
+	if !defender.Affectable(condition) {
+		return 0
+	}
+	definition := modifiers.Lookup(condition)
+	attributes := definition.Effects
+	duration := float64(definition.Duration)
+	value := -float64(attributes[modifiers.HPPerTurn]) * duration
+	// The attacker is the defender's opponent, so it gets the opponent health effect
+	value += float64(attributes[modifiers.MyHPPerTurn]) * duration
+	if attributes[modifiers.SkipTurn] != 0 {
+		value += defenderDamage * duration
+	}
+	if attributes[modifiers.SureStrike] != 0 {
+		value += attackerDamage * duration
+	}
+	// Penalties for the defender and bonuses for its opponent
+	value -= float64(attributes[modifiers.HitChance]+attributes[modifiers.Damage]-attributes[modifiers.Complexity]) / 100 * defenderDamage * duration
+	value -= float64(attributes[modifiers.BlockChance]) / 100 * attackerDamage * duration
+	value += float64(attributes[modifiers.OpponentHitChance]+attributes[modifiers.OpponentBlockChance]) / 100 * attackerDamage * duration
+	return value
+}
+
+// MonteCarloStrategy picks the attack by simulating the next turns of the match many times for the most promising attacks
+type MonteCarloStrategy struct {
+	Catalog *attack.Registry
+	// Rollouts is the number of simulations per attack
+	Rollouts int
+	// Depth is the number of turns to simulate
+	Depth int
+	// Candidates is the number of the best tactical attacks to simulate
+	Candidates int
+}
+
+// ChooseAttack returns the attack with the best average outcome of the simulations
+func (s *MonteCarloStrategy) ChooseAttack(m *Match, attacker, defender *fighter.Fighter) *attack.Attack {
+	rollouts, depth, candidatesNum := s.Rollouts, s.Depth, s.Candidates
+	if rollouts <= 0 {
+		rollouts = defaultRollouts
+	}
+	if depth <= 0 {
+		depth = defaultDepth
+	}
+	if candidatesNum <= 0 {
+		candidatesNum = defaultCandidates
+	}
+
+	// Only simulate the most promising attacks
+	attacks := catalogAttacks(s.Catalog)
+	candidates := candidateAttacks(attacks, attacker, defender)
+	score := tacticalScore(attacks, attacker, defender)
+	scores := make(map[*attack.Attack]float64, len(candidates))
+	for _, candidate := range candidates {
+		scores[candidate] = score(candidate)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return scores[candidates[i]] > scores[candidates[j]] })
+	if len(candidates) > candidatesNum {
+		candidates = candidates[:candidatesNum]
+	}
+
+	return bestAttack(candidates, func(a *attack.Attack) float64 {
+		total := 0.0
+		for i := 0; i < rollouts; i++ {
+			total += s.rollout(m.Rand().Int63(), a, attacker, defender, depth)
+		}
+		return total / float64(rollouts)
+	})
+}
+
+// ChooseStance returns the stance that minimizes the damage of the opponent's best attack
+func (s *MonteCarloStrategy) ChooseStance(m *Match, attacker, defender *fighter.Fighter) attack.Stance {
+	return bestStance(catalogAttacks(s.Catalog), attacker, defender)
+}
+
+// rollout simulates the match starting with the attack and returns the outcome for the attacker, from -2 (lost) to 2 (won)
+func (s *MonteCarloStrategy) rollout(seed int64, first *attack.Attack, attacker, defender *fighter.Fighter, depth int) float64 {
+	simAttacker := attacker.Clone()
+	simDefender := defender.Clone()
+	// The fighters keep their stances in the simulation, choosing them is too slow for the rollouts
+	greedy := &keepStanceStrategy{Strategy: &GreedyStrategy{Catalog: s.Catalog}}
+	simulation := NewMatch(simAttacker, simDefender, &firstAttackStrategy{first: first, then: greedy}, greedy, seed)
+	for i := 0; i < depth && !simulation.Over(); i++ {
+		simulation.Step()
+	}
+
+	outcome := math.Max(float64(simAttacker.CurrentHealth), 0)/float64(simAttacker.MaxHealth) - math.Max(float64(simDefender.CurrentHealth), 0)/float64(simDefender.MaxHealth)
+	if simulation.Over() {
+		switch simulation.Winner() {
+		case simAttacker:
+			outcome++
+		case simDefender:
+			outcome--
+		}
+	}
+	return outcome
+}
+
+// firstAttackStrategy plays the first attack and then follows the other strategy
+type firstAttackStrategy struct {
+	first  *attack.Attack
+	then   Strategy
+	played bool
+}
+
+func (s *firstAttackStrategy) ChooseAttack(m *Match, attacker, defender *fighter.Fighter) *attack.Attack {
+	if !s.played {
+		s.played = true
+		return s.first
+	}
+	return s.then.ChooseAttack(m, attacker, defender)
+}
+
+func (s *firstAttackStrategy) ChooseStance(m *Match, attacker, defender *fighter.Fighter) attack.Stance {
+	return s.then.ChooseStance(m, attacker, defender)
+}
+
+// keepStanceStrategy chooses the attacks with the wrapped strategy, but never changes the stance
+type keepStanceStrategy struct {
+	Strategy
+}
+
+func (s *keepStanceStrategy) ChooseStance(m *Match, attacker, defender *fighter.Fighter) attack.Stance {
+	return attacker.Stance
+}
+
+// ScriptedStrategy plays the attacks from the script in order, one per turn, and takes the stances from the Stances script.
+// A single script can be shared by both fighters to play back the choices of the whole match.
+type ScriptedStrategy struct {
+	Script     []string
+	Stances    []attack.Stance
+	Catalog    *attack.Registry
+	next       int
+	nextStance int
+}
+
+// Done reports whether all scripted attacks were played
+func (s *ScriptedStrategy) Done() bool {
+	return s.next >= len(s.Script)
+}
+
+// ChooseAttack returns the next scripted attack, looked up in the attacker's custom attacks first and then in the catalog.
+// It returns nil, forfeiting the match, when all scripted attacks were played.
+func (s *ScriptedStrategy) ChooseAttack(m *Match, attacker, defender *fighter.Fighter) *attack.Attack {
+	if s.Done() {
+		return nil
+	}
+	name := s.Script[s.next]
+	s.next++
+	for _, customAttack := range attacker.CustomAttacks {
+		if customAttack.Name == name {
+			return customAttack
+		}
+	}
+	return catalogAttacks(s.Catalog).GetAttackByName(name)
+}
+
+// ChooseStance returns the next scripted stance, or keeps the current stance if there are no more stances in the script
+func (s *ScriptedStrategy) ChooseStance(m *Match, attacker, defender *fighter.Fighter) attack.Stance {
+	if s.nextStance >= len(s.Stances) {
+		return attacker.Stance
+	}
+	stance := s.Stances[s.nextStance]
+	s.nextStance++
+	return stance
+}
+
+// catalogAttacks returns the current attacks of the catalog, or the default attacks if the catalog is not set
+func catalogAttacks(catalog *attack.Registry) *attack.Attacks {
+	if catalog == nil {
+		return attack.DefaultRegistry().Attacks()
+	}
+	return catalog.Attacks()
+}
+
+// candidateAttacks returns all attacks the attacker can use against the defender, or all attacks if none of them can be used
+func candidateAttacks(attacks *attack.Attacks, attacker, defender *fighter.Fighter) []*attack.Attack {
+	all := append(attacks.List(), attacker.CustomAttacks...)
+	candidates := []*attack.Attack{}
+	for _, candidate := range all {
+		if attacker.CanUse(defender, candidate) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	if len(candidates) == 0 {
+		return all
+	}
+	return candidates
+}
+
+// bestAttack returns the attack with the highest score, the first one wins the ties
+func bestAttack(candidates []*attack.Attack, score func(a *attack.Attack) float64) *attack.Attack {
+	var best *attack.Attack
+	bestScore := math.Inf(-1)
+	for _, candidate := range candidates {
+		if candidateScore := score(candidate); candidateScore > bestScore {
+			best = candidate
+			bestScore = candidateScore
+		}
+	}
+	return best
+}
+
+// bestStance returns the stance of the fighter that minimizes the expected damage of the opponent's best attack
+// minus the damage the fighter would return with the counter, the first stance wins the ties
+func bestStance(attacks *attack.Attacks, f, opponent *fighter.Fighter) attack.Stance {
+	best := f.Stance
+	bestScore := math.Inf(1)
+	for _, stance := range attack.Stances {
+		// The copy is only read, so it can share the maps with the fighter
+		defender := *f
+		defender.Stance = stance
+		score := 0.0
+		for _, candidate := range candidateAttacks(attacks, opponent, &defender) {
+			score = math.Max(score, opponent.ExpectedDamage(&defender, candidate)-defender.ExpectedCounterDamage(opponent, candidate))
+		}
+		if score < bestScore {
+			best = stance
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// maxExpectedDamage returns the highest expected damage of the attacks
+func maxExpectedDamage(candidates []*attack.Attack, attacker, defender *fighter.Fighter) float64 {
+	maxDamage := 0.0
+	for _, candidate := range candidates {
+		maxDamage = math.Max(maxDamage, attacker.ExpectedDamage(defender, candidate))
+	}
+	return maxDamage
+}
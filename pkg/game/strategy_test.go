@@ -0,0 +1,127 @@
+package game
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+func TestStrategyByName(t *testing.T) {
+	tests := []struct {
+		name string
+		want Strategy
+		err  string
+	}{
+		{"random", &RandomStrategy{}, ""},
+		{"Greedy", &GreedyStrategy{}, ""},
+		{"TACTICAL", &TacticalStrategy{}, ""},
+		{"montecarlo", &MonteCarloStrategy{}, ""},
+		{"hard", nil, `unknown difficulty "hard", should be one of: random, greedy, tactical, montecarlo`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := StrategyByName(test.name, nil)
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Errorf("got error %v, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reflect.TypeOf(got) != reflect.TypeOf(test.want) {
+				t.Errorf("got %T, want %T", got, test.want)
+			}
+		})
+	}
+	for _, name := range Difficulties {
+		if _, err := StrategyByName(name, nil); err != nil {
+			t.Errorf("difficulty %s: %s", name, err)
+		}
+	}
+}
+
+// strategyAttacks returns the catalog with the weak Tap, the strong Haymaker and the Leg Sweep that deals little damage but knocks down
+func strategyAttacks() *attack.Registry {
+	noSpecial := []attack.Special{{Condition: modifiers.Insulted, Chance: 0}}
+	attacks := attack.NewAttacks()
+	attacks.AddAttack(&attack.Attack{Name: "Tap", Type: attack.Punch, Damage: 12, Complexity: 0, HitChance: 99, BlockChance: 0, CriticalChance: 5, Specials: noSpecial})
+	attacks.AddAttack(&attack.Attack{Name: "Haymaker", Type: attack.Punch, Damage: 60, Complexity: 0, HitChance: 99, BlockChance: 0, CriticalChance: 5, Specials: noSpecial})
+	attacks.AddAttack(&attack.Attack{Name: "Leg Sweep", Type: attack.Kick, Damage: 10, Complexity: 0, HitChance: 99, BlockChance: 0, CriticalChance: 5,
+		Specials: []attack.Special{{Condition: modifiers.Prone, Chance: 95}}})
+	return attack.NewStaticRegistry(attacks)
+}
+
+func TestStrategyChooseAttack(t *testing.T) {
+	catalog := strategyAttacks()
+	tests := []struct {
+		name     string
+		strategy Strategy
+		// condition is put on the defender before the choice
+		condition modifiers.Condition
+		want      string
+	}{
+		{"greedy", &GreedyStrategy{Catalog: catalog}, modifiers.Healthy, "Haymaker"},
+		{"greedy ignores the specials", &GreedyStrategy{Catalog: catalog}, modifiers.Prone, "Haymaker"},
+		{"tactical", &TacticalStrategy{Catalog: catalog}, modifiers.Healthy, "Haymaker"},
+		{"montecarlo", &MonteCarloStrategy{Catalog: catalog, Rollouts: 10, Depth: 4}, modifiers.Healthy, "Haymaker"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			match, _ := newTestMatch(1, test.strategy, &ScriptedStrategy{}, Rules{})
+			attacker, defender := match.Fighters()
+			if test.condition != modifiers.Healthy {
+				defender.Conditions[test.condition] = 1
+			}
+			got := test.strategy.ChooseAttack(match, attacker, defender)
+			if got == nil || got.Name != test.want {
+				t.Errorf("chose %v, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTacticalValuesSpecials(t *testing.T) {
+	// The Tap deals a little more damage than the Leg Sweep, so only the value of the knockdown makes the Leg Sweep better
+	noSpecial := []attack.Special{{Condition: modifiers.Insulted, Chance: 0}}
+	attacks := attack.NewAttacks()
+	attacks.AddAttack(&attack.Attack{Name: "Tap", Type: attack.Punch, Damage: 12, Complexity: 0, HitChance: 99, BlockChance: 0, CriticalChance: 5, Specials: noSpecial})
+	attacks.AddAttack(&attack.Attack{Name: "Leg Sweep", Type: attack.Kick, Damage: 10, Complexity: 0, HitChance: 99, BlockChance: 0, CriticalChance: 5,
+		Specials: []attack.Special{{Condition: modifiers.Prone, Chance: 95}}})
+	catalog := attack.NewStaticRegistry(attacks)
+
+	tests := []struct {
+		name   string
+		setup  func(m *Match)
+		greedy string
+		want   string
+	}{
+		{"healthy defender", func(m *Match) {}, "Tap", "Leg Sweep"},
+		{"knocked down defender", func(m *Match) {
+			_, defender := m.Fighters()
+			defender.Conditions[modifiers.Prone] = 1
+		}, "Tap", "Tap"},
+		{"immune defender", func(m *Match) {
+			_, defender := m.Fighters()
+			defender.Immunities = map[modifiers.Condition]int{modifiers.Prone: 1}
+		}, "Tap", "Tap"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			match, _ := newTestMatch(1, &ScriptedStrategy{}, &ScriptedStrategy{}, Rules{})
+			test.setup(match)
+			attacker, defender := match.Fighters()
+			choices := []string{}
+			for _, strategy := range []Strategy{&GreedyStrategy{Catalog: catalog}, &TacticalStrategy{Catalog: catalog}} {
+				choices = append(choices, strategy.ChooseAttack(match, attacker, defender).Name)
+			}
+			if want := []string{test.greedy, test.want}; !reflect.DeepEqual(choices, want) {
+				t.Errorf("greedy and tactical chose %s, want %s", strings.Join(choices, " and "), strings.Join(want, " and "))
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+var (
+	// ErrProxyUnavailable means the proxy or the model server can't be reached or is failing at the moment, the request can be retried
+	ErrProxyUnavailable = errors.New("LLM server unavailable")
+	// ErrTimeout means the reply didn't arrive in time, the request can be retried
+	ErrTimeout = errors.New("LLM request timed out")
+	// ErrProtocol means the server replied with something the client doesn't understand
+	ErrProtocol = errors.New("LLM protocol error")
+)
+
+// newError wraps the cause into the error of the kind.
+// Cancellations and deadlines of the context take precedence over the kind, so the caller can tell them apart.
+func newError(ctx context.Context, kind error, message string, cause error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		kind = context.Canceled
+	case errors.Is(ctx.Err(), context.DeadlineExceeded), errors.As(cause, &netErr) && netErr.Timeout():
+		kind = ErrTimeout
+	}
+	if cause == nil {
+		return fmt.Errorf("%w: %s", kind, message)
+	}
+	return fmt.Errorf("%w: %s: %v", kind, message, cause)
+}
+
+// retryable reports whether the failed request may succeed if sent again
+func retryable(err error) bool {
+	return errors.Is(err, ErrProxyUnavailable) || errors.Is(err, ErrTimeout)
+}
@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWSProxyClientErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply func(conn *websocket.Conn, request proxyRequestData)
+		want  error
+	}{
+		{"missing end marker", func(conn *websocket.Conn, request proxyRequestData) {
+			sendFrames(conn, "Jab ")
+		}, ErrTimeout},
+		{"proxy timeout", func(conn *websocket.Conn, request proxyRequestData) {
+			sendFrames(conn, `{"message": "Endpoint request timed out"}`)
+		}, ErrTimeout},
+		{"binary frame", func(conn *websocket.Conn, request proxyRequestData) {
+			conn.WriteMessage(websocket.BinaryMessage, []byte{0xde, 0xad})
+		}, ErrProtocol},
+		{"connection closed", func(conn *websocket.Conn, request proxyRequestData) {
+			sendFrames(conn, "Jab ")
+			conn.Close()
+		}, ErrProxyUnavailable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, _ := proxyStub(t, test.reply)
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			client := &WSProxyClient{URL: wsURL(server)}
+			_, err := client.Complete(ctx, testRequest(), nil)
+			if !errors.Is(err, test.want) {
+				t.Errorf("error %v, want %v", err, test.want)
+			}
+			if client.conn != nil {
+				t.Error("connection kept after the failure")
+			}
+		})
+	}
+}
+
+func TestWSProxyClientDeadProxy(t *testing.T) {
+	server, _ := proxyStub(t, func(conn *websocket.Conn, request proxyRequestData) {})
+	url := wsURL(server)
+	server.Close()
+	_, err := (&WSProxyClient{URL: url}).Complete(context.Background(), testRequest(), nil)
+	if !errors.Is(err, ErrProxyUnavailable) {
+		t.Errorf("error %v, want %v", err, ErrProxyUnavailable)
+	}
+}
+
+func TestHTTPClientsDeadServer(t *testing.T) {
+	server := sseStub(t, 200)
+	url := server.URL
+	server.Close()
+	clients := map[string]Client{"openai": newTestOpenAIClient(url), "local": newTestLocalClient(url)}
+	for name, client := range clients {
+		_, err := client.Complete(context.Background(), testRequest(), nil)
+		if !errors.Is(err, ErrProxyUnavailable) {
+			t.Errorf("%s: error %v, want %v", name, err, ErrProxyUnavailable)
+		}
+	}
+}
+
+func TestCancelledRequest(t *testing.T) {
+	server, _ := proxyStub(t, func(conn *websocket.Conn, request proxyRequestData) {})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err := (&WSProxyClient{URL: wsURL(server)}).Complete(ctx, testRequest(), nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,190 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultConfigFile string = "llm.json"
+	defaultBackend           = "wsproxy"
+)
+
+// Backends lists the names of the supported LLM backends
+var Backends = []string{"wsproxy", "openai", "local"}
+
+// Message is a single message of the chat with the model
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Request asks the model to continue the chat using the prompt
+type Request struct {
+	// Prompt is the name of the prompt template, e.g. COG_TURN_COMMENT_PROMPT
+	Prompt   string
+	Messages []Message
+	// JSON asks the model to reply with a single JSON object
+	JSON bool
+}
+
+// Client sends the requests to the model.
+// Complete streams the reply to onChunk as it arrives, if set, and returns the whole reply.
+type Client interface {
+	Complete(ctx context.Context, request Request, onChunk func(chunk string)) (string, error)
+}
+
+// Config holds the settings of the LLM backend
+type Config struct {
+	// Backend is one of the Backends
+	Backend string `json:"backend"`
+	// URL is the websocket proxy URL for wsproxy, or the server base URL for openai and local
+	URL    string `json:"url"`
+	APIKey string `json:"api_key"`
+	Model  string `json:"model"`
+	// Prompts holds the prompt templates by name, used by the backends that don't resolve them on the server
+	Prompts map[string]string `json:"prompts"`
+	// Attempts is the maximum number of attempts for every request, including the first one
+	Attempts int `json:"attempts"`
+	// Timeout limits every attempt
+	Timeout Duration `json:"timeout"`
+	// RetryBackoff is the delay before the first retry, doubled for every next one
+	RetryBackoff Duration `json:"retry_backoff"`
+}
+
+// Duration is the time.Duration written as a string like "30s" in the config
+type Duration time.Duration
+
+// UnmarshalJSON parses the duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	err := json.Unmarshal(data, &text)
+	if err != nil {
+		return fmt.Errorf("duration should be a string like \"30s\": %v", err)
+	}
+	duration, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// LoadConfig reads the config from the JSON file and applies the environment variables on top.
+// A missing default config file is not an error, the environment variables and the defaults are used instead.
+//
+// Environment variables: COG_LLM_BACKEND, COG_LLM_URL, COG_LLM_API_KEY (or OPENAI_API_KEY), COG_LLM_MODEL and
+// OPENAI_WSPROXY_URL for the wsproxy backend, COG_LLM_ATTEMPTS and COG_LLM_TIMEOUT.
+func LoadConfig(filename string) (*Config, error) {
+	config := &Config{}
+	if filename == "" {
+		filename = defaultConfigFile
+	}
+	configJSON, err := os.ReadFile(filename)
+	switch {
+	case err == nil:
+		err = json.Unmarshal(configJSON, config)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding LLM config %s: %s", filename, err)
+		}
+	case errors.Is(err, os.ErrNotExist) && filename == defaultConfigFile:
+	default:
+		return nil, fmt.Errorf("error reading LLM config: %s", err)
+	}
+
+	setFromEnv(&config.Backend, "COG_LLM_BACKEND")
+	setFromEnv(&config.URL, "COG_LLM_URL")
+	setFromEnv(&config.APIKey, "OPENAI_API_KEY")
+	setFromEnv(&config.APIKey, "COG_LLM_API_KEY")
+	setFromEnv(&config.Model, "COG_LLM_MODEL")
+	if attempts := os.Getenv("COG_LLM_ATTEMPTS"); attempts != "" {
+		config.Attempts, err = strconv.Atoi(attempts)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing COG_LLM_ATTEMPTS: %s", err)
+		}
+	}
+	if timeout := os.Getenv("COG_LLM_TIMEOUT"); timeout != "" {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing COG_LLM_TIMEOUT: %s", err)
+		}
+		config.Timeout = Duration(duration)
+	}
+
+	if config.Backend == "" {
+		config.Backend = defaultBackend
+	}
+	if config.Attempts <= 0 {
+		config.Attempts = defaultAttempts
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = Duration(defaultTimeout)
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = Duration(defaultRetryBackoff)
+	}
+	if config.Backend == "wsproxy" {
+		setFromEnv(&config.URL, "OPENAI_WSPROXY_URL")
+	}
+	return config, nil
+}
+
+func setFromEnv(value *string, name string) {
+	if envValue := os.Getenv(name); envValue != "" {
+		*value = envValue
+	}
+}
+
+// Prompt returns the prompt template by name from the environment variable, the config or the built-in prompts
+func (c *Config) Prompt(name string) (string, error) {
+	if prompt := os.Getenv(name); prompt != "" {
+		return prompt, nil
+	}
+	if prompt, ok := c.Prompts[name]; ok {
+		return prompt, nil
+	}
+	if prompt, ok := defaultPrompts[name]; ok {
+		return prompt, nil
+	}
+	return "", fmt.Errorf("prompt %s not found in environment variables, LLM config or built-in prompts", name)
+}
+
+// NewClient creates the client for the configured backend, retrying the failed requests as configured
+func NewClient(config *Config) (Client, error) {
+	var client Client
+	switch strings.ToLower(config.Backend) {
+	case "wsproxy":
+		if config.URL == "" {
+			return nil, fmt.Errorf("OpenAI websocket proxy URL not found in environment variable OPENAI_WSPROXY_URL or LLM config")
+		}
+		client = &WSProxyClient{URL: config.URL}
+	case "openai":
+		client = &OpenAIClient{URL: config.URL, APIKey: config.APIKey, Model: config.Model, Config: config, HTTPClient: http.DefaultClient}
+	case "local":
+		client = &LocalClient{URL: config.URL, Model: config.Model, Config: config, HTTPClient: http.DefaultClient}
+	default:
+		return nil, fmt.Errorf("unknown LLM backend %q, should be one of: %s", config.Backend, strings.Join(Backends, ", "))
+	}
+	return &RetryClient{Client: client, Attempts: config.Attempts, Timeout: time.Duration(config.Timeout), Backoff: time.Duration(config.RetryBackoff)}, nil
+}
+
+// withSystemPrompt returns the request messages preceded by the resolved prompt as the system message
+func withSystemPrompt(config *Config, request Request) ([]Message, error) {
+	prompt, err := config.Prompt(request.Prompt)
+	if err != nil {
+		return nil, err
+	}
+	return append([]Message{{Role: "system", Content: prompt}}, request.Messages...), nil
+}
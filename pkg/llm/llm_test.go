@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets the environment variables read by LoadConfig for the test
+func clearEnv(t *testing.T) {
+	for _, name := range []string{"COG_LLM_BACKEND", "COG_LLM_URL", "COG_LLM_API_KEY", "OPENAI_API_KEY", "COG_LLM_MODEL", "OPENAI_WSPROXY_URL", "COG_LLM_ATTEMPTS", "COG_LLM_TIMEOUT"} {
+		t.Setenv(name, "")
+	}
+}
+
+// writeConfig writes the config file to the test directory and returns its name
+func writeConfig(t *testing.T, config string) string {
+	filename := filepath.Join(t.TempDir(), "llm.json")
+	if err := os.WriteFile(filename, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestLoadConfigFromFile(t *testing.T) {
+	clearEnv(t)
+	filename := writeConfig(t, `{"backend":"openai","url":"http://localhost:8080/v1","api_key":"file-key","model":"file-model",
+		"prompts":{"COG_TEST_PROMPT":"From the file"},"attempts":5,"timeout":"2s","retry_backoff":"100ms"}`)
+	config, err := LoadConfig(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{Backend: "openai", URL: "http://localhost:8080/v1", APIKey: "file-key", Model: "file-model", Attempts: 5,
+		Timeout: Duration(2 * time.Second), RetryBackoff: Duration(100 * time.Millisecond)}
+	want.Prompts = map[string]string{testPrompt: "From the file"}
+	if !reflect.DeepEqual(*config, want) {
+		t.Errorf("config %+v, want %+v", *config, want)
+	}
+	if prompt, err := config.Prompt(testPrompt); err != nil || prompt != "From the file" {
+		t.Errorf("prompt %q, error %v, want the prompt from the file", prompt, err)
+	}
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	clearEnv(t)
+	filename := writeConfig(t, `{"backend":"openai","api_key":"file-key","model":"file-model","attempts":5}`)
+	t.Setenv("COG_LLM_BACKEND", "local")
+	t.Setenv("COG_LLM_URL", "http://localhost:11434")
+	t.Setenv("OPENAI_API_KEY", "openai-key")
+	t.Setenv("COG_LLM_MODEL", "env-model")
+	t.Setenv("COG_LLM_ATTEMPTS", "2")
+	t.Setenv("COG_LLM_TIMEOUT", "5s")
+	config, err := LoadConfig(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{Backend: "local", URL: "http://localhost:11434", APIKey: "openai-key", Model: "env-model", Attempts: 2,
+		Timeout: Duration(5 * time.Second), RetryBackoff: Duration(defaultRetryBackoff)}
+	if !reflect.DeepEqual(*config, want) {
+		t.Errorf("config %+v, want %+v", *config, want)
+	}
+
+	// COG_LLM_API_KEY takes precedence over OPENAI_API_KEY
+	t.Setenv("COG_LLM_API_KEY", "cog-key")
+	config, err = LoadConfig(filename)
+	if err != nil || config.APIKey != "cog-key" {
+		t.Errorf("API key %q, error %v, want COG_LLM_API_KEY", config.APIKey, err)
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("OPENAI_WSPROXY_URL", "ws://localhost:9000")
+	config, err := LoadConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{Backend: defaultBackend, URL: "ws://localhost:9000", Attempts: defaultAttempts, Timeout: Duration(defaultTimeout), RetryBackoff: Duration(defaultRetryBackoff)}
+	if !reflect.DeepEqual(*config, want) {
+		t.Errorf("config %+v, want %+v", *config, want)
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		env    map[string]string
+		want   string
+	}{
+		{"missing file", "", nil, "error reading LLM config"},
+		{"bad JSON", `{"backend":`, nil, "error decoding LLM config"},
+		{"bad duration", `{"timeout":30}`, nil, "duration should be a string"},
+		{"bad attempts", `{}`, map[string]string{"COG_LLM_ATTEMPTS": "many"}, "error parsing COG_LLM_ATTEMPTS"},
+		{"bad timeout", `{}`, map[string]string{"COG_LLM_TIMEOUT": "soon"}, "error parsing COG_LLM_TIMEOUT"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+			filename := filepath.Join(t.TempDir(), "missing.json")
+			if test.config != "" {
+				filename = writeConfig(t, test.config)
+			}
+			_, err := LoadConfig(filename)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		config Config
+		want   string
+	}{
+		{Config{Backend: "wsproxy", URL: "ws://localhost:9000"}, ""},
+		{Config{Backend: "wsproxy"}, "websocket proxy URL not found"},
+		{Config{Backend: "OpenAI"}, ""},
+		{Config{Backend: "local"}, ""},
+		{Config{Backend: "carrier-pigeon"}, "unknown LLM backend"},
+	}
+	for _, test := range tests {
+		client, err := NewClient(&test.config)
+		switch {
+		case test.want == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", test.config.Backend, err)
+		case test.want == "" && client == nil:
+			t.Errorf("%s: no client", test.config.Backend)
+		case test.want != "" && (err == nil || !strings.Contains(err.Error(), test.want)):
+			t.Errorf("%s: error %v, want %q", test.config.Backend, err, test.want)
+		}
+	}
+}
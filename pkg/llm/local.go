@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultLocalURL   string = "http://localhost:11434"
+	defaultLocalModel        = "llama3"
+)
+
+// LocalClient talks to a local model server with the Ollama-compatible /api/chat endpoint, streaming the reply as JSON lines
+type LocalClient struct {
+	// URL is the server base URL, e.g. http://localhost:11434
+	URL        string
+	Model      string
+	Config     *Config
+	HTTPClient *http.Client
+}
+
+type localRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+	Stream   bool      `json:"stream"`
+	Format   string    `json:"format,omitempty"`
+}
+
+type localChunk struct {
+	Message Message `json:"message"`
+	Done    bool    `json:"done"`
+	Error   string  `json:"error"`
+}
+
+// Complete sends the request with the resolved prompt as the system message and reads the streamed reply
+func (c *LocalClient) Complete(ctx context.Context, request Request, onChunk func(chunk string)) (string, error) {
+	messages, err := withSystemPrompt(c.Config, request)
+	if err != nil {
+		return "", err
+	}
+	url, model := c.URL, c.Model
+	if url == "" {
+		url = defaultLocalURL
+	}
+	if model == "" {
+		model = defaultLocalModel
+	}
+
+	localRequestData := localRequest{Model: model, Messages: messages, Stream: true}
+	if request.JSON {
+		localRequestData.Format = "json"
+	}
+	body, err := json.Marshal(localRequestData)
+	if err != nil {
+		return "", fmt.Errorf("error encoding request to JSON: %v", err)
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(url, "/")+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %v", err)
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+
+	response, err := c.HTTPClient.Do(httpRequest)
+	if err != nil {
+		return "", newError(ctx, ErrProxyUnavailable, "error sending request to "+url, err)
+	}
+	defer closeBody(response)
+	if err := checkStatus(ctx, response); err != nil {
+		return "", err
+	}
+
+	result := ""
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var chunk localChunk
+		err := json.Unmarshal(line, &chunk)
+		if err != nil {
+			return "", newError(ctx, ErrProtocol, fmt.Sprintf("error decoding streamed chunk %q", line), err)
+		}
+		if chunk.Error != "" {
+			return "", newError(ctx, ErrProtocol, "error from the server: "+chunk.Error, nil)
+		}
+		if onChunk != nil && chunk.Message.Content != "" {
+			onChunk(chunk.Message.Content)
+		}
+		result += chunk.Message.Content
+		if chunk.Done {
+			return result, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", newError(ctx, ErrProxyUnavailable, "error reading streamed reply", err)
+	}
+	return "", newError(ctx, ErrProtocol, "stream ended before the reply was done", nil)
+}
@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// localStub returns the Ollama-compatible server that checks the request and streams the lines as the reply
+func localStub(t *testing.T, status int, lines ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("request path %s, want /api/chat", r.URL.Path)
+		}
+		var request localRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("error decoding request: %v", err)
+		}
+		if !request.Stream || request.Model != "test-model" || request.Format != "json" || request.Messages[0].Role != "system" {
+			t.Errorf("unexpected request %+v", request)
+		}
+		w.WriteHeader(status)
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// localChunkLine returns the streamed JSON line with the content
+func localChunkLine(content string, done bool) string {
+	line, _ := json.Marshal(localChunk{Message: Message{Role: "assistant", Content: content}, Done: done})
+	return string(line)
+}
+
+func newTestLocalClient(url string) *LocalClient {
+	return &LocalClient{URL: url, Model: "test-model", Config: testConfig(), HTTPClient: http.DefaultClient}
+}
+
+func jsonRequest() Request {
+	request := testRequest()
+	request.JSON = true
+	return request
+}
+
+func TestLocalClientStream(t *testing.T) {
+	server := localStub(t, http.StatusOK, localChunkLine(`{"name":`, false), "", localChunkLine(`"Jab"}`, false), localChunkLine("", true))
+	chunks := []string{}
+	result, err := newTestLocalClient(server.URL).Complete(context.Background(), jsonRequest(), func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != `{"name":"Jab"}` {
+		t.Errorf("result %q, want the joined chunks", result)
+	}
+	if !reflect.DeepEqual(chunks, []string{`{"name":`, `"Jab"}`}) {
+		t.Errorf("streamed chunks %q, want the non-empty chunks", chunks)
+	}
+}
+
+func TestLocalClientErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		lines  []string
+		want   error
+	}{
+		{"model not found", http.StatusNotFound, []string{`{"error":"model not found"}`}, ErrProtocol},
+		{"server error", http.StatusInternalServerError, nil, ErrProxyUnavailable},
+		{"non-JSON line", http.StatusOK, []string{"not json"}, ErrProtocol},
+		{"error line", http.StatusOK, []string{`{"error":"out of memory"}`}, ErrProtocol},
+		{"not done", http.StatusOK, []string{localChunkLine("Jab", false)}, ErrProtocol},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := localStub(t, test.status, test.lines...)
+			_, err := newTestLocalClient(server.URL).Complete(context.Background(), jsonRequest(), nil)
+			if !errors.Is(err, test.want) {
+				t.Errorf("error %v, want %v", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultOpenAIURL   string = "https://api.openai.com/v1"
+	defaultOpenAIModel        = "gpt-4o-mini"
+	sseDataPrefix             = "data:"
+	sseDone                   = "[DONE]"
+)
+
+// OpenAIClient talks to any OpenAI-compatible /chat/completions endpoint, streaming the reply with server-sent events
+type OpenAIClient struct {
+	// URL is the API base URL, e.g. https://api.openai.com/v1
+	URL        string
+	APIKey     string
+	Model      string
+	Config     *Config
+	HTTPClient *http.Client
+}
+
+type openAIRequest struct {
+	Model          string          `json:"model"`
+	Messages       []Message       `json:"messages"`
+	Stream         bool            `json:"stream"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+type responseFormat struct {
+	Type string `json:"type"`
+}
+
+type openAIChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Complete sends the request with the resolved prompt as the system message and reads the streamed reply
+func (c *OpenAIClient) Complete(ctx context.Context, request Request, onChunk func(chunk string)) (string, error) {
+	messages, err := withSystemPrompt(c.Config, request)
+	if err != nil {
+		return "", err
+	}
+	url, model := c.URL, c.Model
+	if url == "" {
+		url = defaultOpenAIURL
+	}
+	if model == "" {
+		model = defaultOpenAIModel
+	}
+
+	openAIRequestData := openAIRequest{Model: model, Messages: messages, Stream: true}
+	if request.JSON {
+		openAIRequestData.ResponseFormat = &responseFormat{Type: "json_object"}
+	}
+	body, err := json.Marshal(openAIRequestData)
+	if err != nil {
+		return "", fmt.Errorf("error encoding request to JSON: %v", err)
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(url, "/")+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %v", err)
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.Header.Set("Accept", "text/event-stream")
+	if c.APIKey != "" {
+		httpRequest.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	response, err := c.HTTPClient.Do(httpRequest)
+	if err != nil {
+		return "", newError(ctx, ErrProxyUnavailable, "error sending request to "+url, err)
+	}
+	defer closeBody(response)
+	if err := checkStatus(ctx, response); err != nil {
+		return "", err
+	}
+
+	result := ""
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// Skip the blank separators, comments and other SSE fields
+		if !strings.HasPrefix(line, sseDataPrefix) {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, sseDataPrefix))
+		if data == sseDone {
+			return result, nil
+		}
+		var chunk openAIChunk
+		err := json.Unmarshal([]byte(data), &chunk)
+		if err != nil {
+			return "", newError(ctx, ErrProtocol, fmt.Sprintf("error decoding streamed chunk %q", data), err)
+		}
+		if chunk.Error != nil {
+			return "", newError(ctx, ErrProtocol, "error from the server: "+chunk.Error.Message, nil)
+		}
+		for _, choice := range chunk.Choices {
+			if onChunk != nil && choice.Delta.Content != "" {
+				onChunk(choice.Delta.Content)
+			}
+			result += choice.Delta.Content
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", newError(ctx, ErrProxyUnavailable, "error reading streamed reply", err)
+	}
+	return "", newError(ctx, ErrProtocol, "stream ended without "+sseDone, nil)
+}
+
+// checkStatus returns the error for the unsuccessful response, the server errors and rate limits can be retried
+func checkStatus(ctx context.Context, response *http.Response) error {
+	if response.StatusCode == http.StatusOK {
+		return nil
+	}
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	kind := ErrProtocol
+	if response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError {
+		kind = ErrProxyUnavailable
+	}
+	return newError(ctx, kind, fmt.Sprintf("unexpected response status %s: %s", response.Status, strings.TrimSpace(string(message))), nil)
+}
+
+// closeBody reads the rest of the response body before closing it, so the connection can be reused for the next request
+func closeBody(response *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+	response.Body.Close()
+}
@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+const testPrompt string = "COG_TEST_PROMPT"
+
+// testConfig returns the config with the test prompt, so the backends don't depend on the environment
+func testConfig() *Config {
+	return &Config{Prompts: map[string]string{testPrompt: "You are the test commentator"}}
+}
+
+// testRequest returns the request for the test prompt
+func testRequest() Request {
+	return Request{Prompt: testPrompt, Messages: []Message{{Role: "user", Content: "Jab lands"}}}
+}
+
+// sseStub returns the OpenAI-compatible server that checks the request and streams the lines as the reply
+func sseStub(t *testing.T, status int, lines ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("request path %s, want /chat/completions", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Authorization header %q, want the bearer API key", got)
+		}
+		var request openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("error decoding request: %v", err)
+		}
+		if !request.Stream || request.Model != "test-model" || len(request.Messages) != 2 || request.Messages[0].Role != "system" {
+			t.Errorf("unexpected request %+v", request)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(status)
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// sseChunk returns the SSE data line with the streamed content
+func sseChunk(content string) string {
+	return fmt.Sprintf(`data: {"choices":[{"delta":{"content":%q}}]}`, content) + "\n"
+}
+
+func newTestOpenAIClient(url string) *OpenAIClient {
+	return &OpenAIClient{URL: url, APIKey: "test-key", Model: "test-model", Config: testConfig(), HTTPClient: http.DefaultClient}
+}
+
+func TestOpenAIClientStream(t *testing.T) {
+	server := sseStub(t, http.StatusOK, ": keep-alive", "", sseChunk("Hel"), sseChunk("lo"), "event: ping", sseChunk(""), "data: [DONE]", sseChunk("ignored"))
+	chunks := []string{}
+	result, err := newTestOpenAIClient(server.URL).Complete(context.Background(), testRequest(), func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "Hello" {
+		t.Errorf("result %q, want %q", result, "Hello")
+	}
+	if !reflect.DeepEqual(chunks, []string{"Hel", "lo"}) {
+		t.Errorf("streamed chunks %q, want the non-empty chunks before [DONE]", chunks)
+	}
+}
+
+func TestOpenAIClientErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		lines  []string
+		want   error
+	}{
+		{"bad request", http.StatusBadRequest, []string{`{"error":"bad model"}`}, ErrProtocol},
+		{"rate limit", http.StatusTooManyRequests, nil, ErrProxyUnavailable},
+		{"server error", http.StatusServiceUnavailable, nil, ErrProxyUnavailable},
+		{"non-JSON chunk", http.StatusOK, []string{sseChunk("Hel"), "data: not json"}, ErrProtocol},
+		{"error chunk", http.StatusOK, []string{`data: {"error":{"message":"overloaded"}}`}, ErrProtocol},
+		{"no [DONE]", http.StatusOK, []string{sseChunk("Hel")}, ErrProtocol},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := sseStub(t, test.status, test.lines...)
+			_, err := newTestOpenAIClient(server.URL).Complete(context.Background(), testRequest(), nil)
+			if !errors.Is(err, test.want) {
+				t.Errorf("error %v, want %v", err, test.want)
+			}
+		})
+	}
+}
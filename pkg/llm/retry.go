@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"context"
+	"time"
+
+	"github.com/zerobugdebug/cogfight/pkg/logging"
+)
+
+const (
+	defaultAttempts     int           = 3
+	defaultTimeout      time.Duration = 60 * time.Second
+	defaultRetryBackoff time.Duration = 500 * time.Millisecond
+	maxRetryBackoff     time.Duration = 10 * time.Second
+)
+
+// RetryClient limits the time of every attempt and retries the failed requests with exponential backoff.
+// The request is not retried once any part of the reply was streamed, so the reply is never shown twice.
+type RetryClient struct {
+	Client Client
+	// Attempts is the maximum number of attempts, including the first one
+	Attempts int
+	// Timeout limits every attempt, no limit if 0
+	Timeout time.Duration
+	// Backoff is the delay before the first retry, doubled for every next one
+	Backoff time.Duration
+}
+
+// Complete sends the request to the wrapped client, retrying it on ErrProxyUnavailable and ErrTimeout
+func (c *RetryClient) Complete(ctx context.Context, request Request, onChunk func(chunk string)) (string, error) {
+	backoff := c.Backoff
+	for attempt := 1; ; attempt++ {
+		streamed := false
+		result, err := c.attempt(ctx, request, func(chunk string) {
+			streamed = true
+			if onChunk != nil {
+				onChunk(chunk)
+			}
+		})
+		if err == nil || streamed || attempt >= c.Attempts || !retryable(err) || ctx.Err() != nil {
+			return result, err
+		}
+
+		logging.Debugf("LLM request attempt %d of %d failed, retrying in %s: %v", attempt, c.Attempts, backoff, err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return "", newError(ctx, err, "waiting to retry", nil)
+		}
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+func (c *RetryClient) attempt(ctx context.Context, request Request, onChunk func(chunk string)) (string, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	return c.Client.Complete(ctx, request, onChunk)
+}
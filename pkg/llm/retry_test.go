@@ -0,0 +1,121 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// flakyStub returns the OpenAI-compatible server that fails with the status until the request number succeeds, and the request counter
+func flakyStub(t *testing.T, status int, succeedOn int32, onFailure func()) (*httptest.Server, *int32) {
+	t.Helper()
+	requests := new(int32)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(requests, 1) != succeedOn {
+			if onFailure != nil {
+				onFailure()
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(sseChunk("Jab lands") + "\ndata: [DONE]\n\n"))
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func newTestRetryClient(client Client, attempts int, timeout, backoff time.Duration) *RetryClient {
+	return &RetryClient{Client: client, Attempts: attempts, Timeout: timeout, Backoff: backoff}
+}
+
+func TestRetryClientRetriesUnavailable(t *testing.T) {
+	server, requests := flakyStub(t, http.StatusServiceUnavailable, 3, nil)
+	client := newTestRetryClient(newTestOpenAIClient(server.URL), 3, time.Second, time.Millisecond)
+	result, err := client.Complete(context.Background(), testRequest(), nil)
+	if err != nil || result != "Jab lands" {
+		t.Fatalf("result %q, error %v, want the reply of the third attempt", result, err)
+	}
+	if *requests != 3 {
+		t.Errorf("sent %d requests, want 3", *requests)
+	}
+}
+
+func TestRetryClientGivesUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		attempts int
+		want     error
+		requests int32
+	}{
+		{"out of attempts", http.StatusServiceUnavailable, 2, ErrProxyUnavailable, 2},
+		{"protocol error", http.StatusBadRequest, 3, ErrProtocol, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, requests := flakyStub(t, test.status, 0, nil)
+			client := newTestRetryClient(newTestOpenAIClient(server.URL), test.attempts, time.Second, time.Millisecond)
+			_, err := client.Complete(context.Background(), testRequest(), nil)
+			if !errors.Is(err, test.want) {
+				t.Errorf("error %v, want %v", err, test.want)
+			}
+			if *requests != test.requests {
+				t.Errorf("sent %d requests, want %d", *requests, test.requests)
+			}
+		})
+	}
+}
+
+func TestRetryClientBackoffStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The request is cancelled while the client waits for the long backoff after the first failure
+	server, requests := flakyStub(t, http.StatusServiceUnavailable, 0, cancel)
+	client := newTestRetryClient(newTestOpenAIClient(server.URL), 3, time.Second, time.Hour)
+	start := time.Now()
+	_, err := client.Complete(ctx, testRequest(), nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancelled request returned after %s, want no backoff wait", elapsed)
+	}
+	if *requests != 1 {
+		t.Errorf("sent %d requests, want no retry after the cancel", *requests)
+	}
+}
+
+func TestRetryClientDoesNotRepeatStreamedReply(t *testing.T) {
+	server, connections := proxyStub(t, func(conn *websocket.Conn, request proxyRequestData) {
+		sendFrames(conn, "Jab ")
+		conn.Close()
+	})
+	client := newTestRetryClient(&WSProxyClient{URL: wsURL(server)}, 3, time.Second, time.Millisecond)
+	chunks := 0
+	_, err := client.Complete(context.Background(), testRequest(), func(chunk string) { chunks++ })
+	if !errors.Is(err, ErrProxyUnavailable) {
+		t.Errorf("error %v, want %v", err, ErrProxyUnavailable)
+	}
+	if connections() != 1 || chunks != 1 {
+		t.Errorf("made %d attempts streaming %d chunks, want a single attempt after the reply was streamed", connections(), chunks)
+	}
+}
+
+func TestRetryClientAttemptTimeout(t *testing.T) {
+	// The proxy never sends the end marker, so every attempt times out
+	server, connections := proxyStub(t, func(conn *websocket.Conn, request proxyRequestData) {})
+	client := newTestRetryClient(&WSProxyClient{URL: wsURL(server)}, 2, 50*time.Millisecond, time.Millisecond)
+	_, err := client.Complete(context.Background(), testRequest(), nil)
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("error %v, want %v", err, ErrTimeout)
+	}
+	if connections() != 2 {
+		t.Errorf("made %d attempts, want 2", connections())
+	}
+}
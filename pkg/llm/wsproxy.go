@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	proxyEndMarker     string = "<END>"
+	proxyTimeoutMarker        = "Endpoint request timed out"
+)
+
+type proxyRequestData struct {
+	PromptTemplate string    `json:"prompt_template"`
+	Messages       []Message `json:"messages"`
+	ResponseType   string    `json:"response_type"`
+}
+
+// WSProxyClient talks to the OpenAI websocket proxy, which resolves the prompt templates on the server.
+// The connection is kept open between the requests and reopened after any failure.
+type WSProxyClient struct {
+	URL string
+
+	mu   sync.Mutex
+	conn *websocket.Conn
+}
+
+// Complete sends the request to the proxy and reads the streamed reply until the end marker
+func (c *WSProxyClient) Complete(ctx context.Context, request Request, onChunk func(chunk string)) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	responseType := "stream"
+	if request.JSON {
+		responseType = "json"
+	}
+	jsonData, err := json.Marshal(proxyRequestData{
+		PromptTemplate: request.Prompt,
+		Messages:       request.Messages,
+		ResponseType:   responseType,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error encoding request to JSON: %v", err)
+	}
+
+	if c.conn == nil {
+		c.conn, _, err = websocket.DefaultDialer.DialContext(ctx, c.URL, nil)
+		if err != nil {
+			return "", newError(ctx, ErrProxyUnavailable, "error connecting to websocket server "+c.URL, err)
+		}
+	}
+	result, err := c.exchange(ctx, c.conn, jsonData, onChunk)
+	if err != nil {
+		// The connection state is unknown after the failure
+		c.conn.Close()
+		c.conn = nil
+	}
+	return result, err
+}
+
+// exchange sends the request over the connection and reads the reply, the context deadline and cancellation interrupt the reading
+func (c *WSProxyClient) exchange(ctx context.Context, conn *websocket.Conn, jsonData []byte, onChunk func(chunk string)) (string, error) {
+	deadline, _ := ctx.Deadline()
+	conn.SetWriteDeadline(deadline)
+	conn.SetReadDeadline(deadline)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	defer func() {
+		// Wait for the watcher, so it can't touch the connection once it's reused
+		close(done)
+		<-stopped
+	}()
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			// Unblock the pending read
+			conn.SetReadDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	err := conn.WriteMessage(websocket.TextMessage, jsonData)
+	if err != nil {
+		return "", newError(ctx, ErrProxyUnavailable, "error sending request to websocket server", err)
+	}
+
+	result := ""
+	for {
+		messageType, msg, err := conn.ReadMessage()
+		if err != nil {
+			return "", newError(ctx, ErrProxyUnavailable, "error reading from websocket server", err)
+		}
+		if messageType != websocket.TextMessage {
+			return "", newError(ctx, ErrProtocol, fmt.Sprintf("unexpected websocket message type %d", messageType), nil)
+		}
+		text := string(msg)
+		if strings.Contains(text, proxyTimeoutMarker) {
+			return "", newError(ctx, ErrTimeout, "proxy reported: "+text, nil)
+		}
+		if text == proxyEndMarker {
+			return result, nil
+		}
+		if onChunk != nil {
+			onChunk(text)
+		}
+		result += text
+	}
+}
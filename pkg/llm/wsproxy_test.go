@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// proxyStub returns the websocket proxy that answers every request with the frames from the reply function
+func proxyStub(t *testing.T, reply func(conn *websocket.Conn, request proxyRequestData)) (*httptest.Server, func() int) {
+	t.Helper()
+	var mu sync.Mutex
+	connections := 0
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("error upgrading connection: %v", err)
+			return
+		}
+		defer conn.Close()
+		mu.Lock()
+		connections++
+		mu.Unlock()
+		for {
+			var request proxyRequestData
+			if err := conn.ReadJSON(&request); err != nil {
+				return
+			}
+			reply(conn, request)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return connections
+	}
+}
+
+// wsURL returns the websocket URL of the test server
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// sendFrames writes the text frames to the connection
+func sendFrames(conn *websocket.Conn, frames ...string) {
+	for _, frame := range frames {
+		conn.WriteMessage(websocket.TextMessage, []byte(frame))
+	}
+}
+
+func TestWSProxyClientStreamsUntilEnd(t *testing.T) {
+	server, connections := proxyStub(t, func(conn *websocket.Conn, request proxyRequestData) {
+		if request.PromptTemplate != testPrompt || request.ResponseType != "stream" || len(request.Messages) != 1 {
+			t.Errorf("unexpected request %+v", request)
+		}
+		sendFrames(conn, "Jab ", "lands", proxyEndMarker)
+	})
+	client := &WSProxyClient{URL: wsURL(server)}
+	for i := 0; i < 2; i++ {
+		chunks := []string{}
+		result, err := client.Complete(context.Background(), testRequest(), func(chunk string) {
+			chunks = append(chunks, chunk)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != "Jab lands" {
+			t.Errorf("result %q, want the frames before %s", result, proxyEndMarker)
+		}
+		if !reflect.DeepEqual(chunks, []string{"Jab ", "lands"}) {
+			t.Errorf("streamed chunks %q, want the frames before %s", chunks, proxyEndMarker)
+		}
+	}
+	if connections() != 1 {
+		t.Errorf("opened %d connections, want the connection reused", connections())
+	}
+}
+
+func TestWSProxyClientJSONResponseType(t *testing.T) {
+	server, _ := proxyStub(t, func(conn *websocket.Conn, request proxyRequestData) {
+		if request.ResponseType != "json" {
+			t.Errorf("response type %q, want json", request.ResponseType)
+		}
+		sendFrames(conn, `{"name":"Jab"}`, proxyEndMarker)
+	})
+	result, err := (&WSProxyClient{URL: wsURL(server)}).Complete(context.Background(), jsonRequest(), nil)
+	if err != nil || result != `{"name":"Jab"}` {
+		t.Errorf("result %q, error %v, want the JSON reply", result, err)
+	}
+}
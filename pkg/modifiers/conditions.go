@@ -0,0 +1,271 @@
+package modifiers
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/zerobugdebug/cogfight/pkg/logging"
+)
+
+const defaultConditionsFile string = "conditions.yaml"
+
+//go:embed conditions.yaml
+var defaultConditionsYAML []byte
+
+// builtinConditions must be defined in every conditions file, because the game rules refer to them
+var builtinConditions = []Condition{Healthy, Bruised, Disoriented, Prone, CriticalHit, Bleeding, Paralysed, Insulted}
+
+// Stacking is the policy for the condition applied again while the fighter still has it
+type Stacking string
+
+const (
+	// Refresh restarts the duration
+	Refresh Stacking = "refresh"
+	// Extend adds the duration to the remaining turns
+	Extend Stacking = "extend"
+	// Stack adds one more intensity up to MaxStacks and restarts the duration
+	Stack Stacking = "stack"
+	// Ignore keeps the condition as it is
+	Ignore Stacking = "ignore"
+)
+
+// Stackings lists all stacking policies
+var Stackings = []Stacking{Refresh, Extend, Stack, Ignore}
+
+// Definition describes the condition and its effects
+type Definition struct {
+	Condition Condition
+	Name      string
+	// Action is the name of the action causing the condition, e.g. Knockdown for Prone
+	Action string
+	// Duration is the number of the fighter's turns the condition lasts
+	Duration int
+	Effects  map[Modifier]int
+	Stacking Stacking
+	// MaxStacks limits the intensity of the Stack conditions, every stack adds the stat and health effects again
+	MaxStacks int
+	// Immunity is the number of the opponent's turns the fighter can't get the condition again after it expires
+	Immunity int
+}
+
+// Effect returns the value of the condition effect, or 0 if the condition doesn't have it
+func (d *Definition) Effect(modifier Modifier) int {
+	return d.Effects[modifier]
+}
+
+func (s Stacking) valid() bool {
+	for _, stacking := range Stackings {
+		if s == stacking {
+			return true
+		}
+	}
+	return false
+}
+
+func stackingNames() string {
+	names := make([]string, len(Stackings))
+	for i, stacking := range Stackings {
+		names[i] = string(stacking)
+	}
+	return strings.Join(names, ", ")
+}
+
+// definitions holds the conditions by id, loaded from the embedded conditions file unless LoadConditions is called.
+// LoadConditions swaps the whole map, so the running fights see either the old or the new conditions.
+var definitions atomic.Pointer[map[Condition]*Definition]
+
+func init() {
+	embedded := mustParseConditions(bytes.NewReader(defaultConditionsYAML), defaultConditionsFile)
+	definitions.Store(&embedded)
+}
+
+// Lookup returns the definition of the condition, unknown conditions have no name and no effects
+func Lookup(condition Condition) *Definition {
+	if definition, ok := (*definitions.Load())[condition]; ok {
+		return definition
+	}
+	return &Definition{Condition: condition}
+}
+
+// Definitions returns all defined conditions sorted by id
+func Definitions() []*Definition {
+	current := *definitions.Load()
+	sorted := make([]*Definition, 0, len(current))
+	for _, definition := range current {
+		sorted = append(sorted, definition)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Condition < sorted[j].Condition })
+	return sorted
+}
+
+// LoadConditions replaces the conditions with the ones from the YAML or JSON file, it's safe to call during the fights
+func LoadConditions(filename string) error {
+	logging.Infof("Reading conditions %s", filename)
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("error opening conditions file: %s", err)
+	}
+	defer file.Close()
+	loaded, err := parseConditions(file, filename)
+	if err != nil {
+		return err
+	}
+	definitions.Store(&loaded)
+	return nil
+}
+
+func mustParseConditions(r io.Reader, source string) map[Condition]*Definition {
+	parsed, err := parseConditions(r, source)
+	if err != nil {
+		logging.Fatalf("Embedded conditions are invalid: %v", err)
+	}
+	return parsed
+}
+
+// conditionEntry is the condition as written in the conditions file, before the validation
+type conditionEntry struct {
+	ID       *int           `yaml:"id"`
+	Name     string         `yaml:"name"`
+	Action   string         `yaml:"action"`
+	Duration int            `yaml:"duration"`
+	Effects  map[string]int `yaml:"effects"`
+	Stacking Stacking       `yaml:"stacking"`
+	// MaxStacks is a pointer to tell the missing value from zero
+	MaxStacks *int `yaml:"max_stacks"`
+	Immunity  int  `yaml:"immunity"`
+	line      int
+}
+
+var conditionFields = map[string]bool{"id": true, "name": true, "action": true, "duration": true, "effects": true, "stacking": true, "max_stacks": true, "immunity": true}
+
+// parseConditions reads and validates the conditions, all invalid conditions are reported together
+func parseConditions(r io.Reader, source string) (map[Condition]*Definition, error) {
+	document := struct {
+		Conditions []yaml.Node `yaml:"conditions"`
+	}{}
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	err := decoder.Decode(&document)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding conditions %s: %v", source, err)
+	}
+
+	parsed := make(map[Condition]*Definition)
+	names := make(map[string]bool)
+	errs := []error{}
+	fail := func(line int, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s:%d: %s", source, line, fmt.Sprintf(format, args...)))
+	}
+	for _, node := range document.Conditions {
+		node := node
+		if node.Kind != yaml.MappingNode {
+			fail(node.Line, "condition should be an object")
+			continue
+		}
+		unknown := false
+		for i := 0; i < len(node.Content); i += 2 {
+			if !conditionFields[node.Content[i].Value] {
+				fail(node.Content[i].Line, "unknown field %q", node.Content[i].Value)
+				unknown = true
+			}
+		}
+		entry := conditionEntry{}
+		if err := node.Decode(&entry); err != nil {
+			fail(node.Line, "%v", err)
+			continue
+		}
+		if unknown {
+			continue
+		}
+		entry.line = node.Line
+
+		name := strings.TrimSpace(entry.Name)
+		switch {
+		case entry.ID == nil:
+			fail(entry.line, "condition %q has no id", name)
+			continue
+		case *entry.ID < 0:
+			fail(entry.line, "condition %q has negative id %d", name, *entry.ID)
+			continue
+		case parsed[Condition(*entry.ID)] != nil:
+			fail(entry.line, "condition %q has the same id %d as %q", name, *entry.ID, parsed[Condition(*entry.ID)].Name)
+			continue
+		case name == "":
+			fail(entry.line, "condition %d has no name", *entry.ID)
+			continue
+		case names[strings.ToLower(name)]:
+			fail(entry.line, "duplicate condition name %q", name)
+			continue
+		}
+		names[strings.ToLower(name)] = true
+
+		definition := &Definition{Condition: Condition(*entry.ID), Name: name, Action: strings.TrimSpace(entry.Action), Duration: entry.Duration, Effects: make(map[Modifier]int),
+			Stacking: Refresh, MaxStacks: 1, Immunity: entry.Immunity}
+		if definition.Action == "" {
+			definition.Action = name
+		}
+		if entry.Stacking != "" {
+			definition.Stacking = Stacking(strings.ToLower(string(entry.Stacking)))
+			if !definition.Stacking.valid() {
+				fail(entry.line, "condition %q: unknown stacking %q, should be one of: %s", name, entry.Stacking, stackingNames())
+			}
+		}
+		if entry.MaxStacks != nil {
+			definition.MaxStacks = *entry.MaxStacks
+			if definition.Stacking != Stack {
+				fail(entry.line, "condition %q: max_stacks is only used with the %s stacking", name, Stack)
+			} else if definition.MaxStacks < 1 {
+				fail(entry.line, "condition %q: max_stacks should be at least 1, got %d", name, definition.MaxStacks)
+			}
+		}
+		if definition.Immunity < 0 {
+			fail(entry.line, "condition %q: immunity can't be negative, got %d", name, definition.Immunity)
+		}
+		if definition.Condition == Healthy {
+			if definition.Duration != 0 || len(entry.Effects) > 0 || definition.Immunity != 0 {
+				fail(entry.line, "condition %q can't have a duration, effects or immunity", name)
+			}
+		} else if definition.Duration < 1 {
+			fail(entry.line, "condition %q should last at least 1 turn, got %d", name, definition.Duration)
+		}
+		modifierNames := make([]string, 0, len(entry.Effects))
+		for modifierName := range entry.Effects {
+			modifierNames = append(modifierNames, modifierName)
+		}
+		sort.Strings(modifierNames)
+		for _, modifierName := range modifierNames {
+			value := entry.Effects[modifierName]
+			modifier, err := ParseModifier(modifierName)
+			if err != nil {
+				fail(entry.line, "condition %q: %v", name, err)
+				continue
+			}
+			switch {
+			case (modifier == SkipTurn || modifier == SureStrike) && value != 0 && value != 1:
+				fail(entry.line, "condition %q: %s should be 0 or 1, got %d", name, modifierName, value)
+			case modifier == DamageMult && value < 1:
+				fail(entry.line, "condition %q: %s should be at least 1, got %d", name, modifierName, value)
+			}
+			definition.Effects[modifier] = value
+		}
+		parsed[definition.Condition] = definition
+	}
+	for _, condition := range builtinConditions {
+		if parsed[condition] == nil {
+			errs = append(errs, fmt.Errorf("%s: condition with id %d is required by the game rules", source, condition))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,89 @@
+package modifiers
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConditions(t *testing.T) {
+	tests := []struct {
+		name string
+		// extra is the condition appended to the embedded conditions
+		extra string
+		want  []string
+	}{
+		{"valid", "  - {id: 20, name: Dazed, duration: 2, effects: {hit_chance: -10}, stacking: extend, immunity: 1}", nil},
+		{"unknown field", "  - {id: 20, name: Dazed, duration: 2, color: red}", []string{`unknown field "color"`}},
+		{"no id", "  - {name: Dazed, duration: 2}", []string{`condition "Dazed" has no id`}},
+		{"negative id", "  - {id: -1, name: Dazed, duration: 2}", []string{`condition "Dazed" has negative id -1`}},
+		{"same id", "  - {id: 4, name: Dazed, duration: 2}", []string{`condition "Dazed" has the same id 4 as "Critical Hit"`}},
+		{"no name", "  - {id: 20, duration: 2}", []string{"condition 20 has no name"}},
+		{"duplicate name", "  - {id: 20, name: bleeding, duration: 2}", []string{`duplicate condition name "bleeding"`}},
+		{"unknown stacking", "  - {id: 20, name: Dazed, duration: 2, stacking: merge}", []string{`unknown stacking "merge"`}},
+		{"max stacks without stack", "  - {id: 20, name: Dazed, duration: 2, max_stacks: 3}", []string{"max_stacks is only used with the stack stacking"}},
+		{"zero max stacks", "  - {id: 20, name: Dazed, duration: 2, stacking: stack, max_stacks: 0}", []string{"max_stacks should be at least 1, got 0"}},
+		{"negative immunity", "  - {id: 20, name: Dazed, duration: 2, immunity: -1}", []string{"immunity can't be negative, got -1"}},
+		{"no duration", "  - {id: 20, name: Dazed}", []string{`condition "Dazed" should last at least 1 turn, got 0`}},
+		{"unknown modifier", "  - {id: 20, name: Dazed, duration: 2, effects: {speed: 10}}", []string{`condition "Dazed": unknown modifier`}},
+		{"skip turn flag", "  - {id: 20, name: Dazed, duration: 2, effects: {skip_turn: 2}}", []string{"skip_turn should be 0 or 1, got 2"}},
+		{"damage multiplier", "  - {id: 20, name: Dazed, duration: 2, effects: {damage_mult: 0}}", []string{"damage_mult should be at least 1, got 0"}},
+		{
+			"all errors together",
+			"  - {id: 20, name: Dazed}\n  - {id: 21, name: Winded, duration: 1, immunity: -2}",
+			[]string{"conditions.yaml:", `"Dazed" should last at least 1 turn`, `"Winded": immunity can't be negative`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := append(append([]byte{}, defaultConditionsYAML...), []byte("\n"+test.extra+"\n")...)
+			parsed, err := parseConditions(bytes.NewReader(data), defaultConditionsFile)
+			if test.want == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if dazed := parsed[Condition(20)]; dazed == nil || dazed.Name != "Dazed" || dazed.Action != "Dazed" || dazed.Stacking != Extend || dazed.MaxStacks != 1 {
+					t.Errorf("parsed %+v, want Dazed with the defaults", dazed)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("invalid conditions parsed without errors")
+			}
+			for _, want := range test.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("got error %v, want %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseConditionsRequired(t *testing.T) {
+	_, err := parseConditions(strings.NewReader("conditions:\n  - {id: 0, name: Healthy}\n"), "conditions.yaml")
+	if err == nil || !strings.Contains(err.Error(), "condition with id 5 is required by the game rules") {
+		t.Errorf("got error %v, want the missing Bleeding", err)
+	}
+}
+
+func TestLoadConditions(t *testing.T) {
+	embedded := definitions.Load()
+	t.Cleanup(func() { definitions.Store(embedded) })
+
+	filename := filepath.Join(t.TempDir(), "conditions.yaml")
+	data := bytes.Replace(defaultConditionsYAML, []byte("name: Bleeding"), []byte("name: Cut"), 1)
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadConditions(filename); err != nil {
+		t.Fatal(err)
+	}
+	if name := Lookup(Bleeding).Name; name != "Cut" {
+		t.Errorf("bleeding is named %q after the loading, want Cut", name)
+	}
+	if condition, err := ParseCondition("cut"); err != nil || condition != Bleeding {
+		t.Errorf("parsed %s with error %v, want the loaded bleeding", condition, err)
+	}
+}
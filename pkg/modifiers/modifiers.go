@@ -1,6 +1,13 @@
 package modifiers
 
-// Condition is an enumeration of fighter conditions
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Condition is the id of the fighter condition from the conditions file.
+// The conditions the game rules refer to are listed below, any other conditions are only defined in the file.
 type Condition int
 
 const (
@@ -14,6 +21,7 @@ const (
 	Insulted
 )
 
+// Modifier is the kind of the condition effect
 type Modifier int
 
 const (
@@ -26,92 +34,96 @@ const (
 	SkipTurn
 	OpponentHitChance
 	OpponentBlockChance
+	// HPPerTurn changes the health of the fighter with the condition after its every turn
 	HPPerTurn
+	// MyHPPerTurn changes the health of the opponent, who caused the condition, after every turn of the fighter with the condition
 	MyHPPerTurn
 	SureStrike
 )
 
-// ConditionAttributes maps fighter conditions to their respective attributes
-var DefaultConditionAttributes = map[Condition]map[Modifier]int{
-	Healthy: {},
-	Bruised: {
-		HitChance:   -20,
-		BlockChance: -20,
-		Duration:    3,
-	},
-	Disoriented: {
-		Damage:     -20,
-		Complexity: 20,
-		Duration:   3,
-	},
-	Prone: {
-		SkipTurn: 1,
-		Duration: 1,
-	},
-	CriticalHit: {
-		DamageMult: 2,
-		Duration:   1,
-	},
-	Bleeding: {
-		HPPerTurn: -20,
-		Duration:  3,
-	},
-	Paralysed: {
-		SureStrike: 1,
-		SkipTurn:   1,
-		Duration:   1,
-	},
-	Insulted: {
-		OpponentHitChance:   20,
-		OpponentBlockChance: 20,
-		Duration:            3,
-	},
+var modifierNames = map[Modifier]string{
+	HitChance:           "hit_chance",
+	BlockChance:         "block_chance",
+	Damage:              "damage",
+	DamageMult:          "damage_mult",
+	Complexity:          "complexity",
+	SkipTurn:            "skip_turn",
+	OpponentHitChance:   "opponent_hit_chance",
+	OpponentBlockChance: "opponent_block_chance",
+	HPPerTurn:           "hp_per_turn",
+	MyHPPerTurn:         "opponent_hp_per_turn",
+	SureStrike:          "sure_strike",
 }
 
-// ActionString returns the string representation of the action for the condition
-func (cd Condition) ActionString() string {
-	switch cd {
-	case Healthy:
-		return "Healthy"
-	case Bruised:
-		return "Bruise"
-	case Disoriented:
-		return "Disorientation"
-	case Prone:
-		return "Knockdown"
-	case CriticalHit:
-		return "Critical hit"
-	case Bleeding:
-		return "Bleed"
-	case Paralysed:
-		return "Paralysis"
-	case Insulted:
-		return "Insult"
+// String returns the name of the modifier used in the conditions file
+func (m Modifier) String() string {
+	return modifierNames[m]
+}
+
+// ParseModifier returns the modifier by its name in the conditions file
+func ParseModifier(name string) (Modifier, error) {
+	for modifier, modifierName := range modifierNames {
+		if name == modifierName {
+			return modifier, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown modifier %q", name)
+}
+
+// Stat returns the fighter stat changed by the modifier and whether it's changed for the opponent of the fighter with the condition.
+// ok is false for the modifiers which don't change the stats, but take effect during the turn.
+func (m Modifier) Stat() (stat Modifier, opponent bool, ok bool) {
+	switch m {
+	case HitChance, BlockChance, Damage, Complexity:
+		return m, false, true
+	case OpponentHitChance:
+		return HitChance, true, true
+	case OpponentBlockChance:
+		return BlockChance, true, true
 	default:
-		return ""
+		return m, false, false
+	}
+}
+
+// Ledger holds the total stat modifiers the fighter gets from the active conditions.
+// It's derived from the conditions whenever it's needed instead of being updated as they change, so it can't drift from them.
+type Ledger map[Modifier]float64
+
+// Add adds the stat effects of the condition with the intensity, onOpponent tells whether the condition is on the fighter's opponent
+func (l Ledger) Add(condition Condition, stacks int, onOpponent bool) {
+	for modifier, value := range Lookup(condition).Effects {
+		if stat, opponent, ok := modifier.Stat(); ok && opponent == onOpponent {
+			l[stat] += float64(value * stacks)
+		}
 	}
 }
 
+// SortedConditions returns the conditions from the map in a stable order, so the effects are always applied in the same sequence
+func SortedConditions(conditions map[Condition]int) []Condition {
+	sorted := make([]Condition, 0, len(conditions))
+	for condition := range conditions {
+		sorted = append(sorted, condition)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// ParseCondition returns the condition by its name, ignoring the case
+func ParseCondition(name string) (Condition, error) {
+	for _, definition := range *definitions.Load() {
+		if strings.EqualFold(strings.TrimSpace(name), definition.Name) {
+			return definition.Condition, nil
+		}
+	}
+	return Healthy, fmt.Errorf("unknown condition %q", name)
+}
+
+// ActionString returns the string representation of the action for the condition
+func (cd Condition) ActionString() string {
+	return Lookup(cd).Action
+}
+
 // String returns the string representation of the condition
 func (cd Condition) String() string {
-	switch cd {
-	case Healthy:
-		return "Healthy"
-	case Bruised:
-		return "Bruised"
-	case Disoriented:
-		return "Disoriented"
-	case Prone:
-		return "Prone"
-	case CriticalHit:
-		return "Critical Hit"
-	case Bleeding:
-		return "Bleeding"
-	case Paralysed:
-		return "Paralysed"
-	case Insulted:
-		return "Insulted"
-	default:
-		return ""
-	}
+	return Lookup(cd).Name
 }
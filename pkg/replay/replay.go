@@ -0,0 +1,278 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/fighter"
+	"github.com/zerobugdebug/cogfight/pkg/game"
+	"github.com/zerobugdebug/cogfight/pkg/logging"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+const (
+	// recordingVersion is the version of the recording file written by Save, the version 2 stores the fighters as fighter files
+	recordingVersion int = 2
+)
+
+// Recording holds everything needed to play the match back: the fighters before the fight, the seed, the chosen attacks and stances
+// and the resulting events
+type Recording struct {
+	Version  int
+	Seed     int64
+	Fighters [2]*fighter.Fighter
+	Choices  []string
+	Stances  []attack.Stance
+	// Rules are the match rules, recordings without them were played until the knockout
+	Rules  game.Rules
+	Events []event.Record
+}
+
+// recordingFile is the recording file schema, the fighters are saved in the fighter file schema, so the recording
+// survives the changes of the Fighter struct
+type recordingFile struct {
+	Version  int                `json:"version"`
+	Seed     int64              `json:"seed"`
+	Fighters [2]json.RawMessage `json:"fighters"`
+	Choices  []string           `json:"choices"`
+	Stances  []attack.Stance    `json:"stances,omitempty"`
+	Rules    game.Rules         `json:"rules"`
+	Events   []event.Record     `json:"events"`
+}
+
+// Recorder is a Subscriber that records the match
+type Recorder struct {
+	recording *Recording
+	fighters  [2]*fighter.Fighter
+	err       error
+}
+
+// NewRecorder creates a recorder for the match between the fighters under the rules, the fighters are copied in their current state
+func NewRecorder(f1, f2 *fighter.Fighter, rules game.Rules, seed int64) (*Recorder, error) {
+	recording := &Recording{Version: recordingVersion, Seed: seed, Rules: rules}
+	for i, f := range []*fighter.Fighter{f1, f2} {
+		snapshot, err := copyFighter(f)
+		if err != nil {
+			return nil, err
+		}
+		recording.Fighters[i] = snapshot
+	}
+	return &Recorder{recording: recording, fighters: [2]*fighter.Fighter{f1, f2}}, nil
+}
+
+// Notify records the event
+func (r *Recorder) Notify(e event.Event) {
+	switch e := e.(type) {
+	case event.AttackAttempted:
+		r.recording.Choices = append(r.recording.Choices, e.Attack)
+	case event.StanceChosen:
+		// The stances forced by the conditions aren't chosen by the strategies, so they aren't replayed
+		if e.Condition == modifiers.Healthy {
+			r.recording.Stances = append(r.recording.Stances, e.Stance)
+		}
+	}
+	record, err := event.NewRecord(e)
+	if err != nil {
+		r.err = err
+		return
+	}
+	r.recording.Events = append(r.recording.Events, record)
+}
+
+// Recording returns the match recorded so far, including the custom attacks the fighters created during the match
+func (r *Recorder) Recording() *Recording {
+	for i, f := range r.fighters {
+		for _, customAttack := range f.CustomAttacks {
+			if !hasCustomAttack(r.recording.Fighters[i], customAttack.Name) {
+				snapshot := *customAttack
+				r.recording.Fighters[i].CustomAttacks = append(r.recording.Fighters[i].CustomAttacks, &snapshot)
+			}
+		}
+	}
+	return r.recording
+}
+
+// Save writes the recording to the JSON file, creating the directory if needed
+func (r *Recorder) Save(filename string) error {
+	if r.err != nil {
+		return fmt.Errorf("error recording the match: %v", r.err)
+	}
+	recording := r.Recording()
+	file := recordingFile{Version: recording.Version, Seed: recording.Seed, Choices: recording.Choices, Stances: recording.Stances,
+		Rules: recording.Rules, Events: recording.Events}
+	for i, f := range recording.Fighters {
+		fighterJSON, err := fighter.EncodeFighter(f)
+		if err != nil {
+			return fmt.Errorf("error encoding fighter to JSON: %s", err)
+		}
+		file.Fighters[i] = fighterJSON
+	}
+	recordingJSON, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding recording to JSON: %s", err)
+	}
+	if dir := filepath.Dir(filename); dir != "" {
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return fmt.Errorf("error creating recording directory: %s", err)
+		}
+	}
+	err = os.WriteFile(filename, recordingJSON, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing recording to file: %s", err)
+	}
+	return nil
+}
+
+// Load reads the recording from the JSON file
+func Load(filename string) (*Recording, error) {
+	recordingJSON, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading recording from file: %s", err)
+	}
+	file := &recordingFile{}
+	err = json.Unmarshal(recordingJSON, file)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding recording from JSON: %s", err)
+	}
+	if file.Version > recordingVersion {
+		return nil, fmt.Errorf("recording version %d is newer than the supported version %d, update the game to play it", file.Version, recordingVersion)
+	}
+	if file.Version < 1 {
+		return nil, fmt.Errorf("invalid recording version %d", file.Version)
+	}
+	recording := &Recording{Version: recordingVersion, Seed: file.Seed, Choices: file.Choices, Stances: file.Stances, Rules: file.Rules, Events: file.Events}
+	for i, fighterJSON := range file.Fighters {
+		if len(fighterJSON) == 0 || string(fighterJSON) == "null" {
+			return nil, fmt.Errorf("recording %s doesn't contain both fighters", filename)
+		}
+		if file.Version == 1 {
+			fighterJSON, err = migrateFighterV1(fighterJSON)
+			if err != nil {
+				return nil, fmt.Errorf("can't migrate recording %s from version 1: %s", filename, err)
+			}
+		}
+		recording.Fighters[i], err = fighter.DecodeFighter(fighterJSON)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding fighter from recording %s: %s", filename, err)
+		}
+	}
+	return recording, nil
+}
+
+// migrateFighterV1 converts the fighter dumped by the version 1 recording to the fighter file, dropping the battle state
+func migrateFighterV1(data []byte) ([]byte, error) {
+	f := &fighter.Fighter{}
+	err := json.Unmarshal(data, f)
+	if err != nil {
+		return nil, err
+	}
+	return fighter.EncodeFighter(f)
+}
+
+// Play re-runs the recorded match turn by turn in the terminal, looking up the recorded attacks in the catalog.
+// The match is simulated again from the recorded seed and choices and compared with the recorded events.
+func Play(recording *Recording, catalog *attack.Registry) error {
+	match, checker, err := newReplay(recording, catalog, true)
+	if err != nil {
+		return err
+	}
+	f1, f2 := match.Fighters()
+	match.Subscribe(game.NewConsolePrinter(f1, f2))
+
+	fmt.Printf("\n%s vs %s! (seed %d)\n", f1.Name, f2.Name, recording.Seed)
+	// The script forfeits the match if the recording ends before it's over
+	for !match.Over() {
+		match.Step()
+		color.HiBlue("\n\nPress 'Enter' to continue...")
+		fmt.Scanln()
+	}
+
+	if checker.diverged {
+		logging.Warnf("Replay diverged from the recording at event %d, the game rules or attacks have changed since it was recorded", checker.position)
+	}
+	logging.Info(match.Result())
+	return nil
+}
+
+// newReplay creates the match replaying the recording and the checker comparing its events with the recorded ones,
+// the selected attacks are announced in the terminal if announce is set
+func newReplay(recording *Recording, catalog *attack.Registry, announce bool) (*game.Match, *divergenceChecker, error) {
+	f1, err := copyFighter(recording.Fighters[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	f2, err := copyFighter(recording.Fighters[1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defaultAttacks := catalog.Attacks()
+	for _, name := range recording.Choices {
+		if !hasCustomAttack(f1, name) && !hasCustomAttack(f2, name) && defaultAttacks.GetAttackByName(name) == nil {
+			return nil, nil, fmt.Errorf("recorded attack %q is unknown", name)
+		}
+	}
+
+	script := &game.ScriptedStrategy{Script: recording.Choices, Stances: recording.Stances, Catalog: catalog}
+	strategy := &game.ConsoleStrategy{Strategy: script, Announce: announce}
+	match := game.NewMatch(f1, f2, strategy, strategy, recording.Seed)
+	match.SetRules(recording.Rules)
+	checker := &divergenceChecker{recorded: recording.Events}
+	match.Subscribe(checker)
+	return match, checker, nil
+}
+
+// divergenceChecker compares the replayed events with the recorded ones
+type divergenceChecker struct {
+	recorded []event.Record
+	position int
+	diverged bool
+}
+
+func (c *divergenceChecker) Notify(e event.Event) {
+	if c.diverged {
+		return
+	}
+	record, err := event.NewRecord(e)
+	if err != nil || c.position >= len(c.recorded) || record.Kind != c.recorded[c.position].Kind {
+		c.diverged = true
+		return
+	}
+	// The recorded data is indented in the file
+	recordedData := &bytes.Buffer{}
+	if json.Compact(recordedData, c.recorded[c.position].Data) != nil || !bytes.Equal(record.Data, recordedData.Bytes()) {
+		c.diverged = true
+		return
+	}
+	c.position++
+}
+
+func hasCustomAttack(f *fighter.Fighter, name string) bool {
+	for _, customAttack := range f.CustomAttacks {
+		if customAttack.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// copyFighter makes a deep copy of the fighter through the fighter file, so the copy is the same as the saved fighter
+func copyFighter(f *fighter.Fighter) (*fighter.Fighter, error) {
+	fighterJSON, err := fighter.EncodeFighter(f)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding fighter to JSON: %s", err)
+	}
+	snapshot, err := fighter.DecodeFighter(fighterJSON)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding fighter from JSON: %s", err)
+	}
+	return snapshot, nil
+}
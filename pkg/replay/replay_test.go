@@ -0,0 +1,134 @@
+package replay
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/fighter"
+	"github.com/zerobugdebug/cogfight/pkg/game"
+)
+
+// recordMatch plays the match between the tactical and the random strategies and saves its recording to the file
+func recordMatch(t *testing.T, filename string, catalog *attack.Registry) (*game.MatchResult, [2]*fighter.Fighter) {
+	t.Helper()
+	build := fighter.DefaultBuild()
+	build.Height, build.AgilityStrength = 170, 2
+	f1, f2 := fighter.NewFighter("Tom", fighter.DefaultBuild()), fighter.NewFighter("Jerry", build)
+	recorder, err := NewRecorder(f1, f2, game.Rules{}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := game.NewMatch(f1, f2, &game.TacticalStrategy{Catalog: catalog}, &game.RandomStrategy{Catalog: catalog}, 3)
+	match.Subscribe(recorder)
+	result := match.Run()
+	if err := recorder.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	return result, [2]*fighter.Fighter{f1, f2}
+}
+
+// checkReplay replays the recording and fails the test if the replay diverges from it or ends with another result
+func checkReplay(t *testing.T, recording *Recording, catalog *attack.Registry, want *game.MatchResult) {
+	t.Helper()
+	match, checker, err := newReplay(recording, catalog, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := match.Run()
+	if checker.diverged || checker.position != len(recording.Events) {
+		t.Errorf("replay diverged at event %d of %d", checker.position, len(recording.Events))
+	}
+	if result.String() != want.String() {
+		t.Errorf("replay ended with %q, want %q", result, want)
+	}
+}
+
+func TestRecordingRoundTrip(t *testing.T) {
+	catalog := attack.DefaultRegistry()
+	filename := filepath.Join(t.TempDir(), "match.json")
+	result, fighters := recordMatch(t, filename, catalog)
+
+	recording, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recording.Version != recordingVersion || recording.Seed != 3 || len(recording.Choices) == 0 || len(recording.Events) == 0 {
+		t.Fatalf("loaded version %d with seed %d, %d choices and %d events", recording.Version, recording.Seed, len(recording.Choices), len(recording.Events))
+	}
+	for i, f := range recording.Fighters {
+		if f.Name != fighters[i].Name || f.Build() != fighters[i].Build() || f.CurrentHealth != f.MaxHealth {
+			t.Errorf("loaded %s with %+v and %d health, want %s with %+v before the fight", f.Name, f.Build(), f.CurrentHealth, fighters[i].Name, fighters[i].Build())
+		}
+	}
+	checkReplay(t, recording, catalog, result)
+}
+
+func TestLoadRecordingV1(t *testing.T) {
+	catalog := attack.DefaultRegistry()
+	filename := filepath.Join(t.TempDir(), "match.json")
+	result, _ := recordMatch(t, filename, catalog)
+
+	// The version 1 recording dumped the Fighter struct instead of the fighter file
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := recordingFile{}
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	file.Version = 1
+	for i, fighterJSON := range file.Fighters {
+		f, err := fighter.DecodeFighter(fighterJSON)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if file.Fighters[i], err = json.Marshal(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if data, err = json.Marshal(file); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	recording, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recording.Version != recordingVersion {
+		t.Errorf("loaded recording version %d, want the migrated version %d", recording.Version, recordingVersion)
+	}
+	checkReplay(t, recording, catalog, result)
+}
+
+func TestLoadRecordingErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"newer version", `{"version": 3}`, "recording version 3 is newer than the supported version 2"},
+		{"zero version", `{"version": 0}`, "invalid recording version 0"},
+		{"missing fighter", `{"version": 2, "fighters": [null, {"version": 3, "name": "Tom"}]}`, "doesn't contain both fighters"},
+		{"invalid fighter", `{"version": 2, "fighters": [{"version": 4}, {"version": 3, "name": "Tom"}]}`, "fighter file version 4 is newer"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "match.json")
+			if err := os.WriteFile(filename, []byte(test.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Load(filename)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+}
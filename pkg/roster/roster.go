@@ -0,0 +1,112 @@
+package roster
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/zerobugdebug/cogfight/pkg/fighter"
+	"github.com/zerobugdebug/cogfight/pkg/logging"
+)
+
+const (
+	fighterExt = ".json"
+)
+
+// Roster is the directory of the saved fighters, every fighter is saved to its own JSON file named after the fighter
+type Roster struct {
+	dir string
+}
+
+// New creates the roster in the directory, the directory is created when the first fighter is saved
+func New(dir string) *Roster {
+	return &Roster{dir: dir}
+}
+
+// Dir returns the directory of the roster
+func (r *Roster) Dir() string {
+	return r.dir
+}
+
+// Path returns the file of the fighter with the name
+func (r *Roster) Path(name string) string {
+	return filepath.Join(r.dir, fileName(name)+fighterExt)
+}
+
+// fileName returns the name in lower case with all characters except the letters and the digits replaced by dashes
+func fileName(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, "-")
+}
+
+// Has reports whether the fighter with the name is saved in the roster
+func (r *Roster) Has(name string) bool {
+	_, err := os.Stat(r.Path(name))
+	return err == nil
+}
+
+// List loads all saved fighters sorted by name, the missing roster directory is empty.
+// The files that can't be loaded are skipped with a warning, so one broken file doesn't hide the whole roster.
+func (r *Roster) List() ([]*fighter.Fighter, error) {
+	files, err := filepath.Glob(filepath.Join(r.dir, "*"+fighterExt))
+	if err != nil {
+		return nil, fmt.Errorf("error listing roster %s: %s", r.dir, err)
+	}
+	fighters := []*fighter.Fighter{}
+	for _, file := range files {
+		f, err := fighter.LoadFighterFromFile(file)
+		if err != nil {
+			logging.Warnf("Skipping the fighter file: %v", err)
+			continue
+		}
+		fighters = append(fighters, f)
+	}
+	sort.Slice(fighters, func(i, j int) bool {
+		return fighters[i].Name < fighters[j].Name
+	})
+	return fighters, nil
+}
+
+// Load loads the saved fighter with the name, ready for a new fight
+func (r *Roster) Load(name string) (*fighter.Fighter, error) {
+	if fileName(name) == "" || !r.Has(name) {
+		return nil, fmt.Errorf("fighter %q is not in the roster %s", name, r.dir)
+	}
+	return fighter.LoadFighterFromFile(r.Path(name))
+}
+
+// Save saves the fighter to the roster, replacing the saved fighter with the same name.
+// Different names can map to the same file, the saved fighter with another name is never replaced.
+func (r *Roster) Save(f *fighter.Fighter) error {
+	if fileName(f.Name) == "" {
+		return fmt.Errorf("fighter name %q should contain letters or digits", f.Name)
+	}
+	if r.Has(f.Name) {
+		saved, err := fighter.LoadFighterFromFile(r.Path(f.Name))
+		if err == nil && saved.Name != f.Name {
+			return fmt.Errorf("fighter name %q is too similar to the saved fighter %q, pick another name", f.Name, saved.Name)
+		}
+	}
+	err := os.MkdirAll(r.dir, 0755)
+	if err != nil {
+		return fmt.Errorf("error creating roster directory: %s", err)
+	}
+	return fighter.SaveFighterToFile(f, r.Path(f.Name))
+}
+
+// Delete removes the saved fighter with the name from the roster
+func (r *Roster) Delete(name string) error {
+	if fileName(name) == "" || !r.Has(name) {
+		return fmt.Errorf("fighter %q is not in the roster %s", name, r.dir)
+	}
+	err := os.Remove(r.Path(name))
+	if err != nil {
+		return fmt.Errorf("error deleting fighter: %s", err)
+	}
+	return nil
+}
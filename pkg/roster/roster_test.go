@@ -0,0 +1,125 @@
+package roster
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zerobugdebug/cogfight/pkg/fighter"
+)
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Ivan Drago", "ivan-drago"},
+		{"  Bob   Smith!", "bob-smith"},
+		{"Renco Gracie 2", "renco-gracie-2"},
+		{"Жан Клод", "жан-клод"},
+		{"?!", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := fileName(test.name); got != test.want {
+				t.Errorf("file name is %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRoster(t *testing.T) {
+	r := New(filepath.Join(t.TempDir(), "fighters"))
+	if saved, err := r.List(); err != nil || len(saved) != 0 {
+		t.Fatalf("missing roster directory listed %d fighters with error %v, want none", len(saved), err)
+	}
+
+	build := fighter.DefaultBuild()
+	build.AgilityStrength = 2
+	for _, f := range []*fighter.Fighter{fighter.NewFighter("Tom", build), fighter.NewFighter("Ivan Drago", fighter.DefaultBuild())} {
+		if err := r.Save(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !r.Has("ivan drago") || r.Has("Jerry") {
+		t.Error("roster should have Ivan Drago by any case and not have Jerry")
+	}
+
+	tom, err := r.Load("Tom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tom.Name != "Tom" || tom.Build() != build {
+		t.Errorf("loaded %s with %+v, want Tom with %+v", tom.Name, tom.Build(), build)
+	}
+
+	// The fighter with the same name is replaced
+	build.Age = 50
+	if err := r.Save(fighter.NewFighter("Tom", build)); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := r.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 2 || saved[0].Name != "Ivan Drago" || saved[1].Name != "Tom" || saved[1].Age != 50 {
+		t.Errorf("listed %v, want Ivan Drago and the replaced Tom sorted by name", saved)
+	}
+
+	if err := r.Delete("tom"); err != nil {
+		t.Fatal(err)
+	}
+	if r.Has("Tom") {
+		t.Error("deleted fighter is still in the roster")
+	}
+	if _, err := r.Load("Tom"); err == nil {
+		t.Error("loaded the deleted fighter")
+	}
+	if err := r.Delete("Tom"); err == nil {
+		t.Error("deleted the missing fighter")
+	}
+}
+
+func TestSaveErrors(t *testing.T) {
+	r := New(t.TempDir())
+	if err := r.Save(fighter.NewFighter("Bob Smith", fighter.DefaultBuild())); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"bob-smith!", `fighter name "bob-smith!" is too similar to the saved fighter "Bob Smith"`},
+		{"BOB SMITH", `fighter name "BOB SMITH" is too similar to the saved fighter "Bob Smith"`},
+		{"?!", `fighter name "?!" should contain letters or digits`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := r.Save(fighter.NewFighter(test.name, fighter.DefaultBuild()))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got error %v, want %q", err, test.want)
+			}
+		})
+	}
+	if f, err := r.Load("Bob Smith"); err != nil || f.Name != "Bob Smith" {
+		t.Errorf("loaded %v with error %v, want the saved Bob Smith kept", f, err)
+	}
+}
+
+func TestListSkipsBrokenFiles(t *testing.T) {
+	r := New(t.TempDir())
+	if err := r.Save(fighter.NewFighter("Tom", fighter.DefaultBuild())); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(r.Dir(), "broken.json"), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := r.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].Name != "Tom" {
+		t.Errorf("listed %v, want only Tom", saved)
+	}
+}
@@ -0,0 +1,398 @@
+package simulate
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/fighter"
+	"github.com/zerobugdebug/cogfight/pkg/game"
+	"github.com/zerobugdebug/cogfight/pkg/modifiers"
+)
+
+const (
+	damageBucketSize int = 25
+)
+
+// Config describes the batch of headless fights to simulate
+type Config struct {
+	Fights  int
+	Workers int
+	Seed    int64
+	// Strategies are the difficulty names of the first and the second fighter strategies
+	Strategies [2]string
+	// Fighters is the pool of fighters to pick from, new fighters are generated for every fight if empty
+	Fighters []*fighter.Fighter
+	// Catalog is the attack catalog, the default attacks are used if it's not set
+	Catalog *attack.Registry
+	// Rules are the match rules, the fights last until the knockout if the rules have no rounds
+	Rules game.Rules
+}
+
+// ArchetypeStats holds the results of the fighters of the same archetype
+type ArchetypeStats struct {
+	Fights int
+	Wins   int
+}
+
+// AttackTypeStats holds the usage and success counters of the attack type
+type AttackTypeStats struct {
+	Used            int
+	Executed        int
+	Hits            int
+	Blocked         int
+	SpecialRolls    int
+	SpecialsApplied int
+	// SpecialsResisted counts the successful special rolls that didn't affect the defender due to the immunity or stacking
+	SpecialsResisted int
+	Damage           int
+}
+
+// Report holds the aggregated results of the simulation
+type Report struct {
+	Fights      int
+	Turns       int
+	Archetypes  map[string]*ArchetypeStats
+	AttackTypes map[attack.AttackType]*AttackTypeStats
+	// Damages holds the damage of every landed attack
+	Damages []int
+	// ConditionDamage holds the total damage dealt by every condition
+	ConditionDamage map[modifiers.Condition]int
+	// Stances counts how many times every stance was taken
+	Stances map[attack.Stance]int
+	// Counters holds the number and the total damage of the successful counters
+	Counters      int
+	CounterDamage int
+	// Methods counts how the fights ended and Decisions counts the fights that went to the judges by the decision type
+	Methods   map[game.Method]int
+	Decisions map[event.DecisionType]int
+}
+
+func newReport() *Report {
+	return &Report{
+		Archetypes:      make(map[string]*ArchetypeStats),
+		AttackTypes:     make(map[attack.AttackType]*AttackTypeStats),
+		ConditionDamage: make(map[modifiers.Condition]int),
+		Stances:         make(map[attack.Stance]int),
+		Methods:         make(map[game.Method]int),
+		Decisions:       make(map[event.DecisionType]int),
+	}
+}
+
+// Run simulates the fights using all workers and returns the aggregated report.
+// Every fight is seeded from the config seed and its number, so the report doesn't depend on the number of workers.
+func Run(config Config) (*Report, error) {
+	// All fights use the same catalog, even if it's reloaded during the simulation
+	if config.Catalog != nil {
+		config.Catalog = attack.NewStaticRegistry(config.Catalog.Attacks())
+	}
+	if err := config.Rules.Validate(); err != nil {
+		return nil, err
+	}
+	for _, name := range config.Strategies {
+		if _, err := game.StrategyByName(name, config.Catalog); err != nil {
+			return nil, err
+		}
+	}
+	if len(config.Fighters) == 1 {
+		return nil, fmt.Errorf("at least two fighters are required, got 1")
+	}
+	workers := config.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	fights := make(chan int)
+	reports := make([]*Report, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		reports[i] = newReport()
+		wg.Add(1)
+		go func(report *Report) {
+			defer wg.Done()
+			for fight := range fights {
+				simulateFight(config, config.Seed+int64(fight), report)
+			}
+		}(reports[i])
+	}
+	for fight := 0; fight < config.Fights; fight++ {
+		fights <- fight
+	}
+	close(fights)
+	wg.Wait()
+
+	report := newReport()
+	for _, workerReport := range reports {
+		report.merge(workerReport)
+	}
+	sort.Ints(report.Damages)
+	return report, nil
+}
+
+// simulateFight runs a single headless fight and adds its results to the report
+func simulateFight(config Config, seed int64, report *Report) {
+	rng := rand.New(rand.NewSource(seed))
+	var fighters [2]*fighter.Fighter
+	if len(config.Fighters) == 0 {
+		fighters[0] = fighter.GenerateFighter(rng)
+		fighters[1] = fighter.GenerateFighter(rng)
+	} else {
+		first := rng.Intn(len(config.Fighters))
+		second := rng.Intn(len(config.Fighters) - 1)
+		if second >= first {
+			second++
+		}
+		fighters[0] = fresh(config.Fighters[first])
+		fighters[1] = fresh(config.Fighters[second])
+	}
+
+	var strategies [2]game.Strategy
+	for i, name := range config.Strategies {
+		strategies[i], _ = game.StrategyByName(name, config.Catalog)
+	}
+
+	match := game.NewMatch(fighters[0], fighters[1], strategies[0], strategies[1], seed)
+	match.SetRules(config.Rules)
+	match.Subscribe(&collector{report: report})
+	result := match.Run()
+
+	report.Fights++
+	report.Methods[result.Method]++
+	report.Turns += match.Turn() - 1
+	for _, f := range fighters {
+		archetype, ok := report.Archetypes[f.Archetype()]
+		if !ok {
+			archetype = &ArchetypeStats{}
+			report.Archetypes[f.Archetype()] = archetype
+		}
+		archetype.Fights++
+		if f == result.Winner {
+			archetype.Wins++
+		}
+	}
+}
+
+// fresh returns a copy of the fighter ready for a new fight
+func fresh(f *fighter.Fighter) *fighter.Fighter {
+	clone := f.Clone()
+	clone.Reset()
+	return clone
+}
+
+// collector is a Subscriber that counts the attack events of a single fight
+type collector struct {
+	report     *Report
+	attackType *AttackTypeStats
+}
+
+func (c *collector) Notify(e event.Event) {
+	switch e := e.(type) {
+	case event.TurnStarted:
+		c.attackType = nil
+	case event.AttackAttempted:
+		stats, ok := c.report.AttackTypes[e.Type]
+		if !ok {
+			stats = &AttackTypeStats{}
+			c.report.AttackTypes[e.Type] = stats
+		}
+		stats.Used++
+		c.attackType = stats
+	case event.ComplexityRoll:
+		if e.Success {
+			c.attackType.Executed++
+		}
+	case event.HitRoll:
+		if e.Success {
+			c.attackType.Hits++
+		}
+	case event.BlockRoll:
+		if e.Blocked {
+			c.attackType.Blocked++
+		}
+	case event.SpecialRoll:
+		c.attackType.SpecialRolls++
+		if e.Success {
+			c.attackType.SpecialsApplied++
+		}
+	case event.ConditionResisted:
+		c.attackType.SpecialsResisted++
+	case event.StanceChosen:
+		// Only the stances chosen by the strategies are counted, not the forced blocks
+		if e.Condition == modifiers.Healthy {
+			c.report.Stances[e.Stance]++
+		}
+	case event.Decision:
+		c.report.Decisions[e.Type]++
+	case event.DamageDealt:
+		if e.Counter {
+			c.report.Counters++
+			c.report.CounterDamage += e.Amount
+		} else if e.Attack != "" {
+			c.attackType.Damage += e.Amount
+			c.report.Damages = append(c.report.Damages, e.Amount)
+		} else {
+			c.report.ConditionDamage[e.Condition] += e.Amount
+		}
+	}
+}
+
+// merge adds the other report results to the report
+func (r *Report) merge(other *Report) {
+	r.Fights += other.Fights
+	r.Turns += other.Turns
+	for name, stats := range other.Archetypes {
+		archetype, ok := r.Archetypes[name]
+		if !ok {
+			archetype = &ArchetypeStats{}
+			r.Archetypes[name] = archetype
+		}
+		archetype.Fights += stats.Fights
+		archetype.Wins += stats.Wins
+	}
+	for attackType, stats := range other.AttackTypes {
+		total, ok := r.AttackTypes[attackType]
+		if !ok {
+			total = &AttackTypeStats{}
+			r.AttackTypes[attackType] = total
+		}
+		total.Used += stats.Used
+		total.Executed += stats.Executed
+		total.Hits += stats.Hits
+		total.Blocked += stats.Blocked
+		total.SpecialRolls += stats.SpecialRolls
+		total.SpecialsApplied += stats.SpecialsApplied
+		total.SpecialsResisted += stats.SpecialsResisted
+		total.Damage += stats.Damage
+	}
+	r.Damages = append(r.Damages, other.Damages...)
+	for condition, damage := range other.ConditionDamage {
+		r.ConditionDamage[condition] += damage
+	}
+	for stance, count := range other.Stances {
+		r.Stances[stance] += count
+	}
+	for method, count := range other.Methods {
+		r.Methods[method] += count
+	}
+	for decisionType, count := range other.Decisions {
+		r.Decisions[decisionType] += count
+	}
+	r.Counters += other.Counters
+	r.CounterDamage += other.CounterDamage
+}
+
+// percent returns the part of the total in percents, or 0 if the total is 0
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(part) / float64(total)
+}
+
+// average returns the total divided by the count, or 0 if the count is 0
+func average(total, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return float64(total) / float64(count)
+}
+
+// Print writes the report as tables
+func (r *Report) Print(out io.Writer) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintf(out, "Fights: %d, average fight length: %.1f turns\n", r.Fights, average(r.Turns, r.Fights))
+	methods := []string{}
+	for _, method := range game.Methods {
+		if count := r.Methods[method]; count > 0 {
+			methods = append(methods, fmt.Sprintf("%s %.1f%%", method, percent(count, r.Fights)))
+		}
+	}
+	fmt.Fprintf(out, "Outcomes: %s\n", strings.Join(methods, ", "))
+	if len(r.Decisions) > 0 {
+		fmt.Fprintf(out, "Decisions: unanimous %d, majority %d, split %d, draw %d\n",
+			r.Decisions[event.Unanimous], r.Decisions[event.Majority], r.Decisions[event.Split], r.Decisions[event.Draw])
+	}
+	fmt.Fprintln(out)
+
+	archetypes := make([]string, 0, len(r.Archetypes))
+	for name := range r.Archetypes {
+		archetypes = append(archetypes, name)
+	}
+	sort.Strings(archetypes)
+	fmt.Fprintln(w, "Archetype\tFights\tWins\tWin rate\t")
+	for _, name := range archetypes {
+		stats := r.Archetypes[name]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f%%\t\n", name, stats.Fights, stats.Wins, percent(stats.Wins, stats.Fights))
+	}
+	w.Flush()
+	fmt.Fprintln(out)
+
+	totalUsed := 0
+	for _, stats := range r.AttackTypes {
+		totalUsed += stats.Used
+	}
+	fmt.Fprintln(w, "Attack type\tUsage\tExecuted\tHit\tBlocked\tSpecial\tResisted\tAvg damage\t")
+	for attackType := attack.AttackType(0); attackType < attack.AttackType(attack.MaxAttackTypes); attackType++ {
+		stats, ok := r.AttackTypes[attackType]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%.1f%%\t%.1f%%\t%.1f%%\t%.1f%%\t%.1f%%\t%.1f%%\t%.1f\t\n", attackType.String(),
+			percent(stats.Used, totalUsed), percent(stats.Executed, stats.Used), percent(stats.Hits, stats.Executed),
+			percent(stats.Blocked, stats.Hits), percent(stats.SpecialsApplied, stats.SpecialRolls), percent(stats.SpecialsResisted, stats.SpecialsApplied), average(stats.Damage, stats.Hits-stats.Blocked))
+	}
+	w.Flush()
+	fmt.Fprintln(out)
+
+	totalStances := 0
+	for _, count := range r.Stances {
+		totalStances += count
+	}
+	if totalStances > 0 {
+		fmt.Fprintln(w, "Stance\tUsage\t")
+		for _, stance := range attack.Stances {
+			fmt.Fprintf(w, "%s\t%.1f%%\t\n", stance.String(), percent(r.Stances[stance], totalStances))
+		}
+		w.Flush()
+		fmt.Fprintf(out, "Counters: %d, damage per fight %.1f\n\n", r.Counters, average(r.CounterDamage, r.Fights))
+	}
+
+	if len(r.Damages) == 0 {
+		return
+	}
+	totalDamage := 0
+	for _, damage := range r.Damages {
+		totalDamage += damage
+	}
+	fmt.Fprintf(out, "Landed attacks: %d, damage mean %.1f, median %d, p90 %d, max %d\n", len(r.Damages),
+		float64(totalDamage)/float64(len(r.Damages)), r.Damages[len(r.Damages)/2], r.Damages[len(r.Damages)*9/10], r.Damages[len(r.Damages)-1])
+	buckets := make(map[int]int)
+	for _, damage := range r.Damages {
+		buckets[damage/damageBucketSize]++
+	}
+	fmt.Fprintln(w, "Damage\tAttacks\tShare\t")
+	for bucket := 0; bucket <= r.Damages[len(r.Damages)-1]/damageBucketSize; bucket++ {
+		if buckets[bucket] == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%d-%d\t%d\t%.1f%%\t\n", bucket*damageBucketSize, (bucket+1)*damageBucketSize-1, buckets[bucket], percent(buckets[bucket], len(r.Damages)))
+	}
+	w.Flush()
+
+	if len(r.ConditionDamage) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(w, "Condition\tTotal damage\tPer fight\t")
+		for _, condition := range modifiers.SortedConditions(r.ConditionDamage) {
+			fmt.Fprintf(w, "%s\t%d\t%.1f\t\n", condition.String(), r.ConditionDamage[condition], average(r.ConditionDamage[condition], r.Fights))
+		}
+		w.Flush()
+	}
+}
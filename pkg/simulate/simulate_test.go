@@ -0,0 +1,73 @@
+package simulate
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zerobugdebug/cogfight/pkg/attack"
+	"github.com/zerobugdebug/cogfight/pkg/event"
+	"github.com/zerobugdebug/cogfight/pkg/game"
+)
+
+func TestRunSameReportForAnyWorkers(t *testing.T) {
+	for _, rules := range []game.Rules{{}, game.DefaultRules()} {
+		config := Config{Fights: 60, Seed: 7, Strategies: [2]string{"greedy", "random"}, Rules: rules}
+		config.Workers = 1
+		single, err := Run(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config.Workers = 4
+		parallel, err := Run(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if single.Fights != config.Fights {
+			t.Fatalf("simulated %d fights, want %d", single.Fights, config.Fights)
+		}
+		if !reflect.DeepEqual(single, parallel) {
+			t.Fatalf("1 and 4 workers produced different reports with %d rounds", rules.Rounds)
+		}
+		var singleText, parallelText bytes.Buffer
+		single.Print(&singleText)
+		parallel.Print(&parallelText)
+		if singleText.String() != parallelText.String() {
+			t.Errorf("1 and 4 workers printed different reports:\n%s\n%s", singleText.String(), parallelText.String())
+		}
+	}
+}
+
+func TestPrintReport(t *testing.T) {
+	drawn := newReport()
+	drawn.Fights, drawn.Turns = 2, 40
+	drawn.Methods[game.Draw], drawn.Methods[game.Decision] = 1, 1
+	drawn.Decisions[event.Draw], drawn.Decisions[event.Split] = 1, 1
+
+	empty := newReport()
+	empty.Stances[attack.Block] = 1
+
+	tests := []struct {
+		name   string
+		report *Report
+		want   []string
+	}{
+		{"draws", drawn, []string{"average fight length: 20.0 turns", "Decisions: unanimous 0, majority 0, split 1, draw 1"}},
+		{"no fights", empty, []string{"Fights: 0, average fight length: 0.0 turns", "damage per fight 0.0"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var text bytes.Buffer
+			test.report.Print(&text)
+			if strings.Contains(text.String(), "NaN") {
+				t.Errorf("report contains NaN:\n%s", text.String())
+			}
+			for _, want := range test.want {
+				if !strings.Contains(text.String(), want) {
+					t.Errorf("report doesn't contain %q:\n%s", want, text.String())
+				}
+			}
+		})
+	}
+}
@@ -187,6 +187,49 @@ func ColorModifiedValue(value, delta float64, format string, colorMore func(a ..
 	}
 }
 
+func getDescriptions(valueType string) []string {
+	switch valueType {
+	case "weight":
+		return []string{"Very lightweight", "Lightweight", "Middleweight", "Heavyweight", "Very heavyweight"}
+	case "age":
+		return []string{"Very young", "Young", "Average", "Old", "Very old"}
+	case "height":
+		return []string{"Very short", "Short", "Average", "Tall", "Very tall"}
+	case "complexity":
+		return []string{"Basic", "Moderate", "Advanced", "Expert", "Master"}
+	default:
+		return []string{"Very low", "Low", "Average", "High", "Very high"}
+	}
+}
+
+// PercentileWithType describes where the value falls between min and max using the descriptions for the valueType
+func PercentileWithType(value, min, max float64, valueType string) string {
+	return getPercentileDesc(value, min, max, getDescriptions(valueType))
+}
+
+// PercentileDefault describes where the value falls between min and max using the default descriptions
+func PercentileDefault(value, min, max float64) string {
+	return getPercentileDesc(value, min, max, getDescriptions(""))
+}
+
+func getPercentileDesc(value, min, max float64, descriptions []string) string {
+	if value <= min {
+		return descriptions[0]
+	}
+	if value >= max {
+		return descriptions[len(descriptions)-1]
+	}
+
+	rangePerGroup := (max - min) / float64(len(descriptions))
+	index := int((value - min) / rangePerGroup)
+
+	if index >= len(descriptions) {
+		index = len(descriptions) - 1
+	}
+
+	return descriptions[index]
+}
+
 func RotatingPipe(stopChan chan bool, wg *sync.WaitGroup) {
 	defer wg.Done()
 	pipeChars := []string{"|", "/", "-", "\\"}